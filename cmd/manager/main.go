@@ -17,16 +17,29 @@ limitations under the License.
 package main
 
 import (
+	"context"
 	"crypto/tls"
 	"flag"
+	"fmt"
+	"io"
+	"net/http"
 	"os"
+	"strings"
+	"time"
 
+	uberzap "go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/discovery"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	authorizationv1client "k8s.io/client-go/kubernetes/typed/authorization/v1"
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
 	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 
@@ -34,11 +47,25 @@ import (
 	"github.com/cropalato/k8s-acl-operator/pkg/controller/namespace"
 	"github.com/cropalato/k8s-acl-operator/pkg/controller/namespacerbacconfig"
 	"github.com/cropalato/k8s-acl-operator/pkg/health"
+	"github.com/cropalato/k8s-acl-operator/pkg/metrics"
+	"github.com/cropalato/k8s-acl-operator/pkg/rbac"
+	"github.com/cropalato/k8s-acl-operator/pkg/tracing"
+	rbacwebhook "github.com/cropalato/k8s-acl-operator/pkg/webhook"
 )
 
 var (
 	scheme   = runtime.NewScheme()
 	setupLog = ctrl.Log.WithName("setup")
+
+	// version is set at build time with -ldflags "-X main.version=..."
+	version = "dev"
+)
+
+const (
+	// logSamplingFirst is how many identical log entries are written per tick before sampling
+	logSamplingFirst = 100
+	// logSamplingThereafter keeps every Nth identical entry within a tick after the first ones
+	logSamplingThereafter = 100
 )
 
 func init() {
@@ -52,6 +79,18 @@ func main() {
 	var probeAddr string
 	var secureMetrics bool
 	var enableHTTP2 bool
+	var crdWaitTimeout time.Duration
+	var summaryInterval time.Duration
+	var summaryTarget string
+	var auditLog bool
+	var auditLogFile string
+	var logSampling bool
+	var enableExemplars bool
+	var otelEndpoint string
+	var enableWebhooks bool
+	var preflight bool
+	var controllerOpts controllerOptions
+	templateSettings := keyValueFlag{}
 
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
@@ -62,14 +101,94 @@ func main() {
 		"If set the metrics endpoint is served securely")
 	flag.BoolVar(&enableHTTP2, "enable-http2", false,
 		"If set, HTTP/2 will be enabled for the metrics and webhook servers")
+	flag.BoolVar(&controllerOpts.EnableNamespaceController, "enable-namespace-controller", true,
+		"Run the standalone Namespace controller, which cleans up RBAC from namespaces that stop matching a config. "+
+			"Applying RBAC is always done by the NamespaceRBACConfig controller.")
+	flag.DurationVar(&crdWaitTimeout, "crd-wait-timeout", 2*time.Minute,
+		"How long to wait for the NamespaceRBACConfig CRD to be established before failing startup.")
+	flag.DurationVar(&controllerOpts.CleanupRetryInterval, "cleanup-retry-interval", namespacerbacconfig.DefaultCleanupRetryInterval,
+		"Base interval before retrying a failed cleanup of a deleted NamespaceRBACConfig. "+
+			"Doubles on each consecutive failure.")
+	flag.IntVar(&controllerOpts.CircuitThreshold, "circuit-breaker-threshold", namespacerbacconfig.DefaultCircuitBreakerThreshold,
+		"Consecutive reconcile failures after which a NamespaceRBACConfig is paused. 0 disables the circuit breaker.")
+	flag.DurationVar(&controllerOpts.CircuitInterval, "circuit-breaker-interval", namespacerbacconfig.DefaultCircuitBreakerInterval,
+		"How long reconciliation of a NamespaceRBACConfig is paused once its circuit breaker opens.")
+	flag.IntVar(&controllerOpts.MaxAppliedNamespaces, "max-applied-namespaces", namespacerbacconfig.DefaultMaxAppliedNamespaces,
+		"Maximum namespaces listed in a config's status.appliedNamespaces, followed by an \"… and N more\" entry; "+
+			"status.managedResourceCount keeps the full count. 0 means unbounded.")
+	flag.DurationVar(&controllerOpts.DegradedGracePeriod, "degraded-grace-period", 0,
+		"How long RBAC reconcile failures of a NamespaceRBACConfig must persist before it is marked Degraded and the "+
+			"operator unhealthy; until then it reports Progressing and is retried. 0 marks it Degraded on the first failure.")
+	flag.BoolVar(&controllerOpts.RBAC.ReadOnly, "read-only", false,
+		"Evaluate configs and update status and metrics, but log RBAC writes instead of performing them.")
+	flag.DurationVar(&summaryInterval, "summary-event-interval", 0,
+		"Interval between operator summary Events on the Deployment named by --summary-event-target. 0 disables summaries.")
+	flag.StringVar(&summaryTarget, "summary-event-target", "",
+		"The operator Deployment, as namespace/name, that summary Events are recorded on.")
+	flag.StringVar(&controllerOpts.RBAC.OperatorNamespace, "operator-namespace", os.Getenv("POD_NAMESPACE"),
+		"Namespace the operator runs in, excluded from every config unless it sets allowOperatorNamespace. "+
+			"Defaults to the POD_NAMESPACE environment variable.")
+	flag.StringVar(&controllerOpts.RBAC.OperatorServiceAccount, "operator-service-account", os.Getenv("POD_SERVICE_ACCOUNT"),
+		"Name of the operator's ServiceAccount in --operator-namespace. Cleanup never deletes ClusterRoleBindings granting it. "+
+			"Defaults to the POD_SERVICE_ACCOUNT environment variable.")
+	flag.StringVar(&controllerOpts.RBAC.FieldManager, "field-manager", rbac.DefaultFieldManager,
+		"Field manager name recorded on RBAC writes. Use distinct names when several operators manage the same resources.")
+	flag.BoolVar(&auditLog, "audit-log", false,
+		"Write a JSON line to stdout for every RBAC create, update, patch and delete performed by the operator.")
+	flag.StringVar(&auditLogFile, "audit-log-file", "",
+		"With --audit-log, append audit lines to this file instead of stdout. The file is created if missing.")
+	flag.Var(templateSettings, "template-setting",
+		"Operator-level template value in key=value form, exposed to templates as {{ .Settings.key }}. May be repeated.")
+
+	flag.BoolVar(&enableExemplars, "enable-exemplars", false,
+		"Attach the trace ID of the reconcile context as an exemplar to reconcile duration observations. "+
+			"Exemplars are served in the OpenMetrics format on "+metrics.OpenMetricsPath+".")
+
+	flag.BoolVar(&enableWebhooks, "enable-webhooks", false,
+		"Serve the mutating webhook that defaults new NamespaceRBACConfigs on "+rbacwebhook.MutatePath+". "+
+			"Requires a TLS certificate and a MutatingWebhookConfiguration.")
+
+	flag.BoolVar(&preflight, "preflight", false,
+		"At startup, check through SelfSubjectAccessReviews that the operator holds the permissions it needs. "+
+			"Results are logged and exported as metrics; the manager exits if a critical permission is missing.")
+
+	flag.StringVar(&otelEndpoint, "otel-endpoint", "",
+		"OTLP/HTTP collector receiving reconcile traces, as host:port or an http(s) URL. Tracing is disabled when empty.")
+
+	flag.BoolVar(&logSampling, "zap-log-sampling", false,
+		fmt.Sprintf("Sample repeated log entries: per second, log the first %d identical entries, then every %dth.",
+			logSamplingFirst, logSamplingThereafter))
 
 	opts := zap.Options{
 		Development: true,
 	}
 	opts.BindFlags(flag.CommandLine)
 	flag.Parse()
+	opts.ZapOpts = append(opts.ZapOpts, logSamplingOptions(logSampling)...)
+	controllerOpts.RBAC.TemplateSettings = templateSettings
 
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
+	metrics.EnableExemplars(enableExemplars)
+
+	if auditLog {
+		var auditWriter io.Writer = os.Stdout
+		if auditLogFile != "" {
+			file, err := os.OpenFile(auditLogFile, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+			if err != nil {
+				setupLog.Error(err, "unable to open audit log file", "path", auditLogFile)
+				os.Exit(1)
+			}
+			defer file.Close()
+			auditWriter = file
+		}
+		// Shared by both controllers so concurrent writes don't interleave lines
+		controllerOpts.RBAC.AuditSink = rbac.NewJSONAuditSink(auditWriter)
+	}
+
+	if strings.TrimSpace(controllerOpts.RBAC.FieldManager) == "" {
+		setupLog.Error(fmt.Errorf("--field-manager must not be empty"), "invalid flags")
+		os.Exit(1)
+	}
 
 	// Create health checker
 	healthChecker := health.NewChecker(setupLog)
@@ -89,12 +208,21 @@ func main() {
 		TLSOpts: tlsOpts,
 	})
 
+	// The default /metrics handler never serves OpenMetrics, which exemplars require
+	var metricsHandlers map[string]http.Handler
+	if enableExemplars {
+		metricsHandlers = map[string]http.Handler{
+			metrics.OpenMetricsPath: metrics.OpenMetricsHandler(ctrlmetrics.Registry),
+		}
+	}
+
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
 		Scheme: scheme,
 		Metrics: metricsserver.Options{
 			BindAddress:   metricsAddr,
 			SecureServing: secureMetrics,
 			TLSOpts:       tlsOpts,
+			ExtraHandlers: metricsHandlers,
 		},
 		WebhookServer:          webhookServer,
 		HealthProbeBindAddress: probeAddr,
@@ -106,30 +234,33 @@ func main() {
 		os.Exit(1)
 	}
 
-	// Setup NamespaceRBACConfig controller
-	namespaceRBACConfigReconciler := namespacerbacconfig.NewNamespaceRBACConfigReconciler(
-		mgr.GetClient(),
-		mgr.GetScheme(),
-		ctrl.Log.WithName("controllers").WithName("NamespaceRBACConfig"),
-		healthChecker,
-	)
-	if err = namespaceRBACConfigReconciler.SetupWithManager(mgr); err != nil {
-		setupLog.Error(err, "unable to create controller", "controller", "NamespaceRBACConfig")
+	if controllerOpts.RBAC.ReadOnly {
+		setupLog.Info("read-only mode enabled, RBAC writes will be logged but not performed")
+	}
+
+	// Without RBAC v1 every apply fails; report it once, clearly, and stay unready
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(mgr.GetConfig())
+	if err != nil {
+		setupLog.Error(err, "unable to create discovery client")
 		os.Exit(1)
 	}
+	if err := health.CheckRBACAPI(discoveryClient); err != nil {
+		setupLog.Error(err, "RBAC API check failed; RBAC will not be applied until rbac.authorization.k8s.io/v1 is available")
+		healthChecker.SetAPIUnavailable(err.Error())
+	}
 
-	// Setup Namespace controller
-	namespaceReconciler := namespace.NewNamespaceReconciler(
-		mgr.GetClient(),
-		mgr.GetScheme(),
-		ctrl.Log.WithName("controllers").WithName("Namespace"),
-		healthChecker,
-	)
-	if err = namespaceReconciler.SetupWithManager(mgr); err != nil {
-		setupLog.Error(err, "unable to create controller", "controller", "Namespace")
+	if err = setupControllers(mgr, healthChecker, controllerOpts); err != nil {
+		setupLog.Error(err, "unable to create controllers")
 		os.Exit(1)
 	}
 
+	if enableWebhooks {
+		if err = (&rbacwebhook.ConfigDefaulter{Version: version}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "NamespaceRBACConfig")
+			os.Exit(1)
+		}
+	}
+
 	if err := mgr.AddHealthzCheck("healthz", healthChecker.LivenessCheck); err != nil {
 		setupLog.Error(err, "unable to set up health check")
 		os.Exit(1)
@@ -139,12 +270,187 @@ func main() {
 		os.Exit(1)
 	}
 
-	// Mark operator as ready after successful setup
-	healthChecker.SetReady(true)
+	// Mark operator as ready once the CRD is established and the caches are synced
+	crdWaiter := &health.CRDWaiter{
+		Reader:   mgr.GetAPIReader(),
+		Cache:    mgr.GetCache(),
+		Checker:  healthChecker,
+		CRDName:  rbacv1.Resource("namespacerbacconfigs").String(),
+		Interval: 2 * time.Second,
+		Timeout:  crdWaitTimeout,
+	}
+	if err := mgr.Add(crdWaiter); err != nil {
+		setupLog.Error(err, "unable to set up CRD readiness gate")
+		os.Exit(1)
+	}
+
+	// Fail fast when the operator lacks the RBAC permissions it needs
+	if preflight {
+		authorizationClient, err := authorizationv1client.NewForConfig(mgr.GetConfig())
+		if err != nil {
+			setupLog.Error(err, "unable to create authorization client")
+			os.Exit(1)
+		}
+		if err := mgr.Add(&health.Preflight{
+			Reviews: authorizationClient.SelfSubjectAccessReviews(),
+			Checker: healthChecker,
+		}); err != nil {
+			setupLog.Error(err, "unable to set up preflight")
+			os.Exit(1)
+		}
+	}
+
+	// Periodically record a summary Event on the operator Deployment
+	if summaryInterval > 0 {
+		namespace, name, found := strings.Cut(summaryTarget, "/")
+		if !found || namespace == "" || name == "" {
+			setupLog.Error(fmt.Errorf("invalid --summary-event-target %q", summaryTarget), "expected namespace/name")
+			os.Exit(1)
+		}
+		summaryReporter := &health.SummaryReporter{
+			Reader:   mgr.GetClient(),
+			Checker:  healthChecker,
+			Recorder: mgr.GetEventRecorderFor("rbac-operator"),
+			Object:   &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name}},
+			Interval: summaryInterval,
+		}
+		if err := mgr.Add(summaryReporter); err != nil {
+			setupLog.Error(err, "unable to set up summary events")
+			os.Exit(1)
+		}
+	}
+
+	// Expose leadership as a metric; without leader election every instance leads
+	if err := mgr.Add(&metrics.LeaderTracker{}); err != nil {
+		setupLog.Error(err, "unable to set up leader election metric")
+		os.Exit(1)
+	}
+
+	// Mirror controller-runtime's workqueue depth under the operator's metric prefix
+	if err := mgr.Add(&metrics.QueueDepthReporter{}); err != nil {
+		setupLog.Error(err, "unable to set up workqueue depth metric")
+		os.Exit(1)
+	}
 
-	setupLog.Info("starting manager")
-	if err := mgr.Start(ctrl.SetupSignalHandler()); err != nil {
+	// Dump a metrics snapshot to stderr on SIGUSR1, keeping stdout for the audit log
+	if err := mgr.Add(&metrics.SnapshotDumper{Writer: os.Stderr}); err != nil {
+		setupLog.Error(err, "unable to set up metrics snapshot handler")
+		os.Exit(1)
+	}
+
+	ctx := ctrl.SetupSignalHandler()
+
+	// Export reconcile traces; without an endpoint spans go to the no-op provider
+	shutdownTracing, err := tracing.Setup(ctx, otelEndpoint)
+	if err != nil {
+		setupLog.Error(err, "unable to set up tracing")
+		os.Exit(1)
+	}
+
+	setupLog.Info("starting manager", "version", version)
+	if err := mgr.Start(ctx); err != nil {
 		setupLog.Error(err, "problem running manager")
 		os.Exit(1)
 	}
+
+	// Flush buffered spans; ctx is already cancelled
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		setupLog.Error(err, "failed to flush traces")
+	}
+}
+
+// logSamplingOptions returns the zap options that sample repeated log entries, or
+// none when sampling is disabled
+func logSamplingOptions(enabled bool) []uberzap.Option {
+	if !enabled {
+		return nil
+	}
+	return []uberzap.Option{
+		uberzap.WrapCore(func(core zapcore.Core) zapcore.Core {
+			return zapcore.NewSamplerWithOptions(core, time.Second, logSamplingFirst, logSamplingThereafter)
+		}),
+	}
+}
+
+// controllerOptions holds flag-driven settings for the operator's controllers
+type controllerOptions struct {
+	EnableNamespaceController bool          // Run the standalone Namespace controller
+	CleanupRetryInterval      time.Duration // Base requeue interval after a failed config cleanup
+	CircuitThreshold          int           // Consecutive reconcile failures before a config is paused
+	CircuitInterval           time.Duration // How long a paused config waits before retrying
+	MaxAppliedNamespaces      int           // Cap on namespaces listed in a config's status
+	DegradedGracePeriod       time.Duration // How long reconcile failures persist before a config is Degraded
+	RBAC                      rbac.Options  // Options shared by both controllers' RBAC managers
+}
+
+// keyValueFlag collects repeated key=value flags into a map
+type keyValueFlag map[string]string
+
+// String implements flag.Value
+func (f keyValueFlag) String() string {
+	pairs := make([]string, 0, len(f))
+	for k, v := range f {
+		pairs = append(pairs, k+"="+v)
+	}
+	return strings.Join(pairs, ",")
+}
+
+// Set implements flag.Value
+func (f keyValueFlag) Set(value string) error {
+	key, val, ok := strings.Cut(value, "=")
+	if !ok || key == "" {
+		return fmt.Errorf("expected key=value, got %q", value)
+	}
+	f[key] = val
+	return nil
+}
+
+// setupControllers registers the operator's controllers with the manager.
+// The standalone Namespace controller is only set up when opts.EnableNamespaceController is true.
+func setupControllers(mgr ctrl.Manager, healthChecker *health.Checker, opts controllerOptions) error {
+	// Index generated resources by config so cleanup lists are served from the cache index
+	if err := rbac.IndexConfigLabel(context.Background(), mgr.GetFieldIndexer()); err != nil {
+		return err
+	}
+	opts.RBAC.ConfigLabelIndexed = true
+
+	// Setup NamespaceRBACConfig controller
+	namespaceRBACConfigReconciler := namespacerbacconfig.NewNamespaceRBACConfigReconciler(
+		mgr.GetClient(),
+		mgr.GetScheme(),
+		ctrl.Log.WithName("controllers").WithName("NamespaceRBACConfig"),
+		healthChecker,
+		opts.RBAC,
+	)
+	namespaceRBACConfigReconciler.CleanupRetryInterval = opts.CleanupRetryInterval
+	namespaceRBACConfigReconciler.CircuitThreshold = opts.CircuitThreshold
+	namespaceRBACConfigReconciler.CircuitInterval = opts.CircuitInterval
+	namespaceRBACConfigReconciler.MaxAppliedNamespaces = opts.MaxAppliedNamespaces
+	namespaceRBACConfigReconciler.DegradedGracePeriod = opts.DegradedGracePeriod
+	namespaceRBACConfigReconciler.APIReader = mgr.GetAPIReader()
+	namespaceRBACConfigReconciler.NamespaceCache = mgr.GetCache()
+	if err := namespaceRBACConfigReconciler.SetupWithManager(mgr); err != nil {
+		return fmt.Errorf("controller NamespaceRBACConfig: %w", err)
+	}
+
+	if !opts.EnableNamespaceController {
+		setupLog.Info("Namespace controller disabled")
+		return nil
+	}
+
+	// Setup Namespace controller
+	namespaceReconciler := namespace.NewNamespaceReconciler(
+		mgr.GetClient(),
+		mgr.GetScheme(),
+		ctrl.Log.WithName("controllers").WithName("Namespace"),
+		healthChecker,
+		opts.RBAC,
+	)
+	if err := namespaceReconciler.SetupWithManager(mgr); err != nil {
+		return fmt.Errorf("controller Namespace: %w", err)
+	}
+
+	return nil
 }
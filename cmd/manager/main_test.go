@@ -0,0 +1,162 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"reflect"
+	"slices"
+	"testing"
+
+	uberzap "go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"k8s.io/client-go/rest"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
+
+	"github.com/cropalato/k8s-acl-operator/pkg/health"
+)
+
+// recordingManager records the controllers added to it instead of running them
+type recordingManager struct {
+	ctrl.Manager
+	controllers []string
+}
+
+// Add records the name of each controller; other runnables are ignored
+func (m *recordingManager) Add(r manager.Runnable) error {
+	v := reflect.Indirect(reflect.ValueOf(r))
+	if v.Kind() == reflect.Struct {
+		if name := v.FieldByName("Name"); name.IsValid() && name.Kind() == reflect.String {
+			m.controllers = append(m.controllers, name.String())
+		}
+	}
+	return nil
+}
+
+// GetFieldIndexer returns an indexer that needs no API server
+func (m *recordingManager) GetFieldIndexer() client.FieldIndexer {
+	return noopIndexer{}
+}
+
+type noopIndexer struct{}
+
+func (noopIndexer) IndexField(context.Context, client.Object, string, client.IndexerFunc) error {
+	return nil
+}
+
+func TestSetupControllersNamespaceToggle(t *testing.T) {
+	tests := []struct {
+		name   string
+		enable bool
+		want   []string
+	}{
+		{name: "enabled", enable: true, want: []string{"namespacerbacconfig", "namespace"}},
+		{name: "disabled", enable: false, want: []string{"namespacerbacconfig"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mgr, err := ctrl.NewManager(&rest.Config{Host: "http://127.0.0.1:1"}, ctrl.Options{
+				Scheme:  scheme,
+				Metrics: metricsserver.Options{BindAddress: "0"},
+			})
+			if err != nil {
+				t.Fatalf("NewManager: %v", err)
+			}
+			rec := &recordingManager{Manager: mgr}
+
+			opts := controllerOptions{EnableNamespaceController: tt.enable}
+			if err := setupControllers(rec, health.NewChecker(ctrl.Log), opts); err != nil {
+				t.Fatalf("setupControllers: %v", err)
+			}
+			if !slices.Equal(rec.controllers, tt.want) {
+				t.Errorf("controllers = %v, want %v", rec.controllers, tt.want)
+			}
+		})
+	}
+}
+
+func TestKeyValueFlag(t *testing.T) {
+	tests := []struct {
+		name    string
+		values  []string
+		want    map[string]string
+		wantErr bool
+	}{
+		{name: "single", values: []string{"domain=example.com"}, want: map[string]string{"domain": "example.com"}},
+		{
+			name:   "repeated",
+			values: []string{"domain=example.com", "cluster=prod-1"},
+			want:   map[string]string{"domain": "example.com", "cluster": "prod-1"},
+		},
+		{name: "value with equals sign", values: []string{"query=a=b"}, want: map[string]string{"query": "a=b"}},
+		{name: "empty value", values: []string{"domain="}, want: map[string]string{"domain": ""}},
+		{name: "later value wins", values: []string{"domain=a", "domain=b"}, want: map[string]string{"domain": "b"}},
+		{name: "missing equals sign", values: []string{"domain"}, wantErr: true},
+		{name: "empty key", values: []string{"=example.com"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := keyValueFlag{}
+			var err error
+			for _, v := range tt.values {
+				if err = f.Set(v); err != nil {
+					break
+				}
+			}
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Set() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(map[string]string(f), tt.want) {
+				t.Errorf("flag = %v, want %v", f, tt.want)
+			}
+		})
+	}
+}
+
+func TestLogSamplingOptions(t *testing.T) {
+	const repeats = 3 * logSamplingFirst
+
+	tests := []struct {
+		name    string
+		enabled bool
+		want    int
+	}{
+		{name: "disabled", enabled: false, want: repeats},
+		// The first entries pass, then every logSamplingThereafter-th within the tick
+		{name: "enabled", enabled: true, want: logSamplingFirst + (repeats-logSamplingFirst)/logSamplingThereafter},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core, logs := observer.New(zapcore.DebugLevel)
+			logger := uberzap.New(core, logSamplingOptions(tt.enabled)...)
+			for i := 0; i < repeats; i++ {
+				logger.Info("Processing namespace create/update event")
+			}
+			// Crossing a tick boundary resets the sampler, which can only let more through
+			if got := logs.Len(); got < tt.want || (tt.enabled && got == repeats) {
+				t.Errorf("logged %d entries, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,336 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// Deep copies for the API types. Objects read through the client are copies of what
+// the informer cache holds, so every slice, map and pointer must be copied: a shallow
+// copy would let a reconcile mutate cached objects.
+
+// DeepCopyInto copies the receiver into out
+func (in *NamespaceSelector) DeepCopyInto(out *NamespaceSelector) {
+	*out = *in
+	out.NameRegex = copyPtr(in.NameRegex)
+	out.Annotations = copyMap(in.Annotations)
+	out.AnnotationExists = copySlice(in.AnnotationExists)
+	out.AnnotationNotExists = copySlice(in.AnnotationNotExists)
+	out.Labels = copyMap(in.Labels)
+	out.IncludeNamespaces = copySlice(in.IncludeNamespaces)
+	out.IncludeNamespaceGlobs = copySlice(in.IncludeNamespaceGlobs)
+	out.ExcludeNamespaces = copySlice(in.ExcludeNamespaces)
+	out.ExcludeNameRegex = copySlice(in.ExcludeNameRegex)
+	out.HasResourceQuota = copyPtr(in.HasResourceQuota)
+}
+
+// DeepCopyInto copies the receiver into out
+func (in *RoleTemplate) DeepCopyInto(out *RoleTemplate) {
+	*out = *in
+	out.Rules = copyPolicyRules(in.Rules)
+	out.SimpleRules = copySimpleRules(in.SimpleRules)
+	out.Labels = copyMap(in.Labels)
+	out.Annotations = copyMap(in.Annotations)
+}
+
+// DeepCopyInto copies the receiver into out
+func (in *ClusterRoleTemplate) DeepCopyInto(out *ClusterRoleTemplate) {
+	*out = *in
+	out.Rules = copyPolicyRules(in.Rules)
+	out.SimpleRules = copySimpleRules(in.SimpleRules)
+	out.Labels = copyMap(in.Labels)
+	out.Annotations = copyMap(in.Annotations)
+	out.PerNamespace = copyPtr(in.PerNamespace)
+}
+
+// DeepCopyInto copies the receiver into out
+func (in *SimpleRule) DeepCopyInto(out *SimpleRule) {
+	*out = *in
+	out.APIGroups = copySlice(in.APIGroups)
+	out.Resources = copySlice(in.Resources)
+}
+
+// DeepCopyInto copies the receiver into out
+func (in *RoleBindingTemplate) DeepCopyInto(out *RoleBindingTemplate) {
+	*out = *in
+	out.Subjects = copySlice(in.Subjects)
+	out.Labels = copyMap(in.Labels)
+	out.Annotations = copyMap(in.Annotations)
+	out.FromServiceAccountSelector = copyMap(in.FromServiceAccountSelector)
+}
+
+// DeepCopyInto copies the receiver into out
+func (in *ClusterRoleBindingTemplate) DeepCopyInto(out *ClusterRoleBindingTemplate) {
+	*out = *in
+	out.Subjects = copySlice(in.Subjects)
+	out.Labels = copyMap(in.Labels)
+	out.Annotations = copyMap(in.Annotations)
+}
+
+// DeepCopyInto copies the receiver into out
+func (in *ServiceAccountTemplate) DeepCopyInto(out *ServiceAccountTemplate) {
+	*out = *in
+	out.Labels = copyMap(in.Labels)
+	out.Annotations = copyMap(in.Annotations)
+}
+
+// DeepCopyInto copies the receiver into out
+func (in *RBACTemplates) DeepCopyInto(out *RBACTemplates) {
+	*out = *in
+	if in.ServiceAccounts != nil {
+		out.ServiceAccounts = make([]ServiceAccountTemplate, len(in.ServiceAccounts))
+		for i := range in.ServiceAccounts {
+			in.ServiceAccounts[i].DeepCopyInto(&out.ServiceAccounts[i])
+		}
+	}
+	if in.Roles != nil {
+		out.Roles = make([]RoleTemplate, len(in.Roles))
+		for i := range in.Roles {
+			in.Roles[i].DeepCopyInto(&out.Roles[i])
+		}
+	}
+	if in.ClusterRoles != nil {
+		out.ClusterRoles = make([]ClusterRoleTemplate, len(in.ClusterRoles))
+		for i := range in.ClusterRoles {
+			in.ClusterRoles[i].DeepCopyInto(&out.ClusterRoles[i])
+		}
+	}
+	if in.RoleBindings != nil {
+		out.RoleBindings = make([]RoleBindingTemplate, len(in.RoleBindings))
+		for i := range in.RoleBindings {
+			in.RoleBindings[i].DeepCopyInto(&out.RoleBindings[i])
+		}
+	}
+	if in.ClusterRoleBindings != nil {
+		out.ClusterRoleBindings = make([]ClusterRoleBindingTemplate, len(in.ClusterRoleBindings))
+		for i := range in.ClusterRoleBindings {
+			in.ClusterRoleBindings[i].DeepCopyInto(&out.ClusterRoleBindings[i])
+		}
+	}
+}
+
+// DeepCopyInto copies the receiver into out
+func (in *CleanupConfig) DeepCopyInto(out *CleanupConfig) {
+	*out = *in
+	out.DeleteOrphanedClusterResources = copyPtr(in.DeleteOrphanedClusterResources)
+	out.GracePeriodSeconds = copyPtr(in.GracePeriodSeconds)
+	out.DeleteDanglingBindings = copyPtr(in.DeleteDanglingBindings)
+	out.UseFinalizer = copyPtr(in.UseFinalizer)
+}
+
+// DeepCopyInto copies the receiver into out
+func (in *LimitsConfig) DeepCopyInto(out *LimitsConfig) {
+	*out = *in
+	out.MaxRulesPerRole = copyPtr(in.MaxRulesPerRole)
+	out.MaxSubjectsPerBinding = copyPtr(in.MaxSubjectsPerBinding)
+}
+
+// DeepCopyInto copies the receiver into out
+func (in *NamespaceRBACConfigConfig) DeepCopyInto(out *NamespaceRBACConfigConfig) {
+	*out = *in
+	out.Naming = copyPtr(in.Naming)
+	out.MergeStrategy = copyPtr(in.MergeStrategy)
+	out.TemplateVariables = copyMap(in.TemplateVariables)
+	if in.Cleanup != nil {
+		out.Cleanup = new(CleanupConfig)
+		in.Cleanup.DeepCopyInto(out.Cleanup)
+	}
+	out.NamespaceLabels = copyMap(in.NamespaceLabels)
+	out.NamespaceAnnotations = copyMap(in.NamespaceAnnotations)
+	out.ExportTo = copyPtr(in.ExportTo)
+	out.ResyncInterval = copyPtr(in.ResyncInterval)
+	out.CommonLabels = copyMap(in.CommonLabels)
+	out.CommonAnnotations = copyMap(in.CommonAnnotations)
+	out.Hooks = copyPtr(in.Hooks)
+	out.ApplyOrder = copySlice(in.ApplyOrder)
+	out.MaxConflictRetries = copyPtr(in.MaxConflictRetries)
+	out.OwnerReferenceStrategy = copyPtr(in.OwnerReferenceStrategy)
+	out.AllowedTemplateFunctions = copySlice(in.AllowedTemplateFunctions)
+	out.RequireMatch = copyPtr(in.RequireMatch)
+	out.AllowOperatorNamespace = copyPtr(in.AllowOperatorNamespace)
+	if in.Limits != nil {
+		out.Limits = new(LimitsConfig)
+		in.Limits.DeepCopyInto(out.Limits)
+	}
+	out.SubjectMatchIgnoreCase = copyPtr(in.SubjectMatchIgnoreCase)
+}
+
+// DeepCopyInto copies the receiver into out
+func (in *NamespaceRBACConfigSpec) DeepCopyInto(out *NamespaceRBACConfigSpec) {
+	*out = *in
+	in.NamespaceSelector.DeepCopyInto(&out.NamespaceSelector)
+	in.RBACTemplates.DeepCopyInto(&out.RBACTemplates)
+	if in.Config != nil {
+		out.Config = new(NamespaceRBACConfigConfig)
+		in.Config.DeepCopyInto(out.Config)
+	}
+	out.Suspend = copyPtr(in.Suspend)
+}
+
+// DeepCopyInto copies the receiver into out
+func (in *CreatedResources) DeepCopyInto(out *CreatedResources) {
+	*out = *in
+	out.Roles = copySlice(in.Roles)
+	out.ClusterRoles = copySlice(in.ClusterRoles)
+	out.RoleBindings = copySlice(in.RoleBindings)
+	out.ClusterRoleBindings = copySlice(in.ClusterRoleBindings)
+}
+
+// DeepCopyInto copies the receiver into out
+func (in *NamespaceRBACConfigStatus) DeepCopyInto(out *NamespaceRBACConfigStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		out.Conditions = make([]metav1.Condition, len(in.Conditions))
+		for i := range in.Conditions {
+			in.Conditions[i].DeepCopyInto(&out.Conditions[i])
+		}
+	}
+	out.AppliedNamespaces = copySlice(in.AppliedNamespaces)
+	if in.CreatedResources != nil {
+		out.CreatedResources = new(CreatedResources)
+		in.CreatedResources.DeepCopyInto(out.CreatedResources)
+	}
+	if in.RecentErrors != nil {
+		out.RecentErrors = make([]ErrorRecord, len(in.RecentErrors))
+		for i := range in.RecentErrors {
+			in.RecentErrors[i].DeepCopyInto(&out.RecentErrors[i])
+		}
+	}
+}
+
+// DeepCopyInto copies the receiver into out
+func (in *ErrorRecord) DeepCopyInto(out *ErrorRecord) {
+	*out = *in
+	in.Timestamp.DeepCopyInto(&out.Timestamp)
+}
+
+// DeepCopyInto copies the receiver into out
+func (in *NamespaceRBACConfig) DeepCopyInto(out *NamespaceRBACConfig) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy returns a deep copy of the NamespaceRBACConfig
+func (in *NamespaceRBACConfig) DeepCopy() *NamespaceRBACConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(NamespaceRBACConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject implements runtime.Object
+func (in *NamespaceRBACConfig) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto copies the receiver into out
+func (in *NamespaceRBACConfigList) DeepCopyInto(out *NamespaceRBACConfigList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		out.Items = make([]NamespaceRBACConfig, len(in.Items))
+		for i := range in.Items {
+			in.Items[i].DeepCopyInto(&out.Items[i])
+		}
+	}
+}
+
+// DeepCopy returns a deep copy of the NamespaceRBACConfigList
+func (in *NamespaceRBACConfigList) DeepCopy() *NamespaceRBACConfigList {
+	if in == nil {
+		return nil
+	}
+	out := new(NamespaceRBACConfigList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject implements runtime.Object
+func (in *NamespaceRBACConfigList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// copyPolicyRules deep-copies rules, whose fields are all slices
+func copyPolicyRules(in []rbacv1.PolicyRule) []rbacv1.PolicyRule {
+	if in == nil {
+		return nil
+	}
+	out := make([]rbacv1.PolicyRule, len(in))
+	for i := range in {
+		in[i].DeepCopyInto(&out[i])
+	}
+	return out
+}
+
+// copySimpleRules deep-copies SimpleRules
+func copySimpleRules(in []SimpleRule) []SimpleRule {
+	if in == nil {
+		return nil
+	}
+	out := make([]SimpleRule, len(in))
+	for i := range in {
+		in[i].DeepCopyInto(&out[i])
+	}
+	return out
+}
+
+// copySlice copies a slice of values without pointers, keeping nil as nil
+func copySlice[T any](in []T) []T {
+	if in == nil {
+		return nil
+	}
+	out := make([]T, len(in))
+	copy(out, in)
+	return out
+}
+
+// copyMap copies a map, keeping nil as nil
+func copyMap(in map[string]string) map[string]string {
+	if in == nil {
+		return nil
+	}
+	out := make(map[string]string, len(in))
+	for k, v := range in {
+		out[k] = v
+	}
+	return out
+}
+
+// copyPtr copies the value behind a pointer to a struct or value without pointers
+func copyPtr[T any](in *T) *T {
+	if in == nil {
+		return nil
+	}
+	out := new(T)
+	*out = *in
+	return out
+}
@@ -0,0 +1,108 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"reflect"
+	"testing"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func boolPtr(b bool) *bool { return &b }
+
+func newDeepCopyFixture() *NamespaceRBACConfig {
+	return &NamespaceRBACConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "cfg", Labels: map[string]string{"a": "b"}},
+		Spec: NamespaceRBACConfigSpec{
+			NamespaceSelector: NamespaceSelector{
+				Labels:            map[string]string{"team": "a"},
+				IncludeNamespaces: []string{"ns-a"},
+			},
+			RBACTemplates: RBACTemplates{
+				Roles: []RoleTemplate{{
+					Name:   "reader",
+					Rules:  []rbacv1.PolicyRule{{Verbs: []string{"get"}, Resources: []string{"pods"}}},
+					Labels: map[string]string{"role": "reader"},
+				}},
+				RoleBindings: []RoleBindingTemplate{{
+					Name:     "reader",
+					Subjects: []rbacv1.Subject{{Kind: "User", Name: "alice"}},
+				}},
+			},
+			Config: &NamespaceRBACConfigConfig{
+				TemplateVariables: map[string]string{"env": "prod"},
+				Cleanup:           &CleanupConfig{UseFinalizer: boolPtr(true)},
+				RequireMatch:      boolPtr(true),
+			},
+		},
+		Status: NamespaceRBACConfigStatus{
+			Conditions:        []metav1.Condition{{Type: "Ready", Status: metav1.ConditionTrue}},
+			AppliedNamespaces: []string{"ns-a"},
+			CreatedResources:  &CreatedResources{Roles: []ResourceReference{{Name: "reader", Namespace: "ns-a"}}},
+		},
+	}
+}
+
+func TestDeepCopyObjectIsIndependent(t *testing.T) {
+	tests := []struct {
+		name   string
+		mutate func(*NamespaceRBACConfig)
+	}{
+		{"object labels", func(c *NamespaceRBACConfig) { c.Labels["a"] = "changed" }},
+		{"selector labels", func(c *NamespaceRBACConfig) { c.Spec.NamespaceSelector.Labels["team"] = "b" }},
+		{"selector list", func(c *NamespaceRBACConfig) { c.Spec.NamespaceSelector.IncludeNamespaces[0] = "ns-b" }},
+		{"role rule verbs", func(c *NamespaceRBACConfig) { c.Spec.RBACTemplates.Roles[0].Rules[0].Verbs[0] = "delete" }},
+		{"role labels", func(c *NamespaceRBACConfig) { c.Spec.RBACTemplates.Roles[0].Labels["role"] = "writer" }},
+		{"binding subjects", func(c *NamespaceRBACConfig) { c.Spec.RBACTemplates.RoleBindings[0].Subjects[0].Name = "bob" }},
+		{"template variables", func(c *NamespaceRBACConfig) { c.Spec.Config.TemplateVariables["env"] = "dev" }},
+		{"nested pointer", func(c *NamespaceRBACConfig) { *c.Spec.Config.Cleanup.UseFinalizer = false }},
+		{"config pointer", func(c *NamespaceRBACConfig) { *c.Spec.Config.RequireMatch = false }},
+		{"conditions", func(c *NamespaceRBACConfig) { c.Status.Conditions[0].Status = metav1.ConditionFalse }},
+		{"applied namespaces", func(c *NamespaceRBACConfig) { c.Status.AppliedNamespaces[0] = "ns-b" }},
+		{"created resources", func(c *NamespaceRBACConfig) { c.Status.CreatedResources.Roles[0].Name = "other" }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			original := newDeepCopyFixture()
+			copied, ok := original.DeepCopyObject().(*NamespaceRBACConfig)
+			if !ok {
+				t.Fatalf("DeepCopyObject returned %T", original.DeepCopyObject())
+			}
+			if !reflect.DeepEqual(original, copied) {
+				t.Fatalf("copy differs from original")
+			}
+
+			tt.mutate(copied)
+			if !reflect.DeepEqual(original, newDeepCopyFixture()) {
+				t.Errorf("mutating the copy changed the original")
+			}
+		})
+	}
+}
+
+func TestDeepCopyObjectList(t *testing.T) {
+	list := &NamespaceRBACConfigList{Items: []NamespaceRBACConfig{*newDeepCopyFixture()}}
+	copied := list.DeepCopyObject().(*NamespaceRBACConfigList)
+
+	copied.Items[0].Status.Conditions[0].Reason = "Changed"
+	if list.Items[0].Status.Conditions[0].Reason != "" {
+		t.Errorf("mutating the copied list changed the original")
+	}
+}
@@ -22,35 +22,62 @@ package v1
 import (
 	rbacv1 "k8s.io/api/rbac/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/runtime"
 )
 
 // NamespaceSelector defines multiple criteria for selecting target namespaces.
 // All specified criteria must match (AND logic) except exclusions (take precedence).
 type NamespaceSelector struct {
-	NameRegex         *string           `json:"nameRegex,omitempty"`         // Regex pattern for namespace names
-	Annotations       map[string]string `json:"annotations,omitempty"`       // Required annotations (exact match)
-	Labels            map[string]string `json:"labels,omitempty"`            // Required labels (exact match)
-	IncludeNamespaces []string          `json:"includeNamespaces,omitempty"` // Explicit inclusion list
-	ExcludeNamespaces []string          `json:"excludeNamespaces,omitempty"` // Explicit exclusion list (takes precedence)
+	NameRegex             *string           `json:"nameRegex,omitempty"`             // Regex pattern for namespace names
+	Annotations           map[string]string `json:"annotations,omitempty"`           // Required annotations (exact match)
+	AnnotationExists      []string          `json:"annotationExists,omitempty"`      // Annotation keys that must be present (any value)
+	AnnotationNotExists   []string          `json:"annotationNotExists,omitempty"`   // Annotation keys that must be absent
+	Labels                map[string]string `json:"labels,omitempty"`                // Required labels (exact match)
+	IncludeNamespaces     []string          `json:"includeNamespaces,omitempty"`     // Explicit inclusion list
+	IncludeNamespaceGlobs []string          `json:"includeNamespaceGlobs,omitempty"` // Glob patterns (path.Match syntax) extending the inclusion list
+	ExcludeNamespaces     []string          `json:"excludeNamespaces,omitempty"`     // Explicit exclusion list (takes precedence)
+	ExcludeNameRegex      []string          `json:"excludeNameRegex,omitempty"`      // Regex patterns excluding namespace names (takes precedence)
+	SkipWhen              string            `json:"skipWhen,omitempty"`              // Template excluding an otherwise matching namespace when it renders truthy
+	HasResourceQuota      *bool             `json:"hasResourceQuota,omitempty"`      // true: only namespaces with a ResourceQuota; false: only namespaces without one
 }
 
 // RoleTemplate defines a template for creating Roles
 type RoleTemplate struct {
 	Name        string              `json:"name"`
-	Rules       []rbacv1.PolicyRule `json:"rules"`
+	Rules       []rbacv1.PolicyRule `json:"rules,omitempty"`
+	SimpleRules []SimpleRule        `json:"simpleRules,omitempty"` // Shorthand rules expanded to verbs by access level, appended after Rules
 	Labels      map[string]string   `json:"labels,omitempty"`
 	Annotations map[string]string   `json:"annotations,omitempty"`
 }
 
 // ClusterRoleTemplate defines a template for creating ClusterRoles
 type ClusterRoleTemplate struct {
-	Name        string              `json:"name"`
-	Rules       []rbacv1.PolicyRule `json:"rules"`
-	Labels      map[string]string   `json:"labels,omitempty"`
-	Annotations map[string]string   `json:"annotations,omitempty"`
+	Name         string              `json:"name"`
+	Rules        []rbacv1.PolicyRule `json:"rules,omitempty"`
+	SimpleRules  []SimpleRule        `json:"simpleRules,omitempty"` // Shorthand rules expanded to verbs by access level, appended after Rules
+	Labels       map[string]string   `json:"labels,omitempty"`
+	Annotations  map[string]string   `json:"annotations,omitempty"`
+	PerNamespace *bool               `json:"perNamespace,omitempty"` // true: name must be unique per namespace; false: one ClusterRole shared by all namespaces
+}
+
+// SimpleRule is a shorthand for a PolicyRule whose verbs follow from an access level
+type SimpleRule struct {
+	APIGroups []string    `json:"apiGroups,omitempty"` // Defaults to the core group ("")
+	Resources []string    `json:"resources"`
+	Access    AccessLevel `json:"access"`
 }
 
+// AccessLevel selects the verbs a SimpleRule expands to
+type AccessLevel string
+
+const (
+	// AccessRead grants get, list and watch
+	AccessRead AccessLevel = "read"
+	// AccessWrite grants read plus create, update, patch and delete
+	AccessWrite AccessLevel = "write"
+	// AccessAdmin grants every verb ("*")
+	AccessAdmin AccessLevel = "admin"
+)
+
 // RoleBindingTemplate defines a template for creating RoleBindings
 type RoleBindingTemplate struct {
 	Name        string            `json:"name"`
@@ -58,6 +85,11 @@ type RoleBindingTemplate struct {
 	Subjects    []rbacv1.Subject  `json:"subjects"`
 	Labels      map[string]string `json:"labels,omitempty"`
 	Annotations map[string]string `json:"annotations,omitempty"`
+	// FromServiceAccountSelector adds every ServiceAccount in the target namespace
+	// whose labels match as an additional subject
+	FromServiceAccountSelector map[string]string `json:"fromServiceAccountSelector,omitempty"`
+	// SubjectsFromVar names a template variable holding a YAML/JSON list of subjects to add
+	SubjectsFromVar string `json:"subjectsFromVar,omitempty"`
 }
 
 // ClusterRoleBindingTemplate defines a template for creating ClusterRoleBindings
@@ -67,10 +99,21 @@ type ClusterRoleBindingTemplate struct {
 	Subjects    []rbacv1.Subject  `json:"subjects"`
 	Labels      map[string]string `json:"labels,omitempty"`
 	Annotations map[string]string `json:"annotations,omitempty"`
+	// SubjectsFromVar names a template variable holding a YAML/JSON list of subjects to add
+	SubjectsFromVar string `json:"subjectsFromVar,omitempty"`
+}
+
+// ServiceAccountTemplate defines a template for creating ServiceAccounts, e.g. for
+// bindings to reference or for cloud IAM integrations driven by annotations
+type ServiceAccountTemplate struct {
+	Name        string            `json:"name"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
 }
 
 // RBACTemplates defines templates for RBAC resources
 type RBACTemplates struct {
+	ServiceAccounts     []ServiceAccountTemplate     `json:"serviceAccounts,omitempty"`
 	Roles               []RoleTemplate               `json:"roles,omitempty"`
 	ClusterRoles        []ClusterRoleTemplate        `json:"clusterRoles,omitempty"`
 	RoleBindings        []RoleBindingTemplate        `json:"roleBindings,omitempty"`
@@ -79,19 +122,52 @@ type RBACTemplates struct {
 
 // NamingConfig defines naming patterns for generated resources
 type NamingConfig struct {
-	Prefix    string `json:"prefix,omitempty"`
-	Suffix    string `json:"suffix,omitempty"`
-	Separator string `json:"separator,omitempty"`
+	Prefix    string         `json:"prefix,omitempty"`
+	Suffix    string         `json:"suffix,omitempty"`
+	Separator string         `json:"separator,omitempty"`
+	Strategy  NamingStrategy `json:"strategy,omitempty"` // How rendered names become resource names (default template)
 }
 
+// NamingStrategy selects how generated resource names are derived from name templates
+type NamingStrategy string
+
+const (
+	// NamingStrategyTemplate uses the rendered name template as is
+	NamingStrategyTemplate NamingStrategy = "template"
+	// NamingStrategyHashed hashes the namespace and the raw name template
+	NamingStrategyHashed NamingStrategy = "hashed"
+	// NamingStrategyTemplateHashed hashes the rendered name template
+	NamingStrategyTemplateHashed NamingStrategy = "template-hashed"
+)
+
 // CleanupConfig defines cleanup behavior
 type CleanupConfig struct {
 	DeleteOrphanedClusterResources *bool  `json:"deleteOrphanedClusterResources,omitempty"`
 	GracePeriodSeconds             *int32 `json:"gracePeriodSeconds,omitempty"`
+	DeleteDanglingBindings         *bool  `json:"deleteDanglingBindings,omitempty"` // Delete owned bindings whose RoleRef no longer resolves
+	UseFinalizer                   *bool  `json:"useFinalizer,omitempty"`           // Clean up via finalizer on deletion (default true); false relies on owner-reference GC
+}
+
+// HooksConfig defines external endpoints notified about RBAC changes
+type HooksConfig struct {
+	PostApplyURL string `json:"postApplyURL,omitempty"` // Receives a JSON POST after RBAC is applied to each namespace
+}
+
+// LimitsConfig caps the size of generated resources to protect the API server
+type LimitsConfig struct {
+	MaxRulesPerRole       *int `json:"maxRulesPerRole,omitempty"`       // Maximum rules in each Role and ClusterRole template
+	MaxSubjectsPerBinding *int `json:"maxSubjectsPerBinding,omitempty"` // Maximum static subjects in each RoleBinding and ClusterRoleBinding template
+}
+
+// ConfigMapReference identifies a ConfigMap by name and namespace
+type ConfigMapReference struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
 }
 
 // MergeStrategy defines how to handle conflicts when multiple configs
-// create resources with the same name.
+// create resources with the same name. It may also be a template that
+// renders to one of the strategies below for each namespace.
 type MergeStrategy string
 
 const (
@@ -101,14 +177,46 @@ const (
 	MergeStrategyReplace MergeStrategy = "replace"
 	// MergeStrategyIgnore skips creation if resource already exists
 	MergeStrategyIgnore MergeStrategy = "ignore"
+	// MergeStrategyAuthoritative replaces rules but merges subjects, so rules stay
+	// operator-owned while bindings can be extended by hand
+	MergeStrategyAuthoritative MergeStrategy = "authoritative"
+)
+
+// OwnerReferenceStrategy selects which object owns generated RBAC resources
+type OwnerReferenceStrategy string
+
+const (
+	// OwnerReferenceNamespace makes the target namespace own namespaced resources;
+	// cluster-scoped resources get no owner reference
+	OwnerReferenceNamespace OwnerReferenceStrategy = "namespace"
+	// OwnerReferenceConfig makes the NamespaceRBACConfig own every generated resource
+	OwnerReferenceConfig OwnerReferenceStrategy = "config"
+	// OwnerReferenceNone sets no owner reference; resources are only removed by cleanup
+	OwnerReferenceNone OwnerReferenceStrategy = "none"
 )
 
 // NamespaceRBACConfigConfig defines additional configuration options
 type NamespaceRBACConfigConfig struct {
-	Naming            *NamingConfig     `json:"naming,omitempty"`
-	MergeStrategy     *MergeStrategy    `json:"mergeStrategy,omitempty"`
-	TemplateVariables map[string]string `json:"templateVariables,omitempty"`
-	Cleanup           *CleanupConfig    `json:"cleanup,omitempty"`
+	Naming                     *NamingConfig           `json:"naming,omitempty"`
+	MergeStrategy              *MergeStrategy          `json:"mergeStrategy,omitempty"`
+	TemplateVariables          map[string]string       `json:"templateVariables,omitempty"`
+	Cleanup                    *CleanupConfig          `json:"cleanup,omitempty"`
+	NamespaceLabels            map[string]string       `json:"namespaceLabels,omitempty"`            // Templated labels stamped on matching namespaces
+	NamespaceAnnotations       map[string]string       `json:"namespaceAnnotations,omitempty"`       // Templated annotations stamped on matching namespaces
+	ExportTo                   *ConfigMapReference     `json:"exportTo,omitempty"`                   // ConfigMap receiving rendered RBAC as YAML (audit only)
+	ResyncInterval             *metav1.Duration        `json:"resyncInterval,omitempty"`             // Overrides the global resync period for this config
+	CommonLabels               map[string]string       `json:"commonLabels,omitempty"`               // Templated labels on every generated resource; template labels win
+	CommonAnnotations          map[string]string       `json:"commonAnnotations,omitempty"`          // Templated annotations on every generated resource; template annotations win
+	Hooks                      *HooksConfig            `json:"hooks,omitempty"`                      // External notifications about applied RBAC
+	ApplyOrder                 []string                `json:"applyOrder,omitempty"`                 // Order RBAC kinds are applied in; omitted kinds follow in the default order
+	MaxConflictRetries         *int                    `json:"maxConflictRetries,omitempty"`         // Update attempts on conflict for Roles/RoleBindings (default 3)
+	OwnerReferenceStrategy     *OwnerReferenceStrategy `json:"ownerReferenceStrategy,omitempty"`     // Owner of generated resources: namespace (default), config or none
+	AllowedTemplateFunctions   []string                `json:"allowedTemplateFunctions,omitempty"`   // When set, templates may only call these engine functions
+	RequireMatch               *bool                   `json:"requireMatch,omitempty"`               // Report NoMatchingNamespaces and Ready=False when nothing matches (default true)
+	AllowOperatorNamespace     *bool                   `json:"allowOperatorNamespace,omitempty"`     // Manage RBAC in the operator's own namespace (excluded by default)
+	Limits                     *LimitsConfig           `json:"limits,omitempty"`                     // Size limits on rules and subjects, checked during validation
+	WaitForNamespaceAnnotation string                  `json:"waitForNamespaceAnnotation,omitempty"` // Defer applying RBAC until a matching namespace has this annotation set to "true"
+	SubjectMatchIgnoreCase     *bool                   `json:"subjectMatchIgnoreCase,omitempty"`     // Treat subjects differing only in name case as duplicates when merging
 }
 
 // NamespaceRBACConfigSpec defines the desired state of NamespaceRBACConfig
@@ -116,6 +224,9 @@ type NamespaceRBACConfigSpec struct {
 	NamespaceSelector NamespaceSelector          `json:"namespaceSelector"`
 	RBACTemplates     RBACTemplates              `json:"rbacTemplates"`
 	Config            *NamespaceRBACConfigConfig `json:"config,omitempty"`
+	// Suspend stops applying RBAC and cleaning up unmatched namespaces while true.
+	// Deleting the config still cleans up its resources.
+	Suspend *bool `json:"suspend,omitempty"`
 }
 
 // ResourceReference tracks a created resource
@@ -132,12 +243,22 @@ type CreatedResources struct {
 	ClusterRoleBindings []string            `json:"clusterRoleBindings,omitempty"`
 }
 
+// ErrorRecord captures a single reconcile failure for triage
+type ErrorRecord struct {
+	Timestamp metav1.Time `json:"timestamp"`
+	Namespace string      `json:"namespace,omitempty"` // Empty for failures not tied to a namespace
+	Message   string      `json:"message"`
+}
+
 // NamespaceRBACConfigStatus defines the observed state of NamespaceRBACConfig
 type NamespaceRBACConfigStatus struct {
-	Conditions         []metav1.Condition `json:"conditions,omitempty"`
-	AppliedNamespaces  []string           `json:"appliedNamespaces,omitempty"`
-	CreatedResources   *CreatedResources  `json:"createdResources,omitempty"`
-	ObservedGeneration int64              `json:"observedGeneration,omitempty"`
+	Conditions           []metav1.Condition `json:"conditions,omitempty"`
+	AppliedNamespaces    []string           `json:"appliedNamespaces,omitempty"`    // Bounded; past the cap the last entry reads "… and N more"
+	ManagedResourceCount int                `json:"managedResourceCount,omitempty"` // Number of managed namespaces, including any summarised out of AppliedNamespaces
+	CreatedResources     *CreatedResources  `json:"createdResources,omitempty"`
+	RecentErrors         []ErrorRecord      `json:"recentErrors,omitempty"` // Oldest first, bounded
+	SpecHash             string             `json:"specHash,omitempty"`     // SHA-256 of the spec's canonical JSON
+	ObservedGeneration   int64              `json:"observedGeneration,omitempty"`
 }
 
 // NamespaceRBACConfig defines automatic RBAC management for namespaces.
@@ -151,34 +272,9 @@ type NamespaceRBACConfig struct {
 	Status NamespaceRBACConfigStatus `json:"status,omitempty"`
 }
 
-// DeepCopyObject implements runtime.Object
-func (in *NamespaceRBACConfig) DeepCopyObject() runtime.Object {
-	return &NamespaceRBACConfig{
-		TypeMeta:   in.TypeMeta,
-		ObjectMeta: *in.ObjectMeta.DeepCopy(),
-		Spec:       in.Spec,
-		Status:     in.Status,
-	}
-}
-
 // NamespaceRBACConfigList contains a list of NamespaceRBACConfig
 type NamespaceRBACConfigList struct {
 	metav1.TypeMeta `json:",inline"`
 	metav1.ListMeta `json:"metadata,omitempty"`
 	Items           []NamespaceRBACConfig `json:"items"`
 }
-
-// DeepCopyObject implements runtime.Object
-func (in *NamespaceRBACConfigList) DeepCopyObject() runtime.Object {
-	out := &NamespaceRBACConfigList{
-		TypeMeta: in.TypeMeta,
-		ListMeta: *in.ListMeta.DeepCopy(),
-	}
-	if in.Items != nil {
-		out.Items = make([]NamespaceRBACConfig, len(in.Items))
-		for i := range in.Items {
-			out.Items[i] = *in.Items[i].DeepCopyObject().(*NamespaceRBACConfig)
-		}
-	}
-	return out
-}
@@ -18,41 +18,66 @@ package namespace
 
 import (
 	"context"
+	"fmt"
+	"runtime/debug"
+	"sync"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/util/workqueue"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	"github.com/go-logr/logr"
 	rbacoperatorv1 "github.com/cropalato/k8s-acl-operator/pkg/apis/rbac/v1"
 	"github.com/cropalato/k8s-acl-operator/pkg/health"
+	"github.com/cropalato/k8s-acl-operator/pkg/metrics"
 	"github.com/cropalato/k8s-acl-operator/pkg/rbac"
+	"github.com/cropalato/k8s-acl-operator/pkg/tracing"
 	"github.com/cropalato/k8s-acl-operator/pkg/utils"
 )
 
-// NamespaceReconciler reconciles namespace events to trigger RBAC management
+// NoConfigsCacheTTL is how long an empty NamespaceRBACConfig list is trusted before
+// listing again when a namespace is created or updated. Creating a config drops the
+// cached result, so a namespace that stops matching a new config is still cleaned up.
+// Deletions always list configs, since nothing would retry their cleanup.
+const NoConfigsCacheTTL = 10 * time.Second
+
+// NamespaceReconciler reconciles namespace events to clean up RBAC. It is authoritative
+// for cleanup when a namespace stops matching a config or is deleted; applying RBAC to
+// matching namespaces is left to the NamespaceRBACConfig controller.
 type NamespaceReconciler struct {
 	client.Client
 	Scheme        *runtime.Scheme
 	Log           logr.Logger
 	rbacManager   *rbac.Manager
 	healthChecker *health.Checker
+
+	noConfigsMu    sync.Mutex
+	noConfigsUntil time.Time // Namespace create/update events are skipped until then; zero when configs exist
 }
 
 // NewNamespaceReconciler creates a new namespace reconciler
-func NewNamespaceReconciler(client client.Client, scheme *runtime.Scheme, log logr.Logger, healthChecker *health.Checker) *NamespaceReconciler {
+func NewNamespaceReconciler(client client.Client, scheme *runtime.Scheme, log logr.Logger, healthChecker *health.Checker, rbacOpts rbac.Options) *NamespaceReconciler {
 	return &NamespaceReconciler{
 		Client:        client,
 		Scheme:        scheme,
 		Log:           log,
-		rbacManager:   rbac.NewManager(client),
+		rbacManager:   rbac.NewManager(client, rbacOpts),
 		healthChecker: healthChecker,
 	}
 }
 
-// +kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch;patch
+// +kubebuilder:rbac:groups="",resources=resourcequotas,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=serviceaccounts,verbs=get;list;watch;delete
 // +kubebuilder:rbac:groups=rbac.operator.io,resources=namespacerbacconfigs,verbs=get;list;watch
 // +kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=roles,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=clusterroles,verbs=get;list;watch;create;update;patch;delete
@@ -60,12 +85,22 @@ func NewNamespaceReconciler(client client.Client, scheme *runtime.Scheme, log lo
 // +kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=clusterrolebindings,verbs=get;list;watch;create;update;patch;delete
 
 // Reconcile handles namespace events and applies/removes RBAC as needed
-func (r *NamespaceReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+func (r *NamespaceReconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, err error) {
+	start := time.Now()
+	ctx, span := tracing.Start(ctx, "Namespace.Reconcile", tracing.NamespaceKey.String(req.Name))
+	defer span.End()
 	log := r.Log.WithValues("namespace", req.Name)
 
+	// Record reconcile metrics under the namespace name
+	defer func() {
+		metrics.RecordReconciliation(ctx, req.Name, "Namespace", time.Since(start), err)
+	}()
+	// Deferred after the metrics above so they record the recovered error
+	defer r.recoverPanic(log, &err)
+
 	// Fetch the namespace
 	namespace := &corev1.Namespace{}
-	err := r.Get(ctx, req.NamespacedName, namespace)
+	err = r.Get(ctx, req.NamespacedName, namespace)
 	if err != nil {
 		if errors.IsNotFound(err) {
 			// Namespace was deleted, handle cleanup
@@ -81,10 +116,30 @@ func (r *NamespaceReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 	return r.handleNamespaceCreateOrUpdate(ctx, namespace, log)
 }
 
+// recoverPanic converts a panic during reconciliation into an error, so one bad config
+// cannot crash the manager; the request is then requeued with backoff like any failure.
+// It must be deferred directly from Reconcile.
+func (r *NamespaceReconciler) recoverPanic(log logr.Logger, err *error) {
+	p := recover()
+	if p == nil {
+		return
+	}
+	*err = fmt.Errorf("recovered from panic: %v", p)
+	log.Error(*err, "Reconcile panicked", "stacktrace", string(debug.Stack()))
+	r.healthChecker.SetHealthy(false)
+	metrics.RecordPanic("Namespace")
+}
+
 // handleNamespaceCreateOrUpdate handles namespace creation or update events
 func (r *NamespaceReconciler) handleNamespaceCreateOrUpdate(ctx context.Context, namespace *corev1.Namespace, log logr.Logger) (ctrl.Result, error) {
 	log.Info("Processing namespace create/update event")
 
+	// Nothing to do while no configs exist
+	if r.noConfigsCached() {
+		r.healthChecker.RecordReconcile()
+		return ctrl.Result{}, nil
+	}
+
 	// Get all NamespaceRBACConfigs
 	configList := &rbacoperatorv1.NamespaceRBACConfigList{}
 	if err := r.List(ctx, configList); err != nil {
@@ -92,28 +147,35 @@ func (r *NamespaceReconciler) handleNamespaceCreateOrUpdate(ctx context.Context,
 		r.healthChecker.SetHealthy(false)
 		return ctrl.Result{}, err
 	}
+	if r.recordConfigCount(len(configList.Items)) == 0 {
+		log.V(1).Info("No NamespaceRBACConfigs exist, skipping")
+		r.healthChecker.RecordReconcile()
+		return ctrl.Result{}, nil
+	}
 
-	// Apply RBAC for all matching configs
+	// Clean up configs the namespace no longer matches. Matching configs are applied
+	// by the NamespaceRBACConfig controller, whose namespace watch fires for the same
+	// event; applying here too would do every apply twice.
 	for _, config := range configList.Items {
-		matches, err := utils.NamespaceMatches(namespace, config.Spec.NamespaceSelector)
+		if utils.BoolPtrValue(config.Spec.Suspend) {
+			log.Info("Skipping suspended config", "config", config.Name)
+			continue
+		}
+
+		matches, err := r.rbacManager.NamespaceMatches(ctx, namespace, &config)
 		if err != nil {
 			log.Error(err, "Failed to check namespace match", "config", config.Name)
 			continue
 		}
-
 		if matches {
-			log.Info("Applying RBAC for namespace", "config", config.Name)
-			if err := r.rbacManager.ApplyRBACForNamespace(ctx, namespace, &config); err != nil {
-				log.Error(err, "Failed to apply RBAC", "config", config.Name)
-				// Continue with other configs even if one fails
-			}
-		} else {
-			// If namespace no longer matches, clean up any previously created resources
-			log.Info("Namespace no longer matches config, cleaning up", "config", config.Name)
-			if err := r.rbacManager.CleanupRBACForNamespace(ctx, namespace.Name, &config); err != nil {
-				log.Error(err, "Failed to cleanup RBAC", "config", config.Name)
-				// Continue with other configs even if one fails
-			}
+			log.V(1).Info("Namespace matches config, leaving apply to the NamespaceRBACConfig controller", "config", config.Name)
+			continue
+		}
+
+		log.Info("Namespace does not match config, cleaning up", "config", config.Name)
+		if err := r.cleanupRBAC(ctx, namespace.Name, &config); err != nil {
+			log.Error(err, "Failed to cleanup RBAC", "config", config.Name)
+			// Continue with other configs even if one fails
 		}
 	}
 
@@ -125,18 +187,29 @@ func (r *NamespaceReconciler) handleNamespaceCreateOrUpdate(ctx context.Context,
 func (r *NamespaceReconciler) handleNamespaceDeletion(ctx context.Context, namespaceName string, log logr.Logger) (ctrl.Result, error) {
 	log.Info("Processing namespace deletion event")
 
-	// Get all NamespaceRBACConfigs
+	// Get all NamespaceRBACConfigs. The no-configs cache is not consulted: a deletion
+	// is not retried, so skipping it on a stale result would leak cluster-scoped RBAC.
 	configList := &rbacoperatorv1.NamespaceRBACConfigList{}
 	if err := r.List(ctx, configList); err != nil {
 		log.Error(err, "Failed to list NamespaceRBACConfigs")
 		r.healthChecker.SetHealthy(false)
 		return ctrl.Result{}, err
 	}
+	if r.recordConfigCount(len(configList.Items)) == 0 {
+		log.V(1).Info("No NamespaceRBACConfigs exist, skipping")
+		r.healthChecker.RecordReconcile()
+		return ctrl.Result{}, nil
+	}
 
 	// Clean up RBAC resources for all configs
 	for _, config := range configList.Items {
+		if utils.BoolPtrValue(config.Spec.Suspend) {
+			log.Info("Skipping suspended config", "config", config.Name)
+			continue
+		}
+
 		log.Info("Cleaning up RBAC for deleted namespace", "config", config.Name)
-		if err := r.rbacManager.CleanupRBACForNamespace(ctx, namespaceName, &config); err != nil {
+		if err := r.cleanupRBAC(ctx, namespaceName, &config); err != nil {
 			log.Error(err, "Failed to cleanup RBAC", "config", config.Name)
 			// Continue with other configs even if one fails
 		}
@@ -146,9 +219,66 @@ func (r *NamespaceReconciler) handleNamespaceDeletion(ctx context.Context, names
 	return ctrl.Result{}, nil
 }
 
+// cleanupRBAC removes the config's RBAC from a namespace it no longer applies to
+func (r *NamespaceReconciler) cleanupRBAC(ctx context.Context, namespaceName string, config *rbacoperatorv1.NamespaceRBACConfig) error {
+	matching, err := r.rbacManager.MatchingNamespaces(ctx, config)
+	if err != nil {
+		return err
+	}
+	return r.rbacManager.CleanupRBACForNamespace(ctx, namespaceName, config, matching)
+}
+
+// noConfigsCached reports whether a recent list found no NamespaceRBACConfigs
+func (r *NamespaceReconciler) noConfigsCached() bool {
+	r.noConfigsMu.Lock()
+	defer r.noConfigsMu.Unlock()
+	return time.Now().Before(r.noConfigsUntil)
+}
+
+// forgetNoConfigs drops a cached empty config list, e.g. once a config is created
+func (r *NamespaceReconciler) forgetNoConfigs() {
+	r.noConfigsMu.Lock()
+	defer r.noConfigsMu.Unlock()
+	r.noConfigsUntil = time.Time{}
+}
+
+// configEventHandler enqueues nothing; it only drops the cached empty config list when
+// a config is created, so namespace events are no longer skipped
+func (r *NamespaceReconciler) configEventHandler() handler.EventHandler {
+	return handler.Funcs{
+		CreateFunc: func(context.Context, event.CreateEvent, workqueue.RateLimitingInterface) {
+			r.forgetNoConfigs()
+		},
+	}
+}
+
+// recordConfigCount caches an empty config list for NoConfigsCacheTTL and returns count
+func (r *NamespaceReconciler) recordConfigCount(count int) int {
+	r.noConfigsMu.Lock()
+	defer r.noConfigsMu.Unlock()
+	if count == 0 {
+		r.noConfigsUntil = time.Now().Add(NoConfigsCacheTTL)
+	} else {
+		r.noConfigsUntil = time.Time{}
+	}
+	return count
+}
+
 // SetupWithManager sets up the controller with the Manager
 func (r *NamespaceReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&corev1.Namespace{}).
+		// A quota appearing or disappearing can change hasResourceQuota matches
+		Watches(&corev1.ResourceQuota{}, handler.EnqueueRequestsFromMapFunc(mapResourceQuotaToNamespace), builder.WithPredicates(predicate.Funcs{
+			UpdateFunc: func(event.UpdateEvent) bool { return false },
+		})).
+		Watches(&rbacoperatorv1.NamespaceRBACConfig{}, r.configEventHandler()).
 		Complete(r)
 }
+
+// mapResourceQuotaToNamespace maps a ResourceQuota to the namespace containing it
+func mapResourceQuotaToNamespace(ctx context.Context, obj client.Object) []reconcile.Request {
+	return []reconcile.Request{{
+		NamespacedName: client.ObjectKey{Name: obj.GetNamespace()},
+	}}
+}
@@ -0,0 +1,368 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package namespace
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	rbacoperatorv1 "github.com/cropalato/k8s-acl-operator/pkg/apis/rbac/v1"
+	"github.com/cropalato/k8s-acl-operator/pkg/controller/namespacerbacconfig"
+	"github.com/cropalato/k8s-acl-operator/pkg/health"
+	"github.com/cropalato/k8s-acl-operator/pkg/metrics"
+	"github.com/cropalato/k8s-acl-operator/pkg/rbac"
+	"github.com/cropalato/k8s-acl-operator/pkg/utils"
+)
+
+// newTestReconciler returns a reconciler backed by a fake client holding objs
+func newTestReconciler(t *testing.T, funcs interceptor.Funcs, objs ...client.Object) (*NamespaceReconciler, client.Client) {
+	t.Helper()
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := rbacoperatorv1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	c := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(objs...).
+		WithStatusSubresource(&rbacoperatorv1.NamespaceRBACConfig{}).
+		WithInterceptorFuncs(funcs).
+		Build()
+	return NewNamespaceReconciler(c, scheme, logr.Discard(), health.NewChecker(logr.Discard()), rbac.Options{}), c
+}
+
+func TestReconcileRecordsMetrics(t *testing.T) {
+	tests := []struct {
+		name          string
+		objs          []client.Object
+		getErr        error
+		wantResult    string
+		wantErrorType string
+	}{
+		{
+			name:       "existing namespace",
+			objs:       []client.Object{&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-a"}}},
+			wantResult: "success",
+		},
+		{
+			name:       "deleted namespace",
+			wantResult: "success",
+		},
+		{
+			name:          "get fails",
+			getErr:        errors.NewTimeoutError("slow", 1),
+			wantResult:    "error",
+			wantErrorType: "timeout",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, _ := newTestReconciler(t, interceptor.Funcs{
+				Get: func(ctx context.Context, c client.WithWatch, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+					if tt.getErr != nil {
+						return tt.getErr
+					}
+					return c.Get(ctx, key, obj, opts...)
+				},
+			}, tt.objs...)
+			metrics.ResetMetrics()
+
+			_, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: types.NamespacedName{Name: "team-a"}})
+			if (err != nil) != (tt.getErr != nil) {
+				t.Fatalf("Reconcile() error = %v", err)
+			}
+
+			if got := testutil.ToFloat64(metrics.ReconciliationTotal.WithLabelValues("team-a", "Namespace", tt.wantResult)); got != 1 {
+				t.Errorf("%s reconciles = %v, want 1", tt.wantResult, got)
+			}
+			if got := testutil.CollectAndCount(metrics.ReconciliationDuration); got != 1 {
+				t.Errorf("reconcile duration series = %d, want 1", got)
+			}
+			wantErrors := 0
+			if tt.wantErrorType != "" {
+				wantErrors = 1
+				if got := testutil.ToFloat64(metrics.ReconciliationErrors.WithLabelValues("team-a", "Namespace", tt.wantErrorType)); got != 1 {
+					t.Errorf("%s errors = %v, want 1", tt.wantErrorType, got)
+				}
+			}
+			if got := testutil.CollectAndCount(metrics.ReconciliationErrors); got != wantErrors {
+				t.Errorf("reconcile error series = %d, want %d", got, wantErrors)
+			}
+		})
+	}
+}
+
+func TestReconcileSkipsSuspendedConfigs(t *testing.T) {
+	tests := []struct {
+		name     string
+		suspend  *bool
+		wantRole bool
+	}{
+		{name: "unset"},
+		{name: "not suspended", suspend: utils.GetBoolPtr(false)},
+		{name: "suspended", suspend: utils.GetBoolPtr(true), wantRole: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &rbacoperatorv1.NamespaceRBACConfig{
+				ObjectMeta: metav1.ObjectMeta{Name: "cfg"},
+				Spec: rbacoperatorv1.NamespaceRBACConfigSpec{
+					Suspend:           tt.suspend,
+					NamespaceSelector: rbacoperatorv1.NamespaceSelector{Labels: map[string]string{"team": "a"}},
+					RBACTemplates: rbacoperatorv1.RBACTemplates{
+						Roles: []rbacoperatorv1.RoleTemplate{{Name: "viewer"}},
+					},
+				},
+			}
+			ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-a", Labels: map[string]string{"team": "a"}}}
+			r, c := newTestReconciler(t, interceptor.Funcs{}, config, ns)
+			if _, err := rbac.NewManager(c, rbac.Options{}).ApplyRBACForNamespace(context.Background(), ns, config, []string{ns.Name}); err != nil {
+				t.Fatal(err)
+			}
+
+			// The namespace stops matching, which normally cleans up its RBAC
+			if err := c.Get(context.Background(), client.ObjectKeyFromObject(ns), ns); err != nil {
+				t.Fatal(err)
+			}
+			ns.Labels = nil
+			if err := c.Update(context.Background(), ns); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: types.NamespacedName{Name: "team-a"}}); err != nil {
+				t.Fatal(err)
+			}
+
+			err := c.Get(context.Background(), types.NamespacedName{Namespace: "team-a", Name: "viewer"}, &rbacv1.Role{})
+			if gotRole := err == nil; gotRole != tt.wantRole {
+				t.Errorf("role exists = %t, want %t (err %v)", gotRole, tt.wantRole, err)
+			}
+		})
+	}
+}
+
+func TestReconcileSkipsWorkWhileNoConfigsExist(t *testing.T) {
+	tests := []struct {
+		name       string
+		objs       []client.Object
+		wantCached bool // Whether the empty config list is reused by later events
+	}{
+		{name: "namespace update", objs: []client.Object{&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-a"}}}, wantCached: true},
+		// A skipped deletion would never be retried, so it always lists configs
+		{name: "namespace deletion"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var configLists, otherCalls int
+			r, _ := newTestReconciler(t, interceptor.Funcs{
+				List: func(ctx context.Context, c client.WithWatch, list client.ObjectList, opts ...client.ListOption) error {
+					if _, ok := list.(*rbacoperatorv1.NamespaceRBACConfigList); ok {
+						configLists++
+					} else {
+						otherCalls++
+					}
+					return c.List(ctx, list, opts...)
+				},
+				Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+					otherCalls++
+					return c.Create(ctx, obj, opts...)
+				},
+				Delete: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.DeleteOption) error {
+					otherCalls++
+					return c.Delete(ctx, obj, opts...)
+				},
+			}, tt.objs...)
+			reconcile := func() {
+				t.Helper()
+				if _, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: types.NamespacedName{Name: "team-a"}}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			wantLists := func(cached, uncached int) int {
+				if tt.wantCached {
+					return cached
+				}
+				return uncached
+			}
+
+			reconcile()
+			if configLists != 1 {
+				t.Errorf("first event listed configs %d times, want 1", configLists)
+			}
+
+			// Within NoConfigsCacheTTL the empty list is trusted
+			reconcile()
+			reconcile()
+			if want := wantLists(1, 3); configLists != want {
+				t.Errorf("repeated events listed configs %d times in total, want %d", configLists, want)
+			}
+
+			// Once the cache expires, configs are listed again
+			r.noConfigsUntil = time.Time{}
+			reconcile()
+			if want := wantLists(2, 4); configLists != want {
+				t.Errorf("after expiry configs were listed %d times in total, want %d", configLists, want)
+			}
+
+			// Creating a config drops the cached empty list
+			r.configEventHandler().Create(context.Background(), event.CreateEvent{Object: &rbacoperatorv1.NamespaceRBACConfig{}}, nil)
+			reconcile()
+			if want := wantLists(3, 5); configLists != want {
+				t.Errorf("after a config was created configs were listed %d times in total, want %d", configLists, want)
+			}
+			if otherCalls != 0 {
+				t.Errorf("%d other list/write calls made without configs, want 0", otherCalls)
+			}
+		})
+	}
+}
+
+func TestReconcileRecoversFromPanic(t *testing.T) {
+	tests := []struct {
+		name      string
+		panicGet  bool
+		wantPanic bool
+	}{
+		{name: "no panic"},
+		{name: "panic while getting the namespace", panicGet: true, wantPanic: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nsName := "panic-" + strings.ReplaceAll(tt.name, " ", "-")
+			r, _ := newTestReconciler(t, interceptor.Funcs{
+				Get: func(ctx context.Context, c client.WithWatch, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+					if tt.panicGet {
+						panic("boom")
+					}
+					return c.Get(ctx, key, obj, opts...)
+				},
+			}, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: nsName}})
+			r.healthChecker.RecordReconcile()
+			panics := metrics.ReconcilePanics.WithLabelValues("Namespace")
+			panicErrors := metrics.ReconciliationErrors.WithLabelValues(nsName, "Namespace", "panic")
+			panicsBefore, errorsBefore := testutil.ToFloat64(panics), testutil.ToFloat64(panicErrors)
+
+			_, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: types.NamespacedName{Name: nsName}})
+
+			if gotPanic := err != nil && strings.Contains(err.Error(), "recovered from panic: boom"); gotPanic != tt.wantPanic {
+				t.Fatalf("Reconcile() error = %v, want recovered panic %v", err, tt.wantPanic)
+			}
+			want := 0.0
+			if tt.wantPanic {
+				want = 1
+			}
+			if got := testutil.ToFloat64(panics) - panicsBefore; got != want {
+				t.Errorf("rbac_operator_reconcile_panics_total increased by %v, want %v", got, want)
+			}
+			if got := testutil.ToFloat64(panicErrors) - errorsBefore; got != want {
+				t.Errorf("panic reconciliation errors increased by %v, want %v", got, want)
+			}
+			if healthy := r.healthChecker.IsHealthy(); healthy == tt.wantPanic {
+				t.Errorf("IsHealthy() = %v, want %v", healthy, !tt.wantPanic)
+			}
+		})
+	}
+}
+
+func TestNamespaceEventAppliesOnce(t *testing.T) {
+	// RBAC writes per controller, keyed by "<controller> <verb> <kind>"
+	writes := map[string]int{}
+	var controller string
+	count := func(verb string, obj client.Object) {
+		switch obj.(type) {
+		case *rbacv1.Role, *rbacv1.RoleBinding:
+			writes[fmt.Sprintf("%s %s %T", controller, verb, obj)]++
+		}
+	}
+	namespaceReconciler, c := newTestReconciler(t, interceptor.Funcs{
+		Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+			count("create", obj)
+			return c.Create(ctx, obj, opts...)
+		},
+		Update: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.UpdateOption) error {
+			count("update", obj)
+			return c.Update(ctx, obj, opts...)
+		},
+	}, &rbacoperatorv1.NamespaceRBACConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "cfg", Generation: 1},
+		Spec: rbacoperatorv1.NamespaceRBACConfigSpec{
+			NamespaceSelector: rbacoperatorv1.NamespaceSelector{Labels: map[string]string{"team": "a"}},
+			RBACTemplates: rbacoperatorv1.RBACTemplates{
+				Roles: []rbacoperatorv1.RoleTemplate{{Name: "viewer"}},
+				RoleBindings: []rbacoperatorv1.RoleBindingTemplate{{
+					Name:     "viewer",
+					RoleRef:  rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "Role", Name: "viewer"},
+					Subjects: []rbacv1.Subject{{Kind: rbacv1.GroupKind, APIGroup: rbacv1.GroupName, Name: "team-a"}},
+				}},
+			},
+		},
+	})
+	configReconciler := namespacerbacconfig.NewNamespaceRBACConfigReconciler(c, namespaceReconciler.Scheme, logr.Discard(), health.NewChecker(logr.Discard()), rbac.Options{})
+
+	// Settle the config before any namespace matches it
+	configReq := ctrl.Request{NamespacedName: types.NamespacedName{Name: "cfg"}}
+	for i := 0; i < 2; i++ {
+		if _, err := configReconciler.Reconcile(context.Background(), configReq); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// A matching namespace is created: both controllers' watches fire for the event
+	if err := c.Create(context.Background(), &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-a", Labels: map[string]string{"team": "a"}}}); err != nil {
+		t.Fatal(err)
+	}
+	controller = "Namespace"
+	if _, err := namespaceReconciler.Reconcile(context.Background(), ctrl.Request{NamespacedName: types.NamespacedName{Name: "team-a"}}); err != nil {
+		t.Fatal(err)
+	}
+	controller = "NamespaceRBACConfig"
+	if _, err := configReconciler.Reconcile(context.Background(), configReq); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]int{
+		"NamespaceRBACConfig create *v1.Role":        1,
+		"NamespaceRBACConfig create *v1.RoleBinding": 1,
+	}
+	if !reflect.DeepEqual(writes, want) {
+		t.Errorf("RBAC writes = %v, want %v", writes, want)
+	}
+}
@@ -0,0 +1,92 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package namespacerbacconfig
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+
+	rbacoperatorv1 "github.com/cropalato/k8s-acl-operator/pkg/apis/rbac/v1"
+)
+
+// DefaultHookTimeout bounds each post-apply hook request
+const DefaultHookTimeout = 5 * time.Second
+
+// HookPayload is the JSON body POSTed to Config.Hooks.PostApplyURL after RBAC
+// is applied to a namespace
+type HookPayload struct {
+	Config    string         `json:"config"`
+	Namespace string         `json:"namespace"`
+	Resources []HookResource `json:"resources"`
+}
+
+// HookResource identifies one applied RBAC resource
+type HookResource struct {
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// callPostApplyHook POSTs the applied resources for namespace to the config's
+// post-apply URL. A non-2xx response is returned as an error.
+func (r *NamespaceRBACConfigReconciler) callPostApplyHook(ctx context.Context, config *rbacoperatorv1.NamespaceRBACConfig, namespace string, resources []client.Object) error {
+	payload := HookPayload{
+		Config:    config.Name,
+		Namespace: namespace,
+		Resources: make([]HookResource, 0, len(resources)),
+	}
+	for _, obj := range resources {
+		gvk, err := apiutil.GVKForObject(obj, r.Scheme)
+		if err != nil {
+			return fmt.Errorf("failed to resolve kind for %s: %w", obj.GetName(), err)
+		}
+		payload.Resources = append(payload.Resources, HookResource{
+			Kind:      gvk.Kind,
+			Name:      obj.GetName(),
+			Namespace: obj.GetNamespace(),
+		})
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode hook payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, config.Spec.Config.Hooks.PostApplyURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to build hook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := r.hookClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("post-apply hook failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("post-apply hook returned %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,138 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package namespacerbacconfig
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	rbacoperatorv1 "github.com/cropalato/k8s-acl-operator/pkg/apis/rbac/v1"
+	"github.com/cropalato/k8s-acl-operator/pkg/rbac"
+)
+
+func TestReconcileCallsPostApplyHook(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     int
+		wantStatus metav1.ConditionStatus
+		wantReason string
+	}{
+		{name: "delivered", status: http.StatusOK, wantStatus: metav1.ConditionFalse, wantReason: ReasonHookDelivered},
+		{name: "endpoint error", status: http.StatusInternalServerError, wantStatus: metav1.ConditionTrue, wantReason: ReasonHookError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				mu       sync.Mutex
+				payloads []HookPayload
+			)
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				if req.Method != http.MethodPost || req.Header.Get("Content-Type") != "application/json" {
+					t.Errorf("hook request = %s %q, want POST application/json", req.Method, req.Header.Get("Content-Type"))
+				}
+				var payload HookPayload
+				if err := json.NewDecoder(req.Body).Decode(&payload); err != nil {
+					t.Errorf("decode payload: %v", err)
+				}
+				mu.Lock()
+				payloads = append(payloads, payload)
+				mu.Unlock()
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+
+			config := testConfig("cfg")
+			config.Spec.Config = &rbacoperatorv1.NamespaceRBACConfigConfig{
+				Hooks: &rbacoperatorv1.HooksConfig{PostApplyURL: server.URL},
+			}
+			r, _ := newTestReconciler(t, rbac.Options{}, interceptor.Funcs{},
+				config, testNamespace("team-0", map[string]string{"team": "a"}))
+
+			stored := reconcileConfig(t, r, "cfg")
+
+			mu.Lock()
+			defer mu.Unlock()
+			if len(payloads) != 1 {
+				t.Fatalf("hook called %d times, want 1", len(payloads))
+			}
+			got := payloads[0]
+			sort.Slice(got.Resources, func(i, j int) bool { return got.Resources[i].Kind < got.Resources[j].Kind })
+			want := HookPayload{
+				Config:    "cfg",
+				Namespace: "team-0",
+				Resources: []HookResource{
+					{Kind: "Role", Name: "viewer", Namespace: "team-0"},
+					{Kind: "RoleBinding", Name: "viewer", Namespace: "team-0"},
+				},
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("hook payload = %+v, want %+v", got, want)
+			}
+
+			cond := meta.FindStatusCondition(stored.Status.Conditions, ConditionTypeHookFailed)
+			if cond == nil {
+				t.Fatalf("no %s condition in %v", ConditionTypeHookFailed, stored.Status.Conditions)
+			}
+			if cond.Status != tt.wantStatus || cond.Reason != tt.wantReason {
+				t.Errorf("%s = %s/%s, want %s/%s", ConditionTypeHookFailed, cond.Status, cond.Reason, tt.wantStatus, tt.wantReason)
+			}
+			if stored.Status.ManagedResourceCount != 1 {
+				t.Errorf("ManagedResourceCount = %d, want 1 despite hook result", stored.Status.ManagedResourceCount)
+			}
+		})
+	}
+}
+
+func TestValidateConfigHookURL(t *testing.T) {
+	tests := []struct {
+		name     string
+		url      string
+		wantErrs []string
+	}{
+		{name: "https", url: "https://hooks.example.com/rbac"},
+		{name: "relative", url: "/rbac", wantErrs: []string{"spec.config.hooks.postApplyURL"}},
+		{name: "unsupported scheme", url: "ftp://hooks.example.com", wantErrs: []string{"spec.config.hooks.postApplyURL"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, _ := newTestReconciler(t, rbac.Options{}, interceptor.Funcs{})
+			config := testConfig("cfg")
+			config.Spec.Config = &rbacoperatorv1.NamespaceRBACConfigConfig{
+				Hooks: &rbacoperatorv1.HooksConfig{PostApplyURL: tt.url},
+			}
+
+			var got []string
+			for _, err := range r.validateConfig(config) {
+				got = append(got, err.Field)
+			}
+			if !reflect.DeepEqual(got, tt.wantErrs) {
+				t.Errorf("validation errors on %v, want %v", got, tt.wantErrs)
+			}
+		})
+	}
+}
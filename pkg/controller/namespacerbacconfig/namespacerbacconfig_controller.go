@@ -22,24 +22,41 @@ package namespacerbacconfig
 import (
 	"context"
 	"fmt"
+	"net/http"
+	"net/url"
+	"path"
 	"regexp"
+	"runtime/debug"
+	"sort"
+	"strings"
+	"sync"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/yaml"
 
 	"github.com/go-logr/logr"
 	rbacoperatorv1 "github.com/cropalato/k8s-acl-operator/pkg/apis/rbac/v1"
 	"github.com/cropalato/k8s-acl-operator/pkg/health"
 	"github.com/cropalato/k8s-acl-operator/pkg/metrics"
 	"github.com/cropalato/k8s-acl-operator/pkg/rbac"
+	"github.com/cropalato/k8s-acl-operator/pkg/tracing"
 	"github.com/cropalato/k8s-acl-operator/pkg/utils"
 )
 
@@ -53,6 +70,35 @@ const (
 	// ConditionTypeDegraded indicates whether the NamespaceRBACConfig is degraded
 	// due to errors during reconciliation
 	ConditionTypeDegraded = "Degraded"
+	// ConditionTypeMergeFrozen indicates whether existing resources were skipped
+	// because they carry the merge-freeze annotation
+	ConditionTypeMergeFrozen = "MergeFrozen"
+	// ConditionTypeUnavailable indicates the circuit breaker has paused reconciliation
+	// after repeated consecutive failures
+	ConditionTypeUnavailable = "Unavailable"
+	// ConditionTypeHookFailed indicates the post-apply hook could not be delivered;
+	// it is a warning and does not fail the reconcile
+	ConditionTypeHookFailed = "HookFailed"
+	// ConditionTypeSuspended indicates whether reconciliation is suspended via spec.suspend
+	ConditionTypeSuspended = "Suspended"
+	// ConditionTypeDriftCorrected records the last reconcile that restored resources
+	// deleted or modified outside the operator
+	ConditionTypeDriftCorrected = "DriftCorrected"
+	// ConditionTypeNoMatchingNamespaces indicates the selector matched no namespaces,
+	// which usually points at a misconfigured selector
+	ConditionTypeNoMatchingNamespaces = "NoMatchingNamespaces"
+	// ConditionTypeEscalationDenied indicates the API server refused an RBAC write because
+	// it grants permissions the operator does not hold
+	ConditionTypeEscalationDenied = "EscalationDenied"
+	// ConditionTypeTemplateWarnings indicates templates rendered suspicious output, such
+	// as empty names or label values; it is a warning and does not fail the reconcile
+	ConditionTypeTemplateWarnings = "TemplateWarnings"
+	// ConditionTypeWaitingForNamespaces indicates matching namespaces are not yet
+	// annotated as ready per config.waitForNamespaceAnnotation
+	ConditionTypeWaitingForNamespaces = "WaitingForNamespaces"
+	// ConditionTypePartiallyApplied indicates RBAC could not be applied to some matching
+	// namespaces; the config is requeued with backoff until they succeed
+	ConditionTypePartiallyApplied = "PartiallyApplied"
 
 	// ReasonReconcileSuccess indicates successful reconciliation
 	ReasonReconcileSuccess = "ReconcileSuccess"
@@ -60,6 +106,78 @@ const (
 	ReasonReconcileError = "ReconcileError"
 	// ReasonValidationError indicates validation error
 	ReasonValidationError = "ValidationError"
+	// ReasonRBACAPIUnavailable indicates the rbac.authorization.k8s.io API group is not served
+	ReasonRBACAPIUnavailable = "RBACAPIUnavailable"
+	// ReasonMergeFreezeAnnotation indicates resources were skipped due to the merge-freeze annotation
+	ReasonMergeFreezeAnnotation = "MergeFreezeAnnotation"
+	// ReasonNoFrozenResources indicates no frozen resources were encountered
+	ReasonNoFrozenResources = "NoFrozenResources"
+	// ReasonCircuitBreakerOpen indicates reconciliation is paused after repeated failures
+	ReasonCircuitBreakerOpen = "CircuitBreakerOpen"
+	// ReasonCircuitBreakerClosed indicates reconciliation is running normally
+	ReasonCircuitBreakerClosed = "CircuitBreakerClosed"
+	// ReasonHookError indicates one or more post-apply hook calls failed
+	ReasonHookError = "HookError"
+	// ReasonHookDelivered indicates all post-apply hook calls succeeded
+	ReasonHookDelivered = "HookDelivered"
+	// ReasonSuspended indicates spec.suspend is true
+	ReasonSuspended = "Suspended"
+	// ReasonNotSuspended indicates spec.suspend is false or unset
+	ReasonNotSuspended = "NotSuspended"
+	// ReasonResourcesRestored indicates drifted resources were restored to their rendered state
+	ReasonResourcesRestored = "ResourcesRestored"
+	// ReasonNoMatchingNamespaces indicates the selector matched no namespaces
+	ReasonNoMatchingNamespaces = "NoMatchingNamespaces"
+	// ReasonNamespacesMatched indicates the selector matched at least one namespace
+	ReasonNamespacesMatched = "NamespacesMatched"
+	// ReasonMatchNotRequired indicates the config opted out of requiring a match via requireMatch
+	ReasonMatchNotRequired = "MatchNotRequired"
+	// ReasonEscalationDenied indicates an RBAC write was rejected by escalation prevention
+	ReasonEscalationDenied = "EscalationDenied"
+	// ReasonNoEscalationDenied indicates all RBAC writes were accepted
+	ReasonNoEscalationDenied = "NoEscalationDenied"
+	// ReasonLintWarnings indicates rendered templates produced lint warnings
+	ReasonLintWarnings = "LintWarnings"
+	// ReasonNoLintWarnings indicates rendered templates produced no lint warnings
+	ReasonNoLintWarnings = "NoLintWarnings"
+	// ReasonNamespacesNotReady indicates matching namespaces lack the readiness annotation
+	ReasonNamespacesNotReady = "NamespacesNotReady"
+	// ReasonNamespacesReady indicates all matching namespaces carry the readiness annotation
+	ReasonNamespacesReady = "NamespacesReady"
+	// ReasonNamespaceApplyFailed indicates applying RBAC failed for one or more namespaces
+	ReasonNamespaceApplyFailed = "NamespaceApplyFailed"
+	// ReasonRetrying indicates a reconcile failed but is retried before the config is marked Degraded
+	ReasonRetrying = "Retrying"
+	// ReasonAllNamespacesApplied indicates RBAC was applied to every ready matching namespace
+	ReasonAllNamespacesApplied = "AllNamespacesApplied"
+
+	// MaxRecentErrors bounds Status.RecentErrors; older entries are pruned first
+	MaxRecentErrors = 10
+
+	// DefaultCleanupRetryInterval is the base requeue interval after a failed cleanup
+	DefaultCleanupRetryInterval = time.Minute
+	// MaxCleanupRetryInterval caps the exponential backoff between cleanup retries
+	MaxCleanupRetryInterval = 30 * time.Minute
+
+	// DefaultMaxAppliedNamespaces bounds Status.AppliedNamespaces so large clusters
+	// don't bloat the object in etcd
+	DefaultMaxAppliedNamespaces = 1000
+
+	// DefaultCircuitBreakerThreshold is the number of consecutive reconcile failures
+	// after which a config's circuit breaker opens
+	DefaultCircuitBreakerThreshold = 5
+	// DefaultCircuitBreakerInterval is how long an open circuit breaker pauses reconciliation
+	DefaultCircuitBreakerInterval = time.Hour
+
+	// NamespaceReadinessRequeueInterval is how often a config is requeued while matching
+	// namespaces wait for the readiness annotation; annotation changes also trigger a reconcile
+	NamespaceReadinessRequeueInterval = 30 * time.Second
+
+	// DefaultPartialFailureRetryInterval is the base requeue interval after RBAC failed
+	// to apply to some namespaces
+	DefaultPartialFailureRetryInterval = 5 * time.Second
+	// MaxPartialFailureRetryInterval caps the exponential backoff between partial failure retries
+	MaxPartialFailureRetryInterval = 5 * time.Minute
 
 	// FinalizerName is the finalizer used by this controller to ensure proper cleanup
 	// of cluster-scoped resources when the NamespaceRBACConfig is deleted
@@ -71,28 +189,66 @@ const (
 // RBAC templates to matching namespaces. The reconciler also handles cleanup
 // when configs are deleted.
 type NamespaceRBACConfigReconciler struct {
-	client.Client                 // Kubernetes API client
-	Scheme        *runtime.Scheme // Kubernetes scheme for object serialization
-	Log           logr.Logger     // Structured logger
-	rbacManager   *rbac.Manager   // Handles RBAC resource creation/management
-	healthChecker *health.Checker // Health monitoring
+	client.Client                        // Kubernetes API client
+	Scheme               *runtime.Scheme // Kubernetes scheme for object serialization
+	Log                  logr.Logger     // Structured logger
+	CleanupRetryInterval time.Duration   // Base requeue interval after a failed cleanup, doubled per consecutive failure
+	CircuitThreshold     int             // Consecutive reconcile failures before a config's circuit breaker opens
+	CircuitInterval      time.Duration   // How long an open circuit breaker pauses reconciliation
+	MaxAppliedNamespaces int             // Cap on Status.AppliedNamespaces entries; 0 means unbounded
+	DegradedGracePeriod  time.Duration   // How long reconcile failures must persist before Degraded is set; 0 degrades immediately
+	APIReader            client.Reader   // Uncached reader for listing namespaces on spec changes; falls back to the cached client
+	NamespaceCache       client.Reader   // Informer-backed reader for steady-state namespace lists; falls back to the client
+	rbacManager          *rbac.Manager   // Handles RBAC resource creation/management
+	healthChecker        *health.Checker // Health monitoring
+	hookClient           *http.Client    // Client for post-apply hooks
+
+	cleanupFailuresMu sync.Mutex
+	cleanupFailures   map[string]int // Consecutive cleanup failures per config
+
+	partialFailuresMu sync.Mutex
+	partialFailures   map[string]int // Consecutive reconciles with per-namespace failures per config
+
+	failingSinceMu sync.Mutex
+	failingSince   map[string]time.Time // Start of the current run of failed reconciles per config
+
+	circuitsMu sync.Mutex
+	circuits   map[string]*circuitState // Circuit breaker state per config
+}
+
+// circuitState tracks consecutive reconcile failures for a single config
+type circuitState struct {
+	failures   int       // Consecutive failed reconciles
+	openUntil  time.Time // Reconciliation is paused until this time once the breaker opens
+	generation int64     // Config generation when the breaker opened; a spec change closes it
 }
 
 // NewNamespaceRBACConfigReconciler creates a new reconciler
-func NewNamespaceRBACConfigReconciler(client client.Client, scheme *runtime.Scheme, log logr.Logger, healthChecker *health.Checker) *NamespaceRBACConfigReconciler {
+func NewNamespaceRBACConfigReconciler(client client.Client, scheme *runtime.Scheme, log logr.Logger, healthChecker *health.Checker, rbacOpts rbac.Options) *NamespaceRBACConfigReconciler {
 	return &NamespaceRBACConfigReconciler{
-		Client:        client,
-		Scheme:        scheme,
-		Log:           log,
-		rbacManager:   rbac.NewManager(client),
-		healthChecker: healthChecker,
+		Client:               client,
+		Scheme:               scheme,
+		Log:                  log,
+		CleanupRetryInterval: DefaultCleanupRetryInterval,
+		CircuitThreshold:     DefaultCircuitBreakerThreshold,
+		CircuitInterval:      DefaultCircuitBreakerInterval,
+		rbacManager:          rbac.NewManager(client, rbacOpts),
+		healthChecker:        healthChecker,
+		hookClient:           &http.Client{Timeout: DefaultHookTimeout},
+		cleanupFailures:      make(map[string]int),
+		partialFailures:      make(map[string]int),
+		failingSince:         make(map[string]time.Time),
+		circuits:             make(map[string]*circuitState),
 	}
 }
 
 // +kubebuilder:rbac:groups=rbac.operator.io,resources=namespacerbacconfigs,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=rbac.operator.io,resources=namespacerbacconfigs/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=rbac.operator.io,resources=namespacerbacconfigs/finalizers,verbs=update
-// +kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch;patch
+// +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch;create;update;patch
+// +kubebuilder:rbac:groups="",resources=serviceaccounts,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups="",resources=resourcequotas,verbs=get;list;watch
 // +kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=roles,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=clusterroles,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=rolebindings,verbs=get;list;watch;create;update;patch;delete
@@ -104,7 +260,7 @@ func NewNamespaceRBACConfigReconciler(client client.Client, scheme *runtime.Sche
 // The reconciliation flow:
 // 1. Fetch the NamespaceRBACConfig resource
 // 2. Handle deletion if the resource is being deleted
-// 3. Add finalizer if not present (for proper cleanup)
+// 3. Add finalizer if not present (for proper cleanup), unless cleanup.useFinalizer is false
 // 4. Validate the configuration
 // 5. Find all namespaces matching the selector
 // 6. Apply RBAC templates to matching namespaces
@@ -112,13 +268,15 @@ func NewNamespaceRBACConfigReconciler(client client.Client, scheme *runtime.Sche
 //
 // For more details, check Reconcile and its Result here:
 // - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.16.3/pkg/reconcile
-func (r *NamespaceRBACConfigReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+func (r *NamespaceRBACConfigReconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, err error) {
 	start := time.Now()
+	ctx, span := tracing.Start(ctx, "NamespaceRBACConfig.Reconcile", tracing.ConfigKey.String(req.Name))
+	defer span.End()
 	log := r.Log.WithValues("namespacerbacconfig", req.NamespacedName)
 
 	// Fetch the NamespaceRBACConfig instance
 	config := &rbacoperatorv1.NamespaceRBACConfig{}
-	err := r.Get(ctx, req.NamespacedName, config)
+	err = r.Get(ctx, req.NamespacedName, config)
 	if err != nil {
 		if errors.IsNotFound(err) {
 			// Request object not found, could have been deleted after reconcile request.
@@ -129,63 +287,135 @@ func (r *NamespaceRBACConfigReconciler) Reconcile(ctx context.Context, req ctrl.
 		log.Error(err, "Failed to get NamespaceRBACConfig")
 		r.healthChecker.SetHealthy(false)
 		metrics.SetOperatorHealth("reconciler", false)
-		metrics.RecordReconciliation(req.Name, "NamespaceRBACConfig", time.Since(start), err)
+		metrics.RecordReconciliation(ctx, req.Name, "NamespaceRBACConfig", time.Since(start), err)
 		return ctrl.Result{}, err
 	}
 
+	// Failures reported in status are not returned, but still count as reconcile errors
+	var statusErr error
+
 	// Record active configs count and defer final metrics recording
 	defer func() {
 		configList := &rbacoperatorv1.NamespaceRBACConfigList{}
 		if listErr := r.List(ctx, configList); listErr == nil {
 			metrics.ActiveConfigs.Set(float64(len(configList.Items)))
 		}
-		metrics.RecordReconciliation(config.Name, "NamespaceRBACConfig", time.Since(start), err)
+		recordedErr := err
+		if recordedErr == nil {
+			recordedErr = statusErr
+		}
+		metrics.RecordReconciliation(ctx, config.Name, "NamespaceRBACConfig", time.Since(start), recordedErr)
+		metrics.RecordReconcileDurationByNamespaceCount(config.Status.ManagedResourceCount, time.Since(start))
 	}()
+	// Deferred after the metrics above so they record the recovered error
+	defer r.recoverPanic(log, &err)
 
 	// Handle deletion
 	if config.DeletionTimestamp != nil {
 		return r.handleDeletion(ctx, config, log)
 	}
 
-	// Add finalizer if not present
-	if !controllerutil.ContainsFinalizer(config, FinalizerName) {
+	// Add finalizer if not present, or drop it once the config opts out of finalizer cleanup
+	switch hasFinalizer := controllerutil.ContainsFinalizer(config, FinalizerName); {
+	case useFinalizer(config) && !hasFinalizer:
 		controllerutil.AddFinalizer(config, FinalizerName)
 		if err := r.Update(ctx, config); err != nil {
 			log.Error(err, "Failed to add finalizer")
 			return ctrl.Result{}, err
 		}
 		return ctrl.Result{Requeue: true}, nil
+	case !useFinalizer(config) && hasFinalizer && ownedByConfig(config):
+		// Keep the finalizer while validation would reject the config, so nothing is orphaned
+		controllerutil.RemoveFinalizer(config, FinalizerName)
+		if err := r.Update(ctx, config); err != nil {
+			log.Error(err, "Failed to remove finalizer")
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{Requeue: true}, nil
+	}
+
+	// Suspended configs keep their existing RBAC but are not reconciled
+	if utils.BoolPtrValue(config.Spec.Suspend) {
+		log.Info("NamespaceRBACConfig is suspended, skipping reconciliation")
+		r.setCondition(config, ConditionTypeSuspended, metav1.ConditionTrue, ReasonSuspended, "Reconciliation suspended by spec.suspend")
+		r.setCondition(config, ConditionTypeProgressing, metav1.ConditionFalse, ReasonSuspended, "Reconciliation suspended")
+		return r.updateStatus(ctx, config, log)
+	}
+	r.setCondition(config, ConditionTypeSuspended, metav1.ConditionFalse, ReasonNotSuspended, "Reconciliation is not suspended")
+
+	// Track how long spec changes have been waiting to be observed
+	metrics.UpdateGenerationLag(config.Name, generationLag(config))
+
+	// Skip configs whose circuit breaker is open until the pause elapses
+	if remaining, open := r.circuitOpen(config); open {
+		log.Info("Circuit breaker open, skipping reconciliation", "retryAfter", remaining)
+		return ctrl.Result{RequeueAfter: remaining}, nil
+	}
+
+	// Record a stable hash of the spec so tools can detect changes cheaply
+	if specHash, err := utils.HashJSON(config.Spec); err != nil {
+		log.Error(err, "Failed to hash spec")
+	} else {
+		config.Status.SpecHash = specHash
 	}
 
 	// Set progressing condition
 	r.setCondition(config, ConditionTypeProgressing, metav1.ConditionTrue, "Reconciling", "Reconciling NamespaceRBACConfig")
 
 	// Validate the configuration
-	if err := r.validateConfig(config); err != nil {
-		log.Error(err, "Invalid configuration")
-		r.healthChecker.SetHealthy(false)
-		metrics.SetOperatorHealth("reconciler", false)
-		r.setCondition(config, ConditionTypeDegraded, metav1.ConditionTrue, ReasonValidationError, err.Error())
-		r.setCondition(config, ConditionTypeReady, metav1.ConditionFalse, ReasonValidationError, "Configuration validation failed")
-		r.setCondition(config, ConditionTypeProgressing, metav1.ConditionFalse, ReasonValidationError, "Validation failed")
-		return r.updateStatus(ctx, config, log)
+	if errs := r.validateConfig(config); len(errs) > 0 {
+		return r.failValidation(ctx, config, errs, log)
 	}
 
-	// Reconcile RBAC for all matching namespaces
-	appliedNamespaces, err := r.reconcileRBAC(ctx, config, log)
+	// Find matching namespaces once; duplicate-name checks and every apply render against them
+	var appliedNamespaces, failedNamespaces []string
+	matching, err := r.matchingNamespaces(ctx, config, log)
+	if err == nil {
+		// Templates of the same kind rendering to the same name would overwrite each other
+		if errs := r.rbacManager.CheckDuplicateNames(config, matching); len(errs) > 0 {
+			return r.failValidation(ctx, config, errs, log)
+		}
+
+		// Reconcile RBAC for all matching namespaces
+		appliedNamespaces, failedNamespaces, err = r.reconcileRBAC(ctx, config, matching, log)
+	}
 	if err != nil {
 		log.Error(err, "Failed to reconcile RBAC")
+		statusErr = err
+		degradedReason := ReasonReconcileError
+		if rbac.IsAPIUnavailable(err) {
+			// The wrapped error message already reads "RBAC API unavailable: ..."
+			degradedReason = ReasonRBACAPIUnavailable
+		}
+		if rbac.IsEscalationDenied(err) {
+			// The message names the rejected resource
+			degradedReason = ReasonEscalationDenied
+			r.setCondition(config, ConditionTypeEscalationDenied, metav1.ConditionTrue, ReasonEscalationDenied, err.Error())
+		}
+		if remaining, degrade := r.degradeAfterGrace(config.Name); !degrade {
+			// Possibly transient: keep retrying without flipping Degraded or health
+			r.setCondition(config, ConditionTypeReady, metav1.ConditionFalse, ReasonReconcileError, "RBAC reconciliation failed")
+			r.setCondition(config, ConditionTypeProgressing, metav1.ConditionTrue, ReasonRetrying,
+				fmt.Sprintf("Retrying; marked Degraded if failures persist for another %s: %v", remaining.Round(time.Second), err))
+			result, err = r.failReconcile(ctx, config, log)
+			if err == nil && (result.RequeueAfter == 0 || result.RequeueAfter > remaining) {
+				result.RequeueAfter = remaining
+			}
+			return result, err
+		}
 		r.healthChecker.SetHealthy(false)
 		metrics.SetOperatorHealth("reconciler", false)
-		r.setCondition(config, ConditionTypeDegraded, metav1.ConditionTrue, ReasonReconcileError, err.Error())
+		r.setCondition(config, ConditionTypeDegraded, metav1.ConditionTrue, degradedReason, err.Error())
 		r.setCondition(config, ConditionTypeReady, metav1.ConditionFalse, ReasonReconcileError, "RBAC reconciliation failed")
 		r.setCondition(config, ConditionTypeProgressing, metav1.ConditionFalse, ReasonReconcileError, "Reconciliation failed")
-		return r.updateStatus(ctx, config, log)
+		return r.failReconcile(ctx, config, log)
 	}
 
 	// Update status
-	config.Status.AppliedNamespaces = appliedNamespaces
+	config.Status.ManagedResourceCount = len(appliedNamespaces)
+	config.Status.AppliedNamespaces = boundNamespaces(appliedNamespaces, r.MaxAppliedNamespaces)
 	config.Status.ObservedGeneration = config.Generation
+	metrics.UpdateGenerationLag(config.Name, 0)
 
 	// Update managed namespaces metric
 	metrics.UpdateManagedNamespaces(config.Name, len(appliedNamespaces))
@@ -193,11 +423,112 @@ func (r *NamespaceRBACConfigReconciler) Reconcile(ctx context.Context, req ctrl.
 	// Set success conditions
 	r.healthChecker.RecordReconcile()
 	metrics.SetOperatorHealth("reconciler", true)
-	r.setCondition(config, ConditionTypeReady, metav1.ConditionTrue, ReasonReconcileSuccess, "Successfully reconciled RBAC")
+	// Judge the match by the selected namespaces, not by how many were applied: waiting
+	// or failing namespaces still matched
+	switch {
+	case len(matching) > 0:
+		r.setCondition(config, ConditionTypeNoMatchingNamespaces, metav1.ConditionFalse, ReasonNamespacesMatched,
+			fmt.Sprintf("Selector matched %d namespace(s)", len(matching)))
+		r.setCondition(config, ConditionTypeReady, metav1.ConditionTrue, ReasonReconcileSuccess, "Successfully reconciled RBAC")
+	case !requireMatch(config):
+		r.setCondition(config, ConditionTypeNoMatchingNamespaces, metav1.ConditionFalse, ReasonMatchNotRequired,
+			"Selector matched no namespaces, which requireMatch allows")
+		r.setCondition(config, ConditionTypeReady, metav1.ConditionTrue, ReasonReconcileSuccess, "Successfully reconciled RBAC")
+	default:
+		// An empty match usually means a misconfigured selector, so don't report Ready
+		log.Info("Selector matched no namespaces")
+		r.setCondition(config, ConditionTypeNoMatchingNamespaces, metav1.ConditionTrue, ReasonNoMatchingNamespaces,
+			"Selector matched no namespaces; set config.requireMatch to false if this is intended")
+		r.setCondition(config, ConditionTypeReady, metav1.ConditionFalse, ReasonNoMatchingNamespaces, "Selector matched no namespaces")
+	}
 	r.setCondition(config, ConditionTypeProgressing, metav1.ConditionFalse, ReasonReconcileSuccess, "Reconciliation completed")
-	r.setCondition(config, ConditionTypeDegraded, metav1.ConditionFalse, ReasonReconcileSuccess, "No issues detected")
+	var partialRetry time.Duration
+	if len(failedNamespaces) > 0 {
+		// Other namespaces were applied, so retry the failed ones soon rather than
+		// tripping the circuit breaker or waiting for the next event
+		partialRetry = r.nextPartialFailureRetry(config.Name)
+		message := fmt.Sprintf("Failed to apply RBAC to %d namespace(s): %s", len(failedNamespaces), strings.Join(failedNamespaces, ", "))
+		r.setCondition(config, ConditionTypePartiallyApplied, metav1.ConditionTrue, ReasonNamespaceApplyFailed, message)
+		r.setCondition(config, ConditionTypeReady, metav1.ConditionFalse, ReasonNamespaceApplyFailed, "RBAC partially applied")
+		if remaining, degrade := r.degradeAfterGrace(config.Name); degrade {
+			r.setCondition(config, ConditionTypeDegraded, metav1.ConditionTrue, ReasonNamespaceApplyFailed, message)
+		} else {
+			r.setCondition(config, ConditionTypeProgressing, metav1.ConditionTrue, ReasonRetrying,
+				fmt.Sprintf("Retrying; marked Degraded if failures persist for another %s", remaining.Round(time.Second)))
+			if remaining < partialRetry {
+				partialRetry = remaining
+			}
+		}
+		log.Info("RBAC partially applied; requeueing", "failedNamespaces", failedNamespaces, "retryAfter", partialRetry)
+	} else {
+		r.resetPartialFailures(config.Name)
+		r.resetFailingSince(config.Name)
+		r.setCondition(config, ConditionTypePartiallyApplied, metav1.ConditionFalse, ReasonAllNamespacesApplied, "RBAC applied to all ready matching namespaces")
+		r.setCondition(config, ConditionTypeDegraded, metav1.ConditionFalse, ReasonReconcileSuccess, "No issues detected")
+	}
+	r.setCondition(config, ConditionTypeEscalationDenied, metav1.ConditionFalse, ReasonNoEscalationDenied, "All RBAC writes were accepted")
+	r.resetCircuit(config.Name)
+	r.setCondition(config, ConditionTypeUnavailable, metav1.ConditionFalse, ReasonCircuitBreakerClosed, "Reconciliation is running normally")
+
+	result, err = r.updateStatus(ctx, config, log)
+	if err == nil && config.Spec.Config != nil && config.Spec.Config.ResyncInterval != nil {
+		// Per-config override of the global resync period
+		result.RequeueAfter = config.Spec.Config.ResyncInterval.Duration
+	}
+	if err == nil && meta.IsStatusConditionTrue(config.Status.Conditions, ConditionTypeWaitingForNamespaces) &&
+		(result.RequeueAfter == 0 || result.RequeueAfter > NamespaceReadinessRequeueInterval) {
+		// Poll for namespaces still waiting on the readiness annotation
+		result.RequeueAfter = NamespaceReadinessRequeueInterval
+	}
+	if err == nil && partialRetry > 0 && (result.RequeueAfter == 0 || result.RequeueAfter > partialRetry) {
+		result.RequeueAfter = partialRetry
+	}
+	return result, err
+}
 
-	return r.updateStatus(ctx, config, log)
+// requireMatch reports whether the config treats an empty match as an error; on by
+// default, since an empty match usually hides a selector mistake
+func requireMatch(config *rbacoperatorv1.NamespaceRBACConfig) bool {
+	return config.Spec.Config == nil || config.Spec.Config.RequireMatch == nil || *config.Spec.Config.RequireMatch
+}
+
+// boundNamespaces returns at most max namespaces followed, if any were left out, by a
+// "… and N more" summary entry. A max of 0 leaves the list unbounded.
+func boundNamespaces(namespaces []string, max int) []string {
+	if max <= 0 || len(namespaces) <= max {
+		return namespaces
+	}
+	bounded := make([]string, 0, max+1)
+	bounded = append(bounded, namespaces[:max]...)
+	return append(bounded, fmt.Sprintf("… and %d more", len(namespaces)-max))
+}
+
+// failValidation reports an invalid configuration in status and marks it Degraded
+func (r *NamespaceRBACConfigReconciler) failValidation(ctx context.Context, config *rbacoperatorv1.NamespaceRBACConfig, errs field.ErrorList, log logr.Logger) (ctrl.Result, error) {
+	err := errs.ToAggregate()
+	log.Error(err, "Invalid configuration", "errors", len(errs))
+	recordError(config, "", err)
+	r.healthChecker.SetHealthy(false)
+	metrics.SetOperatorHealth("reconciler", false)
+	r.setCondition(config, ConditionTypeDegraded, metav1.ConditionTrue, ReasonValidationError, validationMessage(errs))
+	r.setCondition(config, ConditionTypeReady, metav1.ConditionFalse, ReasonValidationError, "Configuration validation failed")
+	r.setCondition(config, ConditionTypeProgressing, metav1.ConditionFalse, ReasonValidationError, "Validation failed")
+	return r.failReconcile(ctx, config, log)
+}
+
+// recoverPanic converts a panic during reconciliation into an error, so one bad config
+// cannot crash the manager; the request is then requeued with backoff like any failure.
+// It must be deferred directly from Reconcile.
+func (r *NamespaceRBACConfigReconciler) recoverPanic(log logr.Logger, err *error) {
+	p := recover()
+	if p == nil {
+		return
+	}
+	*err = fmt.Errorf("recovered from panic: %v", p)
+	log.Error(*err, "Reconcile panicked", "stacktrace", string(debug.Stack()))
+	r.healthChecker.SetHealthy(false)
+	metrics.SetOperatorHealth("reconciler", false)
+	metrics.RecordPanic("NamespaceRBACConfig")
 }
 
 // handleDeletion handles the deletion of a NamespaceRBACConfig
@@ -205,11 +536,17 @@ func (r *NamespaceRBACConfigReconciler) handleDeletion(ctx context.Context, conf
 	if controllerutil.ContainsFinalizer(config, FinalizerName) {
 		log.Info("Cleaning up RBAC resources for deleted NamespaceRBACConfig")
 
-		// Clean up RBAC resources
+		// Clean up RBAC resources; the finalizer stays until this succeeds
 		if err := r.cleanupRBAC(ctx, config, log); err != nil {
-			log.Error(err, "Failed to cleanup RBAC resources")
-			return ctrl.Result{RequeueAfter: time.Minute}, err
+			retryAfter := r.nextCleanupRetry(config.Name)
+			log.Error(err, "Failed to cleanup RBAC resources", "retryAfter", retryAfter)
+			// Return no error so RequeueAfter is honored instead of the default rate limiter
+			return ctrl.Result{RequeueAfter: retryAfter}, nil
 		}
+		r.resetCleanupFailures(config.Name)
+		r.resetPartialFailures(config.Name)
+		r.resetFailingSince(config.Name)
+		r.resetCircuit(config.Name)
 
 		// Remove finalizer
 		controllerutil.RemoveFinalizer(config, FinalizerName)
@@ -222,78 +559,642 @@ func (r *NamespaceRBACConfigReconciler) handleDeletion(ctx context.Context, conf
 	return ctrl.Result{}, nil
 }
 
-// validateConfig validates the NamespaceRBACConfig
-func (r *NamespaceRBACConfigReconciler) validateConfig(config *rbacoperatorv1.NamespaceRBACConfig) error {
+// useFinalizer reports whether deletion of the config is blocked by FinalizerName until
+// its resources are cleaned up; defaults to true
+func useFinalizer(config *rbacoperatorv1.NamespaceRBACConfig) bool {
+	return config.Spec.Config == nil || config.Spec.Config.Cleanup == nil ||
+		config.Spec.Config.Cleanup.UseFinalizer == nil || *config.Spec.Config.Cleanup.UseFinalizer
+}
+
+// ownedByConfig reports whether generated resources carry an owner reference to the config
+func ownedByConfig(config *rbacoperatorv1.NamespaceRBACConfig) bool {
+	return config.Spec.Config != nil && config.Spec.Config.OwnerReferenceStrategy != nil &&
+		*config.Spec.Config.OwnerReferenceStrategy == rbacoperatorv1.OwnerReferenceConfig
+}
+
+// validateConfig validates the NamespaceRBACConfig and returns every problem found, each
+// with the JSON field path it refers to (e.g. spec.rbacTemplates.roles[0].name)
+func (r *NamespaceRBACConfigReconciler) validateConfig(config *rbacoperatorv1.NamespaceRBACConfig) field.ErrorList {
+	var errs field.ErrorList
+	specPath := field.NewPath("spec")
+
 	// Validate namespace selector
+	selectorPath := specPath.Child("namespaceSelector")
 	if config.Spec.NamespaceSelector.NameRegex != nil {
 		if _, err := regexp.Compile(*config.Spec.NamespaceSelector.NameRegex); err != nil {
-			return fmt.Errorf("invalid nameRegex: %w", err)
+			errs = append(errs, field.Invalid(selectorPath.Child("nameRegex"), *config.Spec.NamespaceSelector.NameRegex, err.Error()))
+		}
+	}
+	for i, pattern := range config.Spec.NamespaceSelector.ExcludeNameRegex {
+		if _, err := regexp.Compile(pattern); err != nil {
+			errs = append(errs, field.Invalid(selectorPath.Child("excludeNameRegex").Index(i), pattern, err.Error()))
 		}
 	}
+	for i, pattern := range config.Spec.NamespaceSelector.IncludeNamespaceGlobs {
+		if _, err := path.Match(pattern, ""); err != nil {
+			errs = append(errs, field.Invalid(selectorPath.Child("includeNamespaceGlobs").Index(i), pattern, err.Error()))
+		}
+	}
+
+	if config.Spec.Config != nil {
+		errs = append(errs, validateConfigOptions(config, specPath.Child("config"))...)
+	}
 
 	// Validate RBAC templates
-	// TODO: Add more comprehensive validation
-	if len(config.Spec.RBACTemplates.Roles) == 0 &&
-		len(config.Spec.RBACTemplates.ClusterRoles) == 0 &&
-		len(config.Spec.RBACTemplates.RoleBindings) == 0 &&
-		len(config.Spec.RBACTemplates.ClusterRoleBindings) == 0 {
-		return fmt.Errorf("at least one RBAC template must be specified")
+	templatesPath := specPath.Child("rbacTemplates")
+	templates := &config.Spec.RBACTemplates
+	if len(templates.ServiceAccounts) == 0 &&
+		len(templates.Roles) == 0 &&
+		len(templates.ClusterRoles) == 0 &&
+		len(templates.RoleBindings) == 0 &&
+		len(templates.ClusterRoleBindings) == 0 {
+		errs = append(errs, field.Required(templatesPath, "at least one RBAC template must be specified"))
 	}
 
-	return nil
+	// Validate SimpleRule access levels
+	for i, role := range templates.Roles {
+		errs = append(errs, validateSimpleRules(templatesPath.Child("roles").Index(i), role.SimpleRules)...)
+	}
+	for i, clusterRole := range templates.ClusterRoles {
+		errs = append(errs, validateSimpleRules(templatesPath.Child("clusterRoles").Index(i), clusterRole.SimpleRules)...)
+	}
+
+	// Validate binding subjects
+	for i, roleBinding := range templates.RoleBindings {
+		errs = append(errs, validateSubjects(templatesPath.Child("roleBindings").Index(i), roleBinding.Subjects)...)
+	}
+	for i, clusterRoleBinding := range templates.ClusterRoleBindings {
+		bindingPath := templatesPath.Child("clusterRoleBindings").Index(i)
+		errs = append(errs, validateSubjects(bindingPath, clusterRoleBinding.Subjects)...)
+		// A ClusterRoleBinding can only grant a ClusterRole; the API server rejects anything else
+		if clusterRoleBinding.RoleRef.Kind != rbac.KindClusterRole {
+			errs = append(errs, field.NotSupported(bindingPath.Child("roleRef", "kind"), clusterRoleBinding.RoleRef.Kind, []string{rbac.KindClusterRole}))
+		}
+	}
+
+	// Enforce size limits
+	if config.Spec.Config != nil && config.Spec.Config.Limits != nil {
+		errs = append(errs, validateLimits(config.Spec.Config.Limits, templates, specPath.Child("config", "limits"), templatesPath)...)
+	}
+
+	// Catch references to template fields that do not exist
+	errs = append(errs, r.rbacManager.ValidateTemplates(config)...)
+
+	// ClusterRole names must match their declared per-namespace or shared scope
+	errs = append(errs, r.rbacManager.ValidateClusterRoleScopes(config)...)
+
+	return errs
+}
+
+// validateConfigOptions validates spec.config
+func validateConfigOptions(config *rbacoperatorv1.NamespaceRBACConfig, fldPath *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	options := config.Spec.Config
+
+	// Validate merge strategy; templated strategies are checked per namespace at apply time
+	if options.MergeStrategy != nil {
+		strategy := *options.MergeStrategy
+		if !strings.Contains(string(strategy), "{{") && !rbac.IsKnownMergeStrategy(strategy) {
+			errs = append(errs, field.Invalid(fldPath.Child("mergeStrategy"), strategy, "must be one of merge, replace, ignore, authoritative or a template"))
+		}
+	}
+
+	// Validate naming strategy
+	if options.Naming != nil && options.Naming.Strategy != "" && !rbac.IsKnownNamingStrategy(options.Naming.Strategy) {
+		errs = append(errs, field.NotSupported(fldPath.Child("naming", "strategy"), options.Naming.Strategy,
+			[]string{string(rbacoperatorv1.NamingStrategyTemplate), string(rbacoperatorv1.NamingStrategyHashed), string(rbacoperatorv1.NamingStrategyTemplateHashed)}))
+	}
+
+	// Validate owner reference strategy
+	if options.OwnerReferenceStrategy != nil {
+		switch *options.OwnerReferenceStrategy {
+		case rbacoperatorv1.OwnerReferenceNamespace, rbacoperatorv1.OwnerReferenceConfig, rbacoperatorv1.OwnerReferenceNone:
+		default:
+			errs = append(errs, field.NotSupported(fldPath.Child("ownerReferenceStrategy"), *options.OwnerReferenceStrategy,
+				[]string{string(rbacoperatorv1.OwnerReferenceNamespace), string(rbacoperatorv1.OwnerReferenceConfig), string(rbacoperatorv1.OwnerReferenceNone)}))
+		}
+	}
+
+	// Without the finalizer only owner-reference GC removes generated resources on deletion
+	if !useFinalizer(config) && !ownedByConfig(config) {
+		errs = append(errs, field.Invalid(fldPath.Child("cleanup", "useFinalizer"), false, "false requires ownerReferenceStrategy config"))
+	}
+
+	// Validate post-apply hook URL
+	if options.Hooks != nil && options.Hooks.PostApplyURL != "" {
+		hookURL, err := url.Parse(options.Hooks.PostApplyURL)
+		if err != nil || (hookURL.Scheme != "http" && hookURL.Scheme != "https") || hookURL.Host == "" {
+			errs = append(errs, field.Invalid(fldPath.Child("hooks", "postApplyURL"), options.Hooks.PostApplyURL, "must be an absolute http(s) URL"))
+		}
+	}
+
+	// Validate apply order
+	seenKinds := make(map[string]bool)
+	for i, kind := range options.ApplyOrder {
+		switch {
+		case !rbac.IsKnownKind(kind):
+			errs = append(errs, field.NotSupported(fldPath.Child("applyOrder").Index(i), kind, rbac.DefaultApplyOrder))
+		case seenKinds[kind]:
+			errs = append(errs, field.Duplicate(fldPath.Child("applyOrder").Index(i), kind))
+		}
+		seenKinds[kind] = true
+	}
+
+	// Validate conflict retries
+	if options.MaxConflictRetries != nil && *options.MaxConflictRetries <= 0 {
+		errs = append(errs, field.Invalid(fldPath.Child("maxConflictRetries"), *options.MaxConflictRetries, "must be positive"))
+	}
+
+	// Validate resync interval
+	if options.ResyncInterval != nil && options.ResyncInterval.Duration <= 0 {
+		errs = append(errs, field.Invalid(fldPath.Child("resyncInterval"), options.ResyncInterval.Duration.String(), "must be positive"))
+	}
+
+	return errs
+}
+
+// validateSimpleRules checks that every SimpleRule names resources and a known access level
+func validateSimpleRules(fldPath *field.Path, rules []rbacoperatorv1.SimpleRule) field.ErrorList {
+	var errs field.ErrorList
+	for i, rule := range rules {
+		rulePath := fldPath.Child("simpleRules").Index(i)
+		if len(rule.Resources) == 0 {
+			errs = append(errs, field.Required(rulePath.Child("resources"), "must not be empty"))
+		}
+		if !rbac.IsKnownAccessLevel(rule.Access) {
+			errs = append(errs, field.NotSupported(rulePath.Child("access"), rule.Access,
+				[]string{string(rbacoperatorv1.AccessRead), string(rbacoperatorv1.AccessWrite), string(rbacoperatorv1.AccessAdmin)}))
+		}
+	}
+	return errs
+}
+
+// validateLimits checks that no template exceeds the configured rule or subject limits.
+// Subjects added at apply time from variables or ServiceAccount selectors are not counted.
+func validateLimits(limits *rbacoperatorv1.LimitsConfig, templates *rbacoperatorv1.RBACTemplates, limitsPath, templatesPath *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	if limits.MaxRulesPerRole != nil {
+		limit := *limits.MaxRulesPerRole
+		if limit <= 0 {
+			errs = append(errs, field.Invalid(limitsPath.Child("maxRulesPerRole"), limit, "must be positive"))
+		} else {
+			// Each SimpleRule expands to exactly one rule
+			for i, role := range templates.Roles {
+				if count := len(role.Rules) + len(role.SimpleRules); count > limit {
+					errs = append(errs, field.TooMany(templatesPath.Child("roles").Index(i).Child("rules"), count, limit))
+				}
+			}
+			for i, clusterRole := range templates.ClusterRoles {
+				if count := len(clusterRole.Rules) + len(clusterRole.SimpleRules); count > limit {
+					errs = append(errs, field.TooMany(templatesPath.Child("clusterRoles").Index(i).Child("rules"), count, limit))
+				}
+			}
+		}
+	}
+
+	if limits.MaxSubjectsPerBinding != nil {
+		limit := *limits.MaxSubjectsPerBinding
+		if limit <= 0 {
+			errs = append(errs, field.Invalid(limitsPath.Child("maxSubjectsPerBinding"), limit, "must be positive"))
+		} else {
+			for i, roleBinding := range templates.RoleBindings {
+				if len(roleBinding.Subjects) > limit {
+					errs = append(errs, field.TooMany(templatesPath.Child("roleBindings").Index(i).Child("subjects"), len(roleBinding.Subjects), limit))
+				}
+			}
+			for i, clusterRoleBinding := range templates.ClusterRoleBindings {
+				if len(clusterRoleBinding.Subjects) > limit {
+					errs = append(errs, field.TooMany(templatesPath.Child("clusterRoleBindings").Index(i).Child("subjects"), len(clusterRoleBinding.Subjects), limit))
+				}
+			}
+		}
+	}
+
+	return errs
+}
+
+// validateSubjects ensures each subject has a legal kind and that ServiceAccount
+// subjects specify a (possibly templated) namespace
+func validateSubjects(fldPath *field.Path, subjects []rbacv1.Subject) field.ErrorList {
+	var errs field.ErrorList
+	for i, subject := range subjects {
+		subjectPath := fldPath.Child("subjects").Index(i)
+		switch subject.Kind {
+		case rbacv1.UserKind, rbacv1.GroupKind:
+		case rbacv1.ServiceAccountKind:
+			if subject.Namespace == "" {
+				errs = append(errs, field.Required(subjectPath.Child("namespace"), fmt.Sprintf("ServiceAccount subject %q requires a namespace", subject.Name)))
+			}
+		default:
+			errs = append(errs, field.NotSupported(subjectPath.Child("kind"), subject.Kind,
+				[]string{rbacv1.UserKind, rbacv1.GroupKind, rbacv1.ServiceAccountKind}))
+		}
+	}
+	return errs
 }
 
-// reconcileRBAC reconciles RBAC for all matching namespaces
-func (r *NamespaceRBACConfigReconciler) reconcileRBAC(ctx context.Context, config *rbacoperatorv1.NamespaceRBACConfig, log logr.Logger) ([]string, error) {
-	// List all namespaces
+// validationMessage formats validation errors as a list with one field per line, for
+// the Degraded condition
+func validationMessage(errs field.ErrorList) string {
+	lines := make([]string, 0, len(errs)+1)
+	lines = append(lines, fmt.Sprintf("%d validation error(s):", len(errs)))
+	for _, err := range errs {
+		lines = append(lines, "- "+err.Error())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// matchingNamespaces returns the namespaces the config matches, sorted by name.
+// Namespaces whose match cannot be evaluated are logged and skipped.
+func (r *NamespaceRBACConfigReconciler) matchingNamespaces(ctx context.Context, config *rbacoperatorv1.NamespaceRBACConfig, log logr.Logger) ([]corev1.Namespace, error) {
+	// List all namespaces from the shared informer cache, which is kept current by a
+	// watch and costs no API call. For a new or changed spec, read from the API server
+	// so a namespace created just before the config is not missed by a lagging cache.
+	var reader client.Reader = r.Client
+	if r.NamespaceCache != nil {
+		reader = r.NamespaceCache
+	}
+	if r.APIReader != nil && config.Status.ObservedGeneration != config.Generation {
+		reader = r.APIReader
+	}
 	namespaceList := &corev1.NamespaceList{}
-	if err := r.List(ctx, namespaceList); err != nil {
+	if err := reader.List(ctx, namespaceList); err != nil {
+		recordError(config, "", err)
 		return nil, fmt.Errorf("failed to list namespaces: %w", err)
 	}
 
+	matching := make([]corev1.Namespace, 0)
+	for i := range namespaceList.Items {
+		matches, err := r.rbacManager.NamespaceMatches(ctx, &namespaceList.Items[i], config)
+		if err != nil {
+			log.Error(err, "Failed to check namespace match", "namespace", namespaceList.Items[i].Name)
+			continue
+		}
+		if matches {
+			matching = append(matching, namespaceList.Items[i])
+		}
+	}
+	sort.Slice(matching, func(i, j int) bool { return matching[i].Name < matching[j].Name })
+	return matching, nil
+}
+
+// namespaceNames returns the names of the namespaces, in order
+func namespaceNames(namespaces []corev1.Namespace) []string {
+	names := make([]string, 0, len(namespaces))
+	for i := range namespaces {
+		names = append(names, namespaces[i].Name)
+	}
+	return names
+}
+
+// wasManaged reports whether RBAC was applied to the namespace by an earlier reconcile
+func wasManaged(ns *corev1.Namespace, config *rbacoperatorv1.NamespaceRBACConfig) bool {
+	return rbac.ManagedBy(ns, config.Name) || utils.SliceContains(config.Status.AppliedNamespaces, ns.Name)
+}
+
+// reconcileRBAC reconciles RBAC for the matching namespaces. It returns the namespaces
+// RBAC is applied to and those where applying failed; a failure in one namespace does
+// not stop the others, except for errors that would fail every namespace alike. When
+// every ready namespace fails, nothing was applied and an error is returned instead.
+func (r *NamespaceRBACConfigReconciler) reconcileRBAC(ctx context.Context, config *rbacoperatorv1.NamespaceRBACConfig, matching []corev1.Namespace, log logr.Logger) ([]string, []string, error) {
+	ctx, span := tracing.Start(ctx, "NamespaceRBACConfig.reconcileRBAC", tracing.ConfigKey.String(config.Name))
+	defer span.End()
+
+	matchingNames := namespaceNames(matching)
 	appliedNamespaces := make([]string, 0)
+	failedNamespaces := make([]string, 0)
+	frozenResources := make([]string, 0)
+	driftCorrected := make([]string, 0)
+	lintWarnings := make([]string, 0)
+	renderedResources := make(map[string][]client.Object)
+	hookFailures := make([]string, 0)
+	waitingNamespaces := make([]string, 0)
 
-	// Process each namespace
-	for _, ns := range namespaceList.Items {
-		// Check if namespace matches selector
-		matches, err := utils.NamespaceMatches(&ns, config.Spec.NamespaceSelector)
+	// Process each matching namespace
+	for _, ns := range matching {
+		// Matching namespaces that are not ready yet are neither applied nor cleaned up
+		if !rbac.NamespaceReady(&ns, config) {
+			log.V(1).Info("Waiting for namespace readiness annotation", "namespace", ns.Name,
+				"annotation", config.Spec.Config.WaitForNamespaceAnnotation)
+			waitingNamespaces = append(waitingNamespaces, ns.Name)
+			// Keep counting namespaces applied earlier, including any summarised out of the status list
+			if wasManaged(&ns, config) {
+				appliedNamespaces = append(appliedNamespaces, ns.Name)
+			}
+			continue
+		}
+
+		log.Info("Applying RBAC to namespace", "namespace", ns.Name)
+		result, err := r.rbacManager.ApplyRBACForNamespace(ctx, &ns, config, matchingNames)
 		if err != nil {
-			log.Error(err, "Failed to check namespace match", "namespace", ns.Name)
+			recordError(config, ns.Name, err)
+			if rbac.IsAPIUnavailable(err) || rbac.IsEscalationDenied(err) {
+				return nil, nil, fmt.Errorf("failed to apply RBAC for namespace %s: %w", ns.Name, err)
+			}
+			log.Error(err, "Failed to apply RBAC to namespace", "namespace", ns.Name)
+			failedNamespaces = append(failedNamespaces, ns.Name)
+			// Keep counting namespaces applied earlier, including any summarised out of the status list
+			if wasManaged(&ns, config) {
+				appliedNamespaces = append(appliedNamespaces, ns.Name)
+			}
 			continue
 		}
+		appliedNamespaces = append(appliedNamespaces, ns.Name)
+		frozenResources = append(frozenResources, result.FrozenResources...)
+		driftCorrected = append(driftCorrected, result.DriftCorrected...)
+		lintWarnings = append(lintWarnings, result.LintWarnings...)
+		renderedResources[ns.Name] = result.Resources
 
-		if matches {
-			log.Info("Applying RBAC to namespace", "namespace", ns.Name)
-			if err := r.rbacManager.ApplyRBACForNamespace(ctx, &ns, config); err != nil {
-				return nil, fmt.Errorf("failed to apply RBAC for namespace %s: %w", ns.Name, err)
+		// Notify the post-apply hook; failures are reported but do not fail the reconcile
+		if config.Spec.Config != nil && config.Spec.Config.Hooks != nil && config.Spec.Config.Hooks.PostApplyURL != "" {
+			if err := r.callPostApplyHook(ctx, config, ns.Name, result.Resources); err != nil {
+				log.Error(err, "Failed to call post-apply hook", "namespace", ns.Name)
+				recordError(config, ns.Name, err)
+				hookFailures = append(hookFailures, ns.Name)
 			}
-			appliedNamespaces = append(appliedNamespaces, ns.Name)
 		}
 	}
 
-	log.Info("Successfully reconciled RBAC", "appliedNamespaces", appliedNamespaces)
-	return appliedNamespaces, nil
+	if len(waitingNamespaces) > 0 {
+		r.setCondition(config, ConditionTypeWaitingForNamespaces, metav1.ConditionTrue, ReasonNamespacesNotReady,
+			fmt.Sprintf("Waiting for annotation %s=true on %d namespace(s): %s", config.Spec.Config.WaitForNamespaceAnnotation,
+				len(waitingNamespaces), strings.Join(waitingNamespaces, ", ")))
+	} else if config.Spec.Config != nil && config.Spec.Config.WaitForNamespaceAnnotation != "" {
+		r.setCondition(config, ConditionTypeWaitingForNamespaces, metav1.ConditionFalse, ReasonNamespacesReady, "All matching namespaces are ready")
+	} else {
+		// Readiness gating was turned off, so a condition left from it no longer applies
+		meta.RemoveStatusCondition(&config.Status.Conditions, ConditionTypeWaitingForNamespaces)
+	}
+
+	if len(hookFailures) > 0 {
+		r.setCondition(config, ConditionTypeHookFailed, metav1.ConditionTrue, ReasonHookError,
+			fmt.Sprintf("Post-apply hook failed for %d namespace(s): %s", len(hookFailures), strings.Join(hookFailures, ", ")))
+	} else if config.Spec.Config != nil && config.Spec.Config.Hooks != nil && config.Spec.Config.Hooks.PostApplyURL != "" {
+		r.setCondition(config, ConditionTypeHookFailed, metav1.ConditionFalse, ReasonHookDelivered, "Post-apply hook delivered")
+	}
+
+	// Export is audit output only, so failures are reported but do not fail the reconcile
+	if config.Spec.Config != nil && config.Spec.Config.ExportTo != nil {
+		if err := r.exportRenderedRBAC(ctx, config, renderedResources); err != nil {
+			log.Error(err, "Failed to export rendered RBAC", "configMap", config.Spec.Config.ExportTo)
+			recordError(config, "", err)
+		}
+	}
+
+	if len(frozenResources) > 0 {
+		log.Info("Skipped frozen resources", "resources", frozenResources)
+		r.setCondition(config, ConditionTypeMergeFrozen, metav1.ConditionTrue, ReasonMergeFreezeAnnotation,
+			fmt.Sprintf("Skipped %d frozen resource(s): %s", len(frozenResources), strings.Join(frozenResources, ", ")))
+	} else {
+		r.setCondition(config, ConditionTypeMergeFrozen, metav1.ConditionFalse, ReasonNoFrozenResources, "No frozen resources encountered")
+	}
+
+	if len(lintWarnings) > 0 {
+		log.Info("Templates rendered suspicious output", "warnings", lintWarnings)
+		r.setCondition(config, ConditionTypeTemplateWarnings, metav1.ConditionTrue, ReasonLintWarnings,
+			fmt.Sprintf("%d template warning(s): %s", len(lintWarnings), strings.Join(lintWarnings, "; ")))
+	} else {
+		r.setCondition(config, ConditionTypeTemplateWarnings, metav1.ConditionFalse, ReasonNoLintWarnings, "No template warnings")
+	}
+
+	// The condition is only touched when drift was corrected, so it keeps the last occurrence
+	if len(driftCorrected) > 0 {
+		log.Info("Corrected drifted resources", "resources", driftCorrected)
+		r.setCondition(config, ConditionTypeDriftCorrected, metav1.ConditionTrue, ReasonResourcesRestored,
+			fmt.Sprintf("Restored %d resource(s) at %s: %s", len(driftCorrected), time.Now().UTC().Format(time.RFC3339), strings.Join(driftCorrected, ", ")))
+	}
+
+	// With nothing applied this is a failed reconcile, not a partial one
+	if ready := len(matching) - len(waitingNamespaces); len(failedNamespaces) > 0 && len(failedNamespaces) == ready {
+		return nil, nil, fmt.Errorf("failed to apply RBAC to all %d ready matching namespace(s): %s",
+			ready, strings.Join(failedNamespaces, ", "))
+	}
+
+	log.Info("Reconciled RBAC", "appliedNamespaces", appliedNamespaces, "failedNamespaces", failedNamespaces)
+	return appliedNamespaces, failedNamespaces, nil
 }
 
-// cleanupRBAC cleans up RBAC resources created by this config
-func (r *NamespaceRBACConfigReconciler) cleanupRBAC(ctx context.Context, config *rbacoperatorv1.NamespaceRBACConfig, log logr.Logger) error {
-	// For each namespace that was managed by this config
-	for _, namespaceName := range config.Status.AppliedNamespaces {
-		log.Info("Cleaning up RBAC for namespace", "namespace", namespaceName)
-		if err := r.rbacManager.CleanupRBACForNamespace(ctx, namespaceName, config); err != nil {
-			log.Error(err, "Failed to cleanup RBAC for namespace", "namespace", namespaceName)
-			// Continue with other namespaces even if one fails
+// exportRenderedRBAC writes the rendered RBAC resources as YAML into the ConfigMap
+// referenced by Config.ExportTo, using one key per namespace
+func (r *NamespaceRBACConfigReconciler) exportRenderedRBAC(ctx context.Context, config *rbacoperatorv1.NamespaceRBACConfig, rendered map[string][]client.Object) error {
+	ref := config.Spec.Config.ExportTo
+
+	data := make(map[string]string, len(rendered))
+	for namespace, objects := range rendered {
+		var buf strings.Builder
+		for _, obj := range objects {
+			gvk, err := apiutil.GVKForObject(obj, r.Scheme)
+			if err != nil {
+				return fmt.Errorf("failed to resolve kind for %s: %w", obj.GetName(), err)
+			}
+			obj.GetObjectKind().SetGroupVersionKind(gvk)
+
+			out, err := yaml.Marshal(obj)
+			if err != nil {
+				return fmt.Errorf("failed to marshal %s %s: %w", gvk.Kind, obj.GetName(), err)
+			}
+			buf.WriteString("---\n")
+			buf.Write(out)
 		}
+		data[namespace+".yaml"] = buf.String()
 	}
 
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      ref.Name,
+			Namespace: ref.Namespace,
+		},
+	}
+	_, err := controllerutil.CreateOrUpdate(ctx, r.Client, configMap, func() error {
+		if configMap.Labels == nil {
+			configMap.Labels = make(map[string]string)
+		}
+		configMap.Labels[rbac.OwnerLabel] = "namespace-rbac-operator"
+		configMap.Labels[rbac.ConfigLabel] = config.Name
+		configMap.Data = data
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write ConfigMap %s/%s: %w", ref.Namespace, ref.Name, err)
+	}
 	return nil
 }
 
-// setCondition sets a condition on the NamespaceRBACConfig status
+// cleanupRBAC cleans up RBAC resources created by this config. They are found by label,
+// so namespaces summarised out of Status.AppliedNamespaces are cleaned up too.
+func (r *NamespaceRBACConfigReconciler) cleanupRBAC(ctx context.Context, config *rbacoperatorv1.NamespaceRBACConfig, log logr.Logger) error {
+	log.Info("Cleaning up RBAC for config", "managedNamespaces", config.Status.ManagedResourceCount)
+	return r.rbacManager.CleanupRBACForConfig(ctx, config)
+}
+
+// nextCleanupRetry records a cleanup failure for the config and returns how long to wait
+// before retrying: CleanupRetryInterval doubled per consecutive failure, capped at
+// MaxCleanupRetryInterval
+func (r *NamespaceRBACConfigReconciler) nextCleanupRetry(configName string) time.Duration {
+	r.cleanupFailuresMu.Lock()
+	defer r.cleanupFailuresMu.Unlock()
+
+	r.cleanupFailures[configName]++
+	interval := r.CleanupRetryInterval
+	for i := 1; i < r.cleanupFailures[configName] && interval < MaxCleanupRetryInterval; i++ {
+		interval *= 2
+	}
+	if interval > MaxCleanupRetryInterval {
+		interval = MaxCleanupRetryInterval
+	}
+	return interval
+}
+
+// resetCleanupFailures clears the failure count once cleanup succeeds
+func (r *NamespaceRBACConfigReconciler) resetCleanupFailures(configName string) {
+	r.cleanupFailuresMu.Lock()
+	defer r.cleanupFailuresMu.Unlock()
+	delete(r.cleanupFailures, configName)
+}
+
+// nextPartialFailureRetry records a reconcile with per-namespace failures and returns how
+// long to wait before retrying: DefaultPartialFailureRetryInterval doubled per consecutive
+// partial failure, capped at MaxPartialFailureRetryInterval
+func (r *NamespaceRBACConfigReconciler) nextPartialFailureRetry(configName string) time.Duration {
+	r.partialFailuresMu.Lock()
+	defer r.partialFailuresMu.Unlock()
+
+	r.partialFailures[configName]++
+	interval := DefaultPartialFailureRetryInterval
+	for i := 1; i < r.partialFailures[configName] && interval < MaxPartialFailureRetryInterval; i++ {
+		interval *= 2
+	}
+	if interval > MaxPartialFailureRetryInterval {
+		interval = MaxPartialFailureRetryInterval
+	}
+	return interval
+}
+
+// resetPartialFailures clears the partial failure count once every namespace applies
+func (r *NamespaceRBACConfigReconciler) resetPartialFailures(configName string) {
+	r.partialFailuresMu.Lock()
+	defer r.partialFailuresMu.Unlock()
+	delete(r.partialFailures, configName)
+}
+
+// degradeAfterGrace records a failed reconcile for the config and reports whether failures
+// have persisted for DegradedGracePeriod, so the config should be marked Degraded. While
+// they have not, it also returns how much of the grace period is left.
+func (r *NamespaceRBACConfigReconciler) degradeAfterGrace(configName string) (time.Duration, bool) {
+	r.failingSinceMu.Lock()
+	defer r.failingSinceMu.Unlock()
+
+	since, ok := r.failingSince[configName]
+	if !ok {
+		since = time.Now()
+		r.failingSince[configName] = since
+	}
+	remaining := r.DegradedGracePeriod - time.Since(since)
+	if remaining <= 0 {
+		return 0, true
+	}
+	return remaining, false
+}
+
+// resetFailingSince ends the config's run of failed reconciles once one succeeds
+func (r *NamespaceRBACConfigReconciler) resetFailingSince(configName string) {
+	r.failingSinceMu.Lock()
+	defer r.failingSinceMu.Unlock()
+	delete(r.failingSince, configName)
+}
+
+// generationLag returns how long the config's spec has been ahead of its observed
+// generation, measured from the latest condition transition (or creation if the
+// config has no conditions yet). Returns 0 when the config is caught up.
+func generationLag(config *rbacoperatorv1.NamespaceRBACConfig) time.Duration {
+	if config.Status.ObservedGeneration >= config.Generation {
+		return 0
+	}
+
+	since := config.CreationTimestamp.Time
+	for _, condition := range config.Status.Conditions {
+		if condition.LastTransitionTime.After(since) {
+			since = condition.LastTransitionTime.Time
+		}
+	}
+	return time.Since(since)
+}
+
+// failReconcile records a failed reconcile against the config's circuit breaker and
+// writes status. Once the breaker opens, the Unavailable condition is set and the
+// config is requeued after CircuitInterval.
+func (r *NamespaceRBACConfigReconciler) failReconcile(ctx context.Context, config *rbacoperatorv1.NamespaceRBACConfig, log logr.Logger) (ctrl.Result, error) {
+	failures, opened := r.recordCircuitFailure(config)
+	if !opened {
+		return r.updateStatus(ctx, config, log)
+	}
+
+	log.Info("Circuit breaker opened after consecutive failures", "failures", failures, "retryAfter", r.CircuitInterval)
+	r.setCondition(config, ConditionTypeUnavailable, metav1.ConditionTrue, ReasonCircuitBreakerOpen,
+		fmt.Sprintf("Reconciliation paused for %s after %d consecutive failures", r.CircuitInterval, failures))
+	result, err := r.updateStatus(ctx, config, log)
+	if err == nil {
+		result.RequeueAfter = r.CircuitInterval
+	}
+	return result, err
+}
+
+// recordCircuitFailure counts a failed reconcile for the config and reports whether
+// its circuit breaker is now open
+func (r *NamespaceRBACConfigReconciler) recordCircuitFailure(config *rbacoperatorv1.NamespaceRBACConfig) (int, bool) {
+	r.circuitsMu.Lock()
+	defer r.circuitsMu.Unlock()
+
+	state, ok := r.circuits[config.Name]
+	if !ok {
+		state = &circuitState{}
+		r.circuits[config.Name] = state
+	}
+	state.failures++
+	if r.CircuitThreshold <= 0 || state.failures < r.CircuitThreshold {
+		return state.failures, false
+	}
+	state.openUntil = time.Now().Add(r.CircuitInterval)
+	state.generation = config.Generation
+	return state.failures, true
+}
+
+// circuitOpen returns how long reconciliation of the config stays paused. A spec
+// change (new generation) closes the breaker early so fixes are applied promptly.
+func (r *NamespaceRBACConfigReconciler) circuitOpen(config *rbacoperatorv1.NamespaceRBACConfig) (time.Duration, bool) {
+	r.circuitsMu.Lock()
+	defer r.circuitsMu.Unlock()
+
+	state, ok := r.circuits[config.Name]
+	if !ok || state.openUntil.IsZero() {
+		return 0, false
+	}
+	if state.generation != config.Generation {
+		state.openUntil = time.Time{}
+		return 0, false
+	}
+	remaining := time.Until(state.openUntil)
+	if remaining <= 0 {
+		// Half-open: allow one attempt; a further failure reopens the breaker
+		return 0, false
+	}
+	return remaining, true
+}
+
+// resetCircuit closes the circuit breaker for the config
+func (r *NamespaceRBACConfigReconciler) resetCircuit(configName string) {
+	r.circuitsMu.Lock()
+	defer r.circuitsMu.Unlock()
+	delete(r.circuits, configName)
+}
+
+// setCondition sets a condition on the NamespaceRBACConfig status, stamped with the
+// generation it was computed for
 func (r *NamespaceRBACConfigReconciler) setCondition(config *rbacoperatorv1.NamespaceRBACConfig, conditionType string, status metav1.ConditionStatus, reason, message string) {
 	condition := metav1.Condition{
 		Type:               conditionType,
 		Status:             status,
+		ObservedGeneration: config.Generation,
 		LastTransitionTime: metav1.NewTime(time.Now()),
 		Reason:             reason,
 		Message:            message,
@@ -317,8 +1218,48 @@ func (r *NamespaceRBACConfigReconciler) setCondition(config *rbacoperatorv1.Name
 	config.Status.Conditions = append(config.Status.Conditions, condition)
 }
 
-// updateStatus updates the status of the NamespaceRBACConfig
+// IsConditionTrueForGeneration reports whether the condition is True and was computed
+// for the config's current generation. A True condition left over from an older spec
+// is not current and reports false.
+func IsConditionTrueForGeneration(config *rbacoperatorv1.NamespaceRBACConfig, conditionType string) bool {
+	condition := meta.FindStatusCondition(config.Status.Conditions, conditionType)
+	return condition != nil && condition.Status == metav1.ConditionTrue && condition.ObservedGeneration == config.Generation
+}
+
+// recordError appends a failure to Status.RecentErrors, pruning the oldest
+// entries so that at most MaxRecentErrors are kept (newest last)
+func recordError(config *rbacoperatorv1.NamespaceRBACConfig, namespace string, err error) {
+	config.Status.RecentErrors = append(config.Status.RecentErrors, rbacoperatorv1.ErrorRecord{
+		Timestamp: metav1.NewTime(time.Now()),
+		Namespace: namespace,
+		Message:   err.Error(),
+	})
+	if excess := len(config.Status.RecentErrors) - MaxRecentErrors; excess > 0 {
+		config.Status.RecentErrors = config.Status.RecentErrors[excess:]
+	}
+}
+
+// updateStatus updates the status of the NamespaceRBACConfig. The write is skipped when
+// the cached status already matches, so no-op reconciles such as periodic resyncs don't
+// churn the resourceVersion
 func (r *NamespaceRBACConfigReconciler) updateStatus(ctx context.Context, config *rbacoperatorv1.NamespaceRBACConfig, log logr.Logger) (ctrl.Result, error) {
+	current := &rbacoperatorv1.NamespaceRBACConfig{}
+	if err := r.Get(ctx, client.ObjectKeyFromObject(config), current); err == nil {
+		// Progressing turns True and back within every reconcile; a condition whose
+		// persisted status is unchanged keeps its persisted transition time
+		conditions := make([]metav1.Condition, len(config.Status.Conditions))
+		for i, condition := range config.Status.Conditions {
+			if persisted := meta.FindStatusCondition(current.Status.Conditions, condition.Type); persisted != nil && persisted.Status == condition.Status {
+				condition.LastTransitionTime = persisted.LastTransitionTime
+			}
+			conditions[i] = condition
+		}
+		config.Status.Conditions = conditions
+		if equality.Semantic.DeepEqual(current.Status, config.Status) {
+			return ctrl.Result{}, nil
+		}
+	}
+
 	if err := r.Status().Update(ctx, config); err != nil {
 		if errors.IsNotFound(err) {
 			log.Info("NamespaceRBACConfig was deleted during reconciliation, skipping status update")
@@ -338,9 +1279,103 @@ func (r *NamespaceRBACConfigReconciler) SetupWithManager(mgr ctrl.Manager) error
 			&corev1.Namespace{},
 			handler.EnqueueRequestsFromMapFunc(r.mapNamespaceToConfigs),
 		).
+		// A quota appearing or disappearing can change hasResourceQuota matches
+		Watches(&corev1.ResourceQuota{}, handler.EnqueueRequestsFromMapFunc(r.mapResourceQuotaToConfigs), builder.WithPredicates(createDeletePredicate)).
+		// Recreate owned RBAC resources as soon as they are deleted by hand
+		Watches(&corev1.ServiceAccount{}, handler.EnqueueRequestsFromMapFunc(mapOwnedResourceToConfig), builder.WithPredicates(deletePredicate)).
+		Watches(&rbacv1.Role{}, handler.EnqueueRequestsFromMapFunc(mapOwnedResourceToConfig), builder.WithPredicates(deletePredicate)).
+		Watches(&rbacv1.ClusterRole{}, handler.EnqueueRequestsFromMapFunc(r.mapClusterRoleToConfigs), builder.WithPredicates(deletePredicate)).
+		Watches(&rbacv1.RoleBinding{}, handler.EnqueueRequestsFromMapFunc(mapOwnedResourceToConfig), builder.WithPredicates(deletePredicate)).
+		Watches(&rbacv1.ClusterRoleBinding{}, handler.EnqueueRequestsFromMapFunc(mapOwnedResourceToConfig), builder.WithPredicates(deletePredicate)).
 		Complete(r)
 }
 
+// deletePredicate only passes delete events
+var deletePredicate = predicate.Funcs{
+	CreateFunc:  func(event.CreateEvent) bool { return false },
+	UpdateFunc:  func(event.UpdateEvent) bool { return false },
+	DeleteFunc:  func(event.DeleteEvent) bool { return true },
+	GenericFunc: func(event.GenericEvent) bool { return false },
+}
+
+// createDeletePredicate only passes create and delete events
+var createDeletePredicate = predicate.Funcs{
+	CreateFunc:  func(event.CreateEvent) bool { return true },
+	UpdateFunc:  func(event.UpdateEvent) bool { return false },
+	DeleteFunc:  func(event.DeleteEvent) bool { return true },
+	GenericFunc: func(event.GenericEvent) bool { return false },
+}
+
+// mapResourceQuotaToConfigs maps a ResourceQuota to every NamespaceRBACConfig whose
+// selector sets hasResourceQuota, since only those depend on quota presence
+func (r *NamespaceRBACConfigReconciler) mapResourceQuotaToConfigs(ctx context.Context, obj client.Object) []reconcile.Request {
+	configList := &rbacoperatorv1.NamespaceRBACConfigList{}
+	if err := r.List(ctx, configList); err != nil {
+		r.Log.Error(err, "Failed to list NamespaceRBACConfigs", "resourceQuota", client.ObjectKeyFromObject(obj))
+		return nil
+	}
+
+	requests := make([]reconcile.Request, 0)
+	for _, config := range configList.Items {
+		if config.Spec.NamespaceSelector.HasResourceQuota != nil {
+			requests = append(requests, reconcile.Request{
+				NamespacedName: client.ObjectKey{Name: config.Name},
+			})
+		}
+	}
+	return requests
+}
+
+// mapOwnedResourceToConfig maps an operator-owned RBAC resource to the
+// NamespaceRBACConfig named in its config label
+func mapOwnedResourceToConfig(ctx context.Context, obj client.Object) []reconcile.Request {
+	labels := obj.GetLabels()
+	if labels[rbac.OwnerLabel] != "namespace-rbac-operator" || labels[rbac.ConfigLabel] == "" {
+		return nil
+	}
+
+	return []reconcile.Request{{
+		NamespacedName: client.ObjectKey{Name: labels[rbac.ConfigLabel]},
+	}}
+}
+
+// mapClusterRoleToConfigs maps an operator-owned ClusterRole to every NamespaceRBACConfig
+// that renders its name. Shared ClusterRoles are labeled with only one config, so the
+// label alone would leave the other producers' bindings dangling until their next event
+func (r *NamespaceRBACConfigReconciler) mapClusterRoleToConfigs(ctx context.Context, obj client.Object) []reconcile.Request {
+	requests := mapOwnedResourceToConfig(ctx, obj)
+	if requests == nil {
+		return nil
+	}
+
+	log := r.Log.WithValues("clusterRole", obj.GetName())
+
+	configList := &rbacoperatorv1.NamespaceRBACConfigList{}
+	if err := r.List(ctx, configList); err != nil {
+		log.Error(err, "Failed to list NamespaceRBACConfigs")
+		return requests
+	}
+
+	for i := range configList.Items {
+		config := &configList.Items[i]
+		if config.Name == requests[0].Name {
+			continue
+		}
+		produces, err := r.rbacManager.ProducesClusterRole(ctx, config, obj.GetName())
+		if err != nil {
+			log.Error(err, "Failed to check ClusterRole producer", "config", config.Name)
+			continue
+		}
+		if produces {
+			requests = append(requests, reconcile.Request{
+				NamespacedName: client.ObjectKey{Name: config.Name},
+			})
+		}
+	}
+
+	return requests
+}
+
 // mapNamespaceToConfigs maps namespace events to NamespaceRBACConfig reconcile requests
 func (r *NamespaceRBACConfigReconciler) mapNamespaceToConfigs(ctx context.Context, obj client.Object) []reconcile.Request {
 	namespace, ok := obj.(*corev1.Namespace)
@@ -361,7 +1396,7 @@ func (r *NamespaceRBACConfigReconciler) mapNamespaceToConfigs(ctx context.Contex
 
 	// Check which configs should be reconciled for this namespace
 	for _, config := range configList.Items {
-		matches, err := utils.NamespaceMatches(namespace, config.Spec.NamespaceSelector)
+		matches, err := r.rbacManager.NamespaceMatches(ctx, namespace, &config)
 		if err != nil {
 			log.Error(err, "Failed to check namespace match", "config", config.Name)
 			continue
@@ -0,0 +1,2225 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package namespacerbacconfig
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/util/workqueue"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	rbacoperatorv1 "github.com/cropalato/k8s-acl-operator/pkg/apis/rbac/v1"
+	"github.com/cropalato/k8s-acl-operator/pkg/health"
+	"github.com/cropalato/k8s-acl-operator/pkg/metrics"
+	"github.com/cropalato/k8s-acl-operator/pkg/rbac"
+	"github.com/cropalato/k8s-acl-operator/pkg/utils"
+)
+
+// newTestReconciler returns a reconciler backed by a fake client holding objs
+func newTestReconciler(t *testing.T, opts rbac.Options, funcs interceptor.Funcs, objs ...client.Object) (*NamespaceRBACConfigReconciler, client.Client) {
+	t.Helper()
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := rbacoperatorv1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	c := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(objs...).
+		WithStatusSubresource(&rbacoperatorv1.NamespaceRBACConfig{}).
+		WithInterceptorFuncs(funcs).
+		Build()
+	return NewNamespaceRBACConfigReconciler(c, scheme, logr.Discard(), health.NewChecker(logr.Discard()), opts), c
+}
+
+// reconcileConfig reconciles the config until it stops asking for an immediate requeue
+// and returns it as stored afterwards
+func reconcileConfig(t *testing.T, r *NamespaceRBACConfigReconciler, name string) *rbacoperatorv1.NamespaceRBACConfig {
+	t.Helper()
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: name}}
+	for i := 0; i < 5; i++ {
+		result, err := r.Reconcile(context.Background(), req)
+		if err != nil {
+			t.Fatalf("Reconcile: %v", err)
+		}
+		if !result.Requeue {
+			break
+		}
+	}
+	config := &rbacoperatorv1.NamespaceRBACConfig{}
+	if err := r.Get(context.Background(), req.NamespacedName, config); err != nil && !errors.IsNotFound(err) {
+		t.Fatal(err)
+	}
+	return config
+}
+
+// testNamespace returns a namespace with the given name and labels
+func testNamespace(name string, labels map[string]string) *corev1.Namespace {
+	return &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels}}
+}
+
+// testConfig returns a config granting group team-a a Role in namespaces labelled team=a
+func testConfig(name string) *rbacoperatorv1.NamespaceRBACConfig {
+	return &rbacoperatorv1.NamespaceRBACConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Generation: 1},
+		Spec: rbacoperatorv1.NamespaceRBACConfigSpec{
+			NamespaceSelector: rbacoperatorv1.NamespaceSelector{Labels: map[string]string{"team": "a"}},
+			RBACTemplates: rbacoperatorv1.RBACTemplates{
+				Roles: []rbacoperatorv1.RoleTemplate{{
+					Name:  "viewer",
+					Rules: []rbacv1.PolicyRule{{APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: []string{"get"}}},
+				}},
+				RoleBindings: []rbacoperatorv1.RoleBindingTemplate{{
+					Name:     "viewer",
+					RoleRef:  rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "Role", Name: "viewer"},
+					Subjects: []rbacv1.Subject{{Kind: rbacv1.GroupKind, APIGroup: rbacv1.GroupName, Name: "team-a"}},
+				}},
+			},
+		},
+	}
+}
+
+func TestBoundNamespaces(t *testing.T) {
+	tests := []struct {
+		name       string
+		namespaces []string
+		max        int
+		want       []string
+	}{
+		{name: "unbounded", namespaces: []string{"a", "b", "c"}, max: 0, want: []string{"a", "b", "c"}},
+		{name: "at the limit", namespaces: []string{"a", "b"}, max: 2, want: []string{"a", "b"}},
+		{name: "beyond the limit", namespaces: []string{"a", "b", "c", "d"}, max: 2, want: []string{"a", "b", "… and 2 more"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := boundNamespaces(tt.namespaces, tt.max); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("boundNamespaces() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReconcileTruncatesAppliedNamespaces(t *testing.T) {
+	objs := []client.Object{testConfig("cfg")}
+	for i := 0; i < 4; i++ {
+		objs = append(objs, testNamespace(fmt.Sprintf("team-%d", i), map[string]string{"team": "a"}))
+	}
+	r, _ := newTestReconciler(t, rbac.Options{}, interceptor.Funcs{}, objs...)
+	r.MaxAppliedNamespaces = 2
+
+	config := reconcileConfig(t, r, "cfg")
+
+	if want := []string{"team-0", "team-1", "… and 2 more"}; !reflect.DeepEqual(config.Status.AppliedNamespaces, want) {
+		t.Errorf("AppliedNamespaces = %v, want %v", config.Status.AppliedNamespaces, want)
+	}
+	if config.Status.ManagedResourceCount != 4 {
+		t.Errorf("ManagedResourceCount = %d, want 4", config.Status.ManagedResourceCount)
+	}
+}
+
+func TestDeletionCleansUpNamespacesBeyondLimit(t *testing.T) {
+	objs := []client.Object{testConfig("cfg")}
+	for i := 0; i < 4; i++ {
+		objs = append(objs, testNamespace(fmt.Sprintf("team-%d", i), map[string]string{"team": "a"}))
+	}
+	r, c := newTestReconciler(t, rbac.Options{}, interceptor.Funcs{}, objs...)
+	r.MaxAppliedNamespaces = 1
+	reconcileConfig(t, r, "cfg")
+
+	// Stop matching every namespace, so cleanup cannot rely on the selector either
+	for i := 0; i < 4; i++ {
+		ns := &corev1.Namespace{}
+		if err := c.Get(context.Background(), types.NamespacedName{Name: fmt.Sprintf("team-%d", i)}, ns); err != nil {
+			t.Fatal(err)
+		}
+		ns.Labels = nil
+		if err := c.Update(context.Background(), ns); err != nil {
+			t.Fatal(err)
+		}
+	}
+	config := &rbacoperatorv1.NamespaceRBACConfig{}
+	if err := c.Get(context.Background(), types.NamespacedName{Name: "cfg"}, config); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Delete(context.Background(), config); err != nil {
+		t.Fatal(err)
+	}
+	reconcileConfig(t, r, "cfg")
+
+	roles := &rbacv1.RoleList{}
+	if err := c.List(context.Background(), roles); err != nil {
+		t.Fatal(err)
+	}
+	if len(roles.Items) != 0 {
+		t.Errorf("%d Roles left after deleting the config, want 0", len(roles.Items))
+	}
+	bindings := &rbacv1.RoleBindingList{}
+	if err := c.List(context.Background(), bindings); err != nil {
+		t.Fatal(err)
+	}
+	if len(bindings.Items) != 0 {
+		t.Errorf("%d RoleBindings left after deleting the config, want 0", len(bindings.Items))
+	}
+}
+
+func TestReconcileEmptyMatch(t *testing.T) {
+	tests := []struct {
+		name         string
+		requireMatch *bool
+		wantNoMatch  metav1.ConditionStatus
+		wantReady    metav1.ConditionStatus
+		wantReason   string
+	}{
+		{name: "default", wantNoMatch: metav1.ConditionTrue, wantReady: metav1.ConditionFalse, wantReason: ReasonNoMatchingNamespaces},
+		{name: "not required", requireMatch: utils.GetBoolPtr(false), wantNoMatch: metav1.ConditionFalse, wantReady: metav1.ConditionTrue, wantReason: ReasonMatchNotRequired},
+		{name: "required", requireMatch: utils.GetBoolPtr(true), wantNoMatch: metav1.ConditionTrue, wantReady: metav1.ConditionFalse, wantReason: ReasonNoMatchingNamespaces},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := testConfig("cfg")
+			config.Spec.Config = &rbacoperatorv1.NamespaceRBACConfigConfig{RequireMatch: tt.requireMatch}
+			r, _ := newTestReconciler(t, rbac.Options{}, interceptor.Funcs{}, config, testNamespace("other", nil))
+
+			config = reconcileConfig(t, r, "cfg")
+
+			noMatch := meta.FindStatusCondition(config.Status.Conditions, ConditionTypeNoMatchingNamespaces)
+			if noMatch == nil || noMatch.Status != tt.wantNoMatch || noMatch.Reason != tt.wantReason {
+				t.Errorf("NoMatchingNamespaces = %+v, want status %s reason %s", noMatch, tt.wantNoMatch, tt.wantReason)
+			}
+			ready := meta.FindStatusCondition(config.Status.Conditions, ConditionTypeReady)
+			if ready == nil || ready.Status != tt.wantReady {
+				t.Errorf("Ready = %+v, want status %s", ready, tt.wantReady)
+			}
+		})
+	}
+}
+
+func TestRecordErrorKeepsNewest(t *testing.T) {
+	tests := []struct {
+		name      string
+		records   int
+		wantFirst string
+	}{
+		{name: "below the limit", records: 2, wantFirst: "error 0"},
+		{name: "at the limit", records: MaxRecentErrors, wantFirst: "error 0"},
+		{name: "beyond the limit", records: MaxRecentErrors + 3, wantFirst: "error 3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := testConfig("cfg")
+			for i := 0; i < tt.records; i++ {
+				recordError(config, fmt.Sprintf("ns-%d", i), fmt.Errorf("error %d", i))
+			}
+
+			errs := config.Status.RecentErrors
+			if want := min(tt.records, MaxRecentErrors); len(errs) != want {
+				t.Fatalf("kept %d errors, want %d", len(errs), want)
+			}
+			if errs[0].Message != tt.wantFirst {
+				t.Errorf("oldest error = %q, want %q", errs[0].Message, tt.wantFirst)
+			}
+			if last := errs[len(errs)-1]; last.Namespace != fmt.Sprintf("ns-%d", tt.records-1) || last.Timestamp.IsZero() {
+				t.Errorf("newest error = %+v, want namespace ns-%d with a timestamp", last, tt.records-1)
+			}
+		})
+	}
+}
+
+func TestReconcileRecordsNamespaceErrors(t *testing.T) {
+	r, _ := newTestReconciler(t, rbac.Options{}, interceptor.Funcs{
+		Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+			if obj.GetNamespace() == "team-b" {
+				return errors.NewInternalError(fmt.Errorf("boom"))
+			}
+			return c.Create(ctx, obj, opts...)
+		},
+	}, testConfig("cfg"), testNamespace("team-a", map[string]string{"team": "a"}), testNamespace("team-b", map[string]string{"team": "a"}))
+
+	config := reconcileConfig(t, r, "cfg")
+
+	if len(config.Status.RecentErrors) == 0 {
+		t.Fatal("no errors recorded")
+	}
+	for _, e := range config.Status.RecentErrors {
+		if e.Namespace != "team-b" {
+			t.Errorf("error recorded for namespace %q, want team-b: %s", e.Namespace, e.Message)
+		}
+	}
+}
+
+func TestReconcileReportsMergeFrozen(t *testing.T) {
+	frozen := &rbacv1.Role{ObjectMeta: metav1.ObjectMeta{
+		Namespace:   "team-a",
+		Name:        "viewer",
+		Annotations: map[string]string{rbac.MergeFreezeAnnotation: "true"},
+	}}
+	r, _ := newTestReconciler(t, rbac.Options{}, interceptor.Funcs{},
+		testConfig("cfg"), testNamespace("team-a", map[string]string{"team": "a"}), frozen)
+
+	config := reconcileConfig(t, r, "cfg")
+
+	condition := meta.FindStatusCondition(config.Status.Conditions, ConditionTypeMergeFrozen)
+	if condition == nil || condition.Status != metav1.ConditionTrue || condition.Reason != ReasonMergeFreezeAnnotation {
+		t.Fatalf("MergeFrozen = %+v, want True/%s", condition, ReasonMergeFreezeAnnotation)
+	}
+	if !strings.Contains(condition.Message, "Role team-a/viewer") {
+		t.Errorf("MergeFrozen message %q does not name the frozen Role", condition.Message)
+	}
+}
+
+func TestReconcileDegradesWhenRBACAPIUnavailable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+	}{
+		{
+			name: "no kind match",
+			err:  &meta.NoKindMatchError{GroupKind: schema.GroupKind{Group: rbacv1.GroupName, Kind: "Role"}, SearchedVersions: []string{"v1"}},
+		},
+		{
+			name: "no resource match",
+			err:  &meta.NoResourceMatchError{PartialResource: rbacv1.SchemeGroupVersion.WithResource("roles")},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, _ := newTestReconciler(t, rbac.Options{}, interceptor.Funcs{
+				Get: func(ctx context.Context, c client.WithWatch, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+					if _, ok := obj.(*rbacv1.Role); ok {
+						return tt.err
+					}
+					return c.Get(ctx, key, obj, opts...)
+				},
+			}, testConfig("cfg"), testNamespace("team-a", map[string]string{"team": "a"}))
+
+			config := reconcileConfig(t, r, "cfg")
+
+			condition := meta.FindStatusCondition(config.Status.Conditions, ConditionTypeDegraded)
+			if condition == nil || condition.Status != metav1.ConditionTrue || condition.Reason != ReasonRBACAPIUnavailable {
+				t.Fatalf("Degraded = %+v, want True/%s", condition, ReasonRBACAPIUnavailable)
+			}
+			if !strings.Contains(condition.Message, "RBAC API unavailable") {
+				t.Errorf("Degraded message %q does not say the RBAC API is unavailable", condition.Message)
+			}
+		})
+	}
+}
+
+func TestReconcileExportsRenderedRBAC(t *testing.T) {
+	tests := []struct {
+		name     string
+		existing *corev1.ConfigMap
+		wantKeys []string
+	}{
+		{
+			name:     "new ConfigMap",
+			wantKeys: []string{"team-a.yaml", "team-b.yaml"},
+		},
+		{
+			name: "stale keys are replaced",
+			existing: &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "audit", Name: "rbac-export"},
+				Data:       map[string]string{"team-old.yaml": "stale"},
+			},
+			wantKeys: []string{"team-a.yaml", "team-b.yaml"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := testConfig("cfg")
+			config.Spec.Config = &rbacoperatorv1.NamespaceRBACConfigConfig{
+				ExportTo: &rbacoperatorv1.ConfigMapReference{Namespace: "audit", Name: "rbac-export"},
+			}
+			objs := []client.Object{config,
+				testNamespace("team-a", map[string]string{"team": "a"}),
+				testNamespace("team-b", map[string]string{"team": "a"}),
+				testNamespace("other", nil),
+			}
+			if tt.existing != nil {
+				objs = append(objs, tt.existing)
+			}
+			r, c := newTestReconciler(t, rbac.Options{}, interceptor.Funcs{}, objs...)
+
+			reconcileConfig(t, r, "cfg")
+
+			configMap := &corev1.ConfigMap{}
+			if err := c.Get(context.Background(), types.NamespacedName{Namespace: "audit", Name: "rbac-export"}, configMap); err != nil {
+				t.Fatal(err)
+			}
+			var keys []string
+			for key := range configMap.Data {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			if !reflect.DeepEqual(keys, tt.wantKeys) {
+				t.Errorf("exported keys = %v, want %v", keys, tt.wantKeys)
+			}
+			if configMap.Labels[rbac.ConfigLabel] != "cfg" {
+				t.Errorf("config label = %q, want cfg", configMap.Labels[rbac.ConfigLabel])
+			}
+
+			exported := configMap.Data["team-a.yaml"]
+			for _, want := range []string{"kind: Role\n", "kind: RoleBinding\n", "name: viewer\n", "namespace: team-a\n", "- pods\n"} {
+				if !strings.Contains(exported, want) {
+					t.Errorf("exported YAML does not contain %q:\n%s", want, exported)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateConfigSubjectKinds(t *testing.T) {
+	tests := []struct {
+		name     string
+		subject  rbacv1.Subject
+		wantErrs []string
+	}{
+		{
+			name:    "user",
+			subject: rbacv1.Subject{Kind: rbacv1.UserKind, APIGroup: rbacv1.GroupName, Name: "alice"},
+		},
+		{
+			name:    "group",
+			subject: rbacv1.Subject{Kind: rbacv1.GroupKind, APIGroup: rbacv1.GroupName, Name: "team-a"},
+		},
+		{
+			name:    "service account",
+			subject: rbacv1.Subject{Kind: rbacv1.ServiceAccountKind, Name: "deployer", Namespace: "ci"},
+		},
+		{
+			name:    "service account in templated namespace",
+			subject: rbacv1.Subject{Kind: rbacv1.ServiceAccountKind, Name: "deployer", Namespace: "{{ .Namespace.Name }}"},
+		},
+		{
+			name:     "service account without namespace",
+			subject:  rbacv1.Subject{Kind: rbacv1.ServiceAccountKind, Name: "deployer"},
+			wantErrs: []string{"spec.rbacTemplates.roleBindings[0].subjects[0].namespace"},
+		},
+		{
+			name:     "misspelled kind",
+			subject:  rbacv1.Subject{Kind: "Gropu", APIGroup: rbacv1.GroupName, Name: "team-a"},
+			wantErrs: []string{"spec.rbacTemplates.roleBindings[0].subjects[0].kind"},
+		},
+		{
+			name:     "empty kind",
+			subject:  rbacv1.Subject{Name: "team-a"},
+			wantErrs: []string{"spec.rbacTemplates.roleBindings[0].subjects[0].kind"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, _ := newTestReconciler(t, rbac.Options{}, interceptor.Funcs{})
+			config := testConfig("cfg")
+			config.Spec.RBACTemplates.RoleBindings[0].Subjects = []rbacv1.Subject{tt.subject}
+
+			var got []string
+			for _, err := range r.validateConfig(config) {
+				got = append(got, err.Field)
+			}
+			if !reflect.DeepEqual(got, tt.wantErrs) {
+				t.Errorf("validation errors on %v, want %v", got, tt.wantErrs)
+			}
+		})
+	}
+}
+
+func TestReconcileSpecHash(t *testing.T) {
+	tests := []struct {
+		name        string
+		mutate      func(config *rbacoperatorv1.NamespaceRBACConfig)
+		wantChanged bool
+	}{
+		{
+			name:   "unchanged",
+			mutate: func(config *rbacoperatorv1.NamespaceRBACConfig) {},
+		},
+		{
+			name: "metadata only",
+			mutate: func(config *rbacoperatorv1.NamespaceRBACConfig) {
+				config.Labels = map[string]string{"owner": "platform"}
+			},
+		},
+		{
+			name: "rule changed",
+			mutate: func(config *rbacoperatorv1.NamespaceRBACConfig) {
+				config.Spec.RBACTemplates.Roles[0].Rules[0].Verbs = []string{"get", "list"}
+			},
+			wantChanged: true,
+		},
+		{
+			name: "selector changed",
+			mutate: func(config *rbacoperatorv1.NamespaceRBACConfig) {
+				config.Spec.NamespaceSelector.Labels["env"] = "prod"
+			},
+			wantChanged: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, c := newTestReconciler(t, rbac.Options{}, interceptor.Funcs{},
+				testConfig("cfg"), testNamespace("team-a", map[string]string{"team": "a"}))
+
+			before := reconcileConfig(t, r, "cfg").Status.SpecHash
+			if before == "" {
+				t.Fatal("spec hash not set")
+			}
+
+			config := &rbacoperatorv1.NamespaceRBACConfig{}
+			if err := c.Get(context.Background(), types.NamespacedName{Name: "cfg"}, config); err != nil {
+				t.Fatal(err)
+			}
+			tt.mutate(config)
+			if err := c.Update(context.Background(), config); err != nil {
+				t.Fatal(err)
+			}
+
+			after := reconcileConfig(t, r, "cfg").Status.SpecHash
+			if changed := after != before; changed != tt.wantChanged {
+				t.Errorf("hash changed = %v (%s -> %s), want %v", changed, before, after, tt.wantChanged)
+			}
+		})
+	}
+}
+
+func TestNextCleanupRetryBacksOff(t *testing.T) {
+	tests := []struct {
+		name     string
+		base     time.Duration
+		failures int
+		want     time.Duration
+	}{
+		{name: "first failure", base: time.Minute, failures: 1, want: time.Minute},
+		{name: "second failure", base: time.Minute, failures: 2, want: 2 * time.Minute},
+		{name: "fourth failure", base: time.Minute, failures: 4, want: 8 * time.Minute},
+		{name: "capped", base: time.Minute, failures: 10, want: MaxCleanupRetryInterval},
+		{name: "base above cap", base: time.Hour, failures: 1, want: MaxCleanupRetryInterval},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, _ := newTestReconciler(t, rbac.Options{}, interceptor.Funcs{})
+			r.CleanupRetryInterval = tt.base
+			var got time.Duration
+			for i := 0; i < tt.failures; i++ {
+				got = r.nextCleanupRetry("cfg")
+			}
+			if got != tt.want {
+				t.Errorf("retry after %d failures = %s, want %s", tt.failures, got, tt.want)
+			}
+
+			r.resetCleanupFailures("cfg")
+			if got := r.nextCleanupRetry("cfg"); got != min(tt.base, MaxCleanupRetryInterval) {
+				t.Errorf("retry after reset = %s, want %s", got, min(tt.base, MaxCleanupRetryInterval))
+			}
+		})
+	}
+}
+
+func TestDeletionKeepsFinalizerUntilCleanupSucceeds(t *testing.T) {
+	failCleanup := true
+	r, c := newTestReconciler(t, rbac.Options{}, interceptor.Funcs{
+		Delete: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.DeleteOption) error {
+			if _, ok := obj.(*rbacv1.RoleBinding); ok && failCleanup {
+				return errors.NewInternalError(fmt.Errorf("boom"))
+			}
+			return c.Delete(ctx, obj, opts...)
+		},
+	}, testConfig("cfg"), testNamespace("team-a", map[string]string{"team": "a"}))
+	r.CleanupRetryInterval = time.Second
+	reconcileConfig(t, r, "cfg")
+
+	config := &rbacoperatorv1.NamespaceRBACConfig{}
+	if err := c.Get(context.Background(), types.NamespacedName{Name: "cfg"}, config); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Delete(context.Background(), config); err != nil {
+		t.Fatal(err)
+	}
+
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "cfg"}}
+	for _, want := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second} {
+		result, err := r.Reconcile(context.Background(), req)
+		if err != nil {
+			t.Fatalf("Reconcile: %v", err)
+		}
+		if result.RequeueAfter != want {
+			t.Errorf("RequeueAfter = %s, want %s", result.RequeueAfter, want)
+		}
+		if err := c.Get(context.Background(), req.NamespacedName, config); err != nil {
+			t.Fatalf("config gone while cleanup fails: %v", err)
+		}
+		if !controllerutil.ContainsFinalizer(config, FinalizerName) {
+			t.Fatal("finalizer removed while cleanup fails")
+		}
+	}
+
+	failCleanup = false
+	if _, err := r.Reconcile(context.Background(), req); err != nil {
+		t.Fatalf("Reconcile: %v", err)
+	}
+	if err := c.Get(context.Background(), req.NamespacedName, config); !errors.IsNotFound(err) {
+		t.Errorf("config still present after cleanup succeeded: %v", err)
+	}
+}
+
+func TestReconcileResyncInterval(t *testing.T) {
+	tests := []struct {
+		name         string
+		interval     *metav1.Duration
+		wantRequeue  time.Duration
+		wantDegraded bool
+	}{
+		{name: "unset", wantRequeue: 0},
+		{name: "override", interval: &metav1.Duration{Duration: 10 * time.Minute}, wantRequeue: 10 * time.Minute},
+		{name: "zero", interval: &metav1.Duration{}, wantDegraded: true},
+		{name: "negative", interval: &metav1.Duration{Duration: -time.Minute}, wantDegraded: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := testConfig("cfg")
+			config.Spec.Config = &rbacoperatorv1.NamespaceRBACConfigConfig{ResyncInterval: tt.interval}
+			r, c := newTestReconciler(t, rbac.Options{}, interceptor.Funcs{}, config, testNamespace("team-a", map[string]string{"team": "a"}))
+
+			req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "cfg"}}
+			var result ctrl.Result
+			for i := 0; i < 5; i++ {
+				var err error
+				if result, err = r.Reconcile(context.Background(), req); err != nil {
+					t.Fatalf("Reconcile: %v", err)
+				}
+				if !result.Requeue {
+					break
+				}
+			}
+
+			if err := c.Get(context.Background(), req.NamespacedName, config); err != nil {
+				t.Fatal(err)
+			}
+			degraded := meta.IsStatusConditionTrue(config.Status.Conditions, ConditionTypeDegraded)
+			if degraded != tt.wantDegraded {
+				t.Fatalf("Degraded = %v, want %v", degraded, tt.wantDegraded)
+			}
+			if tt.wantDegraded {
+				condition := meta.FindStatusCondition(config.Status.Conditions, ConditionTypeDegraded)
+				if !strings.Contains(condition.Message, "resyncInterval") {
+					t.Errorf("Degraded message %q does not name resyncInterval", condition.Message)
+				}
+				return
+			}
+			if result.RequeueAfter != tt.wantRequeue {
+				t.Errorf("RequeueAfter = %s, want %s", result.RequeueAfter, tt.wantRequeue)
+			}
+		})
+	}
+}
+
+func TestCircuitBreakerTripsAndRecovers(t *testing.T) {
+	const threshold = 3
+	noMatch := &meta.NoKindMatchError{GroupKind: schema.GroupKind{Group: rbacv1.GroupName, Kind: "Role"}, SearchedVersions: []string{"v1"}}
+
+	tests := []struct {
+		name  string
+		close func(t *testing.T, r *NamespaceRBACConfigReconciler, c client.Client)
+	}{
+		{
+			name: "pause elapses",
+			close: func(t *testing.T, r *NamespaceRBACConfigReconciler, c client.Client) {
+				r.circuits["cfg"].openUntil = time.Now()
+			},
+		},
+		{
+			name: "spec changes",
+			close: func(t *testing.T, r *NamespaceRBACConfigReconciler, c client.Client) {
+				config := &rbacoperatorv1.NamespaceRBACConfig{}
+				if err := c.Get(context.Background(), types.NamespacedName{Name: "cfg"}, config); err != nil {
+					t.Fatal(err)
+				}
+				config.Generation++
+				if err := c.Update(context.Background(), config); err != nil {
+					t.Fatal(err)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			failing, gets := true, 0
+			r, c := newTestReconciler(t, rbac.Options{}, interceptor.Funcs{
+				Get: func(ctx context.Context, c client.WithWatch, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+					if _, ok := obj.(*rbacv1.Role); ok {
+						gets++
+						if failing {
+							return noMatch
+						}
+					}
+					return c.Get(ctx, key, obj, opts...)
+				},
+			}, testConfig("cfg"), testNamespace("team-a", map[string]string{"team": "a"}))
+			r.CircuitThreshold = threshold
+			r.CircuitInterval = time.Hour
+
+			var config *rbacoperatorv1.NamespaceRBACConfig
+			for i := 1; i <= threshold; i++ {
+				config = reconcileConfig(t, r, "cfg")
+				open := meta.IsStatusConditionTrue(config.Status.Conditions, ConditionTypeUnavailable)
+				if open != (i == threshold) {
+					t.Fatalf("after %d failures Unavailable = %v", i, open)
+				}
+			}
+
+			// While open, reconciles are skipped without touching RBAC
+			before := gets
+			req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "cfg"}}
+			result, err := r.Reconcile(context.Background(), req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if gets != before {
+				t.Errorf("open breaker still applied RBAC")
+			}
+			if result.RequeueAfter <= 0 || result.RequeueAfter > time.Hour {
+				t.Errorf("RequeueAfter = %s, want the remaining pause", result.RequeueAfter)
+			}
+
+			tt.close(t, r, c)
+			failing = false
+			config = reconcileConfig(t, r, "cfg")
+			condition := meta.FindStatusCondition(config.Status.Conditions, ConditionTypeUnavailable)
+			if condition == nil || condition.Status != metav1.ConditionFalse || condition.Reason != ReasonCircuitBreakerClosed {
+				t.Fatalf("Unavailable = %+v, want False/%s", condition, ReasonCircuitBreakerClosed)
+			}
+			if _, ok := r.circuits["cfg"]; ok {
+				t.Error("failure count not reset after a success")
+			}
+		})
+	}
+}
+
+func TestGenerationLag(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name       string
+		generation int64
+		observed   int64
+		created    time.Time
+		transition time.Time
+		want       time.Duration
+	}{
+		{name: "caught up", generation: 2, observed: 2, created: now.Add(-time.Hour), want: 0},
+		{name: "never observed", generation: 1, observed: 0, created: now.Add(-time.Minute), want: time.Minute},
+		{
+			name:       "behind since last transition",
+			generation: 3,
+			observed:   2,
+			created:    now.Add(-time.Hour),
+			transition: now.Add(-5 * time.Minute),
+			want:       5 * time.Minute,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := testConfig("cfg")
+			config.Generation = tt.generation
+			config.CreationTimestamp = metav1.NewTime(tt.created)
+			config.Status.ObservedGeneration = tt.observed
+			if !tt.transition.IsZero() {
+				config.Status.Conditions = []metav1.Condition{{
+					Type:               ConditionTypeReady,
+					Status:             metav1.ConditionTrue,
+					LastTransitionTime: metav1.NewTime(tt.transition),
+				}}
+			}
+
+			got := generationLag(config)
+			if got < tt.want || got > tt.want+time.Minute/2 {
+				t.Errorf("generationLag() = %s, want about %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateConfigExcludeNameRegex(t *testing.T) {
+	tests := []struct {
+		name     string
+		patterns []string
+		wantErrs []string
+	}{
+		{name: "valid", patterns: []string{"^temp-", "-scratch$"}},
+		{
+			name:     "invalid",
+			patterns: []string{"^temp-", "^scratch-("},
+			wantErrs: []string{"spec.namespaceSelector.excludeNameRegex[1]"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, _ := newTestReconciler(t, rbac.Options{}, interceptor.Funcs{})
+			config := testConfig("cfg")
+			config.Spec.NamespaceSelector.ExcludeNameRegex = tt.patterns
+
+			var got []string
+			for _, err := range r.validateConfig(config) {
+				got = append(got, err.Field)
+			}
+			if !reflect.DeepEqual(got, tt.wantErrs) {
+				t.Errorf("validation errors on %v, want %v", got, tt.wantErrs)
+			}
+		})
+	}
+}
+
+func TestOwnedResourceDeleteEnqueuesConfig(t *testing.T) {
+	owned := map[string]string{rbac.OwnerLabel: "namespace-rbac-operator", rbac.ConfigLabel: "cfg"}
+	role := func(labels map[string]string) *rbacv1.Role {
+		return &rbacv1.Role{ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "viewer", Labels: labels}}
+	}
+
+	tests := []struct {
+		name string
+		send func(h handler.EventHandler, q workqueue.RateLimitingInterface)
+		want []string
+	}{
+		{
+			name: "owned Role deleted",
+			send: func(h handler.EventHandler, q workqueue.RateLimitingInterface) {
+				if e := (event.DeleteEvent{Object: role(owned)}); deletePredicate.Delete(e) {
+					h.Delete(context.Background(), e, q)
+				}
+			},
+			want: []string{"cfg"},
+		},
+		{
+			name: "foreign Role deleted",
+			send: func(h handler.EventHandler, q workqueue.RateLimitingInterface) {
+				if e := (event.DeleteEvent{Object: role(nil)}); deletePredicate.Delete(e) {
+					h.Delete(context.Background(), e, q)
+				}
+			},
+		},
+		{
+			name: "owned Role updated",
+			send: func(h handler.EventHandler, q workqueue.RateLimitingInterface) {
+				if e := (event.UpdateEvent{ObjectOld: role(owned), ObjectNew: role(owned)}); deletePredicate.Update(e) {
+					h.Update(context.Background(), e, q)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())
+			defer q.ShutDown()
+
+			tt.send(handler.EnqueueRequestsFromMapFunc(mapOwnedResourceToConfig), q)
+
+			var got []string
+			for q.Len() > 0 {
+				item, _ := q.Get()
+				got = append(got, item.(reconcile.Request).Name)
+				q.Done(item)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("enqueued %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReconcileRecreatesDeletedRole(t *testing.T) {
+	r, c := newTestReconciler(t, rbac.Options{}, interceptor.Funcs{},
+		testConfig("cfg"), testNamespace("team-a", map[string]string{"team": "a"}))
+	reconcileConfig(t, r, "cfg")
+
+	key := types.NamespacedName{Namespace: "team-a", Name: "viewer"}
+	role := &rbacv1.Role{}
+	if err := c.Get(context.Background(), key, role); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Delete(context.Background(), role); err != nil {
+		t.Fatal(err)
+	}
+
+	// The delete event maps to this config, whose reconcile restores the Role
+	requests := mapOwnedResourceToConfig(context.Background(), role)
+	if len(requests) != 1 {
+		t.Fatalf("delete mapped to %v, want one request", requests)
+	}
+	reconcileConfig(t, r, requests[0].Name)
+	if err := c.Get(context.Background(), key, &rbacv1.Role{}); err != nil {
+		t.Errorf("Role not recreated: %v", err)
+	}
+}
+
+func TestNewConfigAppliesToNamespaceMissingFromCache(t *testing.T) {
+	tests := []struct {
+		name        string
+		apiReader   bool
+		wantApplied bool
+	}{
+		{name: "uncached list on a new spec", apiReader: true, wantApplied: true},
+		{name: "lagging cache only", apiReader: false, wantApplied: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, c := newTestReconciler(t, rbac.Options{}, interceptor.Funcs{},
+				testConfig("cfg"), testNamespace("team-a", map[string]string{"team": "a"}))
+			// The namespace was created just before the config and the cache has not seen it yet
+			r.NamespaceCache = fake.NewClientBuilder().WithScheme(r.Scheme).Build()
+			if tt.apiReader {
+				r.APIReader = c
+			}
+
+			config := reconcileConfig(t, r, "cfg")
+
+			err := c.Get(context.Background(), types.NamespacedName{Namespace: "team-a", Name: "viewer"}, &rbacv1.Role{})
+			if applied := err == nil; applied != tt.wantApplied {
+				t.Errorf("Role applied = %v (%v), want %v", applied, err, tt.wantApplied)
+			}
+			if applied := reflect.DeepEqual(config.Status.AppliedNamespaces, []string{"team-a"}); applied != tt.wantApplied {
+				t.Errorf("applied namespaces = %v, want team-a applied %v", config.Status.AppliedNamespaces, tt.wantApplied)
+			}
+		})
+	}
+}
+
+func TestValidateConfigMergeStrategy(t *testing.T) {
+	tests := []struct {
+		name     string
+		strategy string
+		wantErrs []string
+	}{
+		{name: "known strategy", strategy: "authoritative"},
+		{name: "template", strategy: `{{ if eq (index .Namespace.Labels "env") "prod" }}replace{{ else }}merge{{ end }}`},
+		{name: "unknown strategy", strategy: "overwrite", wantErrs: []string{"spec.config.mergeStrategy"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, _ := newTestReconciler(t, rbac.Options{}, interceptor.Funcs{})
+			strategy := rbacoperatorv1.MergeStrategy(tt.strategy)
+			config := testConfig("cfg")
+			config.Spec.Config = &rbacoperatorv1.NamespaceRBACConfigConfig{MergeStrategy: &strategy}
+
+			var got []string
+			for _, err := range r.validateConfig(config) {
+				got = append(got, err.Field)
+			}
+			if !reflect.DeepEqual(got, tt.wantErrs) {
+				t.Errorf("validation errors on %v, want %v", got, tt.wantErrs)
+			}
+		})
+	}
+}
+
+func TestValidateConfigApplyOrder(t *testing.T) {
+	tests := []struct {
+		name     string
+		order    []string
+		wantErrs []string
+	}{
+		{name: "partial order", order: []string{rbac.KindRoleBinding, rbac.KindRole}},
+		{name: "unknown kind", order: []string{rbac.KindRole, "Secret"}, wantErrs: []string{"spec.config.applyOrder[1]"}},
+		{name: "duplicate kind", order: []string{rbac.KindRole, rbac.KindRole}, wantErrs: []string{"spec.config.applyOrder[1]"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, _ := newTestReconciler(t, rbac.Options{}, interceptor.Funcs{})
+			config := testConfig("cfg")
+			config.Spec.Config = &rbacoperatorv1.NamespaceRBACConfigConfig{ApplyOrder: tt.order}
+
+			var got []string
+			for _, err := range r.validateConfig(config) {
+				got = append(got, err.Field)
+			}
+			if !reflect.DeepEqual(got, tt.wantErrs) {
+				t.Errorf("validation errors on %v, want %v", got, tt.wantErrs)
+			}
+		})
+	}
+}
+
+func TestReconcileSuspendAndResume(t *testing.T) {
+	suspend := true
+	config := testConfig("cfg")
+	config.Spec.Suspend = &suspend
+	r, c := newTestReconciler(t, rbac.Options{}, interceptor.Funcs{},
+		config, testNamespace("team-0", map[string]string{"team": "a"}))
+	roleKey := types.NamespacedName{Namespace: "team-0", Name: "viewer"}
+
+	steps := []struct {
+		name          string
+		suspend       bool
+		wantCondition metav1.ConditionStatus
+		wantReason    string
+		wantRole      bool
+	}{
+		{name: "suspended", suspend: true, wantCondition: metav1.ConditionTrue, wantReason: ReasonSuspended},
+		{name: "resumed", suspend: false, wantCondition: metav1.ConditionFalse, wantReason: ReasonNotSuspended, wantRole: true},
+	}
+
+	for _, step := range steps {
+		t.Run(step.name, func(t *testing.T) {
+			stored := &rbacoperatorv1.NamespaceRBACConfig{}
+			if err := c.Get(context.Background(), types.NamespacedName{Name: "cfg"}, stored); err != nil {
+				t.Fatal(err)
+			}
+			suspend := step.suspend
+			stored.Spec.Suspend = &suspend
+			if err := c.Update(context.Background(), stored); err != nil {
+				t.Fatal(err)
+			}
+
+			stored = reconcileConfig(t, r, "cfg")
+
+			cond := meta.FindStatusCondition(stored.Status.Conditions, ConditionTypeSuspended)
+			if cond == nil || cond.Status != step.wantCondition || cond.Reason != step.wantReason {
+				t.Errorf("%s condition = %+v, want %s/%s", ConditionTypeSuspended, cond, step.wantCondition, step.wantReason)
+			}
+			err := c.Get(context.Background(), roleKey, &rbacv1.Role{})
+			if gotRole := err == nil; gotRole != step.wantRole {
+				t.Errorf("role exists = %t, want %t (err %v)", gotRole, step.wantRole, err)
+			}
+		})
+	}
+}
+
+func TestValidateConfigMaxConflictRetries(t *testing.T) {
+	tests := []struct {
+		name     string
+		retries  int
+		wantErrs []string
+	}{
+		{name: "positive", retries: 5},
+		{name: "zero", retries: 0, wantErrs: []string{"spec.config.maxConflictRetries"}},
+		{name: "negative", retries: -1, wantErrs: []string{"spec.config.maxConflictRetries"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, _ := newTestReconciler(t, rbac.Options{}, interceptor.Funcs{})
+			retries := tt.retries
+			config := testConfig("cfg")
+			config.Spec.Config = &rbacoperatorv1.NamespaceRBACConfigConfig{MaxConflictRetries: &retries}
+
+			var got []string
+			for _, err := range r.validateConfig(config) {
+				got = append(got, err.Field)
+			}
+			if !reflect.DeepEqual(got, tt.wantErrs) {
+				t.Errorf("validation errors on %v, want %v", got, tt.wantErrs)
+			}
+		})
+	}
+}
+
+func TestReconcileSkipsOperatorNamespace(t *testing.T) {
+	tests := []struct {
+		name       string
+		allow      *bool
+		wantOpRole bool
+	}{
+		{name: "excluded by default"},
+		{name: "explicitly excluded", allow: utils.GetBoolPtr(false)},
+		{name: "allowed by config", allow: utils.GetBoolPtr(true), wantOpRole: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := testConfig("cfg")
+			config.Spec.Config = &rbacoperatorv1.NamespaceRBACConfigConfig{AllowOperatorNamespace: tt.allow}
+			r, c := newTestReconciler(t, rbac.Options{OperatorNamespace: "rbac-operator"}, interceptor.Funcs{}, config,
+				testNamespace("rbac-operator", map[string]string{"team": "a"}),
+				testNamespace("team-0", map[string]string{"team": "a"}))
+
+			reconcileConfig(t, r, "cfg")
+
+			if err := c.Get(context.Background(), types.NamespacedName{Namespace: "team-0", Name: "viewer"}, &rbacv1.Role{}); err != nil {
+				t.Errorf("role in team-0: %v", err)
+			}
+			err := c.Get(context.Background(), types.NamespacedName{Namespace: "rbac-operator", Name: "viewer"}, &rbacv1.Role{})
+			if gotRole := err == nil; gotRole != tt.wantOpRole {
+				t.Errorf("role in operator namespace = %t, want %t (err %v)", gotRole, tt.wantOpRole, err)
+			}
+		})
+	}
+}
+
+func TestReconcileReportsDriftCorrection(t *testing.T) {
+	tests := []struct {
+		name      string
+		drift     func(t *testing.T, c client.Client, role *rbacv1.Role)
+		wantDrift bool
+	}{
+		{
+			name:  "no drift",
+			drift: func(t *testing.T, c client.Client, role *rbacv1.Role) {},
+		},
+		{
+			name: "role deleted",
+			drift: func(t *testing.T, c client.Client, role *rbacv1.Role) {
+				if err := c.Delete(context.Background(), role); err != nil {
+					t.Fatal(err)
+				}
+			},
+			wantDrift: true,
+		},
+		{
+			name: "role rules modified",
+			drift: func(t *testing.T, c client.Client, role *rbacv1.Role) {
+				role.Rules = []rbacv1.PolicyRule{{APIGroups: []string{""}, Resources: []string{"secrets"}, Verbs: []string{"list"}}}
+				if err := c.Update(context.Background(), role); err != nil {
+					t.Fatal(err)
+				}
+			},
+			wantDrift: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, c := newTestReconciler(t, rbac.Options{}, interceptor.Funcs{},
+				testConfig("cfg"), testNamespace("team-a", map[string]string{"team": "a"}))
+			reconcileConfig(t, r, "cfg")
+			metrics.ResetMetrics()
+
+			role := &rbacv1.Role{}
+			if err := c.Get(context.Background(), types.NamespacedName{Namespace: "team-a", Name: "viewer"}, role); err != nil {
+				t.Fatal(err)
+			}
+			tt.drift(t, c, role)
+			config := reconcileConfig(t, r, "cfg")
+
+			cond := meta.FindStatusCondition(config.Status.Conditions, ConditionTypeDriftCorrected)
+			wantCorrections := 0.0
+			if tt.wantDrift {
+				wantCorrections = 1
+				if cond == nil || cond.Status != metav1.ConditionTrue || cond.Reason != ReasonResourcesRestored {
+					t.Fatalf("%s condition = %+v, want True/%s", ConditionTypeDriftCorrected, cond, ReasonResourcesRestored)
+				}
+				if !strings.HasPrefix(cond.Message, "Restored 1 resource(s) at ") || !strings.Contains(cond.Message, "viewer") {
+					t.Errorf("%s message = %q", ConditionTypeDriftCorrected, cond.Message)
+				}
+			} else if cond != nil {
+				t.Errorf("%s condition = %+v, want none", ConditionTypeDriftCorrected, cond)
+			}
+			if got := testutil.ToFloat64(metrics.DriftCorrections.WithLabelValues("cfg", "role")); got != wantCorrections {
+				t.Errorf("role drift corrections = %v, want %v", got, wantCorrections)
+			}
+		})
+	}
+}
+
+func TestSteadyStateReconcilesListNamespacesFromCache(t *testing.T) {
+	r, c := newTestReconciler(t, rbac.Options{}, interceptor.Funcs{},
+		testConfig("cfg"), testNamespace("team-a", map[string]string{"team": "a"}))
+
+	// countingReader counts namespace lists served by c
+	countingReader := func(count *int) client.Reader {
+		return interceptor.NewClient(c.(client.WithWatch), interceptor.Funcs{
+			List: func(ctx context.Context, c client.WithWatch, list client.ObjectList, opts ...client.ListOption) error {
+				if _, ok := list.(*corev1.NamespaceList); ok {
+					*count++
+				}
+				return c.List(ctx, list, opts...)
+			},
+		})
+	}
+	var apiLists, cacheLists int
+	r.APIReader = countingReader(&apiLists)
+	r.NamespaceCache = countingReader(&cacheLists)
+
+	// The first reconcile observes a new generation and reads from the API server
+	reconcileConfig(t, r, "cfg")
+	if apiLists == 0 {
+		t.Fatal("new generation did not list namespaces from the API server")
+	}
+	firstAPILists, firstCacheLists := apiLists, cacheLists
+
+	const resyncs = 3
+	for i := 0; i < resyncs; i++ {
+		reconcileConfig(t, r, "cfg")
+	}
+	if apiLists != firstAPILists {
+		t.Errorf("steady-state reconciles made %d API namespace lists, want 0", apiLists-firstAPILists)
+	}
+	if got := cacheLists - firstCacheLists; got < resyncs {
+		t.Errorf("steady-state reconciles made %d cached namespace lists, want at least %d", got, resyncs)
+	}
+}
+
+func TestValidateConfigIncludeNamespaceGlobs(t *testing.T) {
+	tests := []struct {
+		name     string
+		globs    []string
+		wantErrs []string
+	}{
+		{name: "valid globs", globs: []string{"team-*", "ops-?"}},
+		{name: "malformed glob", globs: []string{"team-*", "team-["}, wantErrs: []string{"spec.namespaceSelector.includeNamespaceGlobs[1]"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, _ := newTestReconciler(t, rbac.Options{}, interceptor.Funcs{})
+			config := testConfig("cfg")
+			config.Spec.NamespaceSelector.IncludeNamespaceGlobs = tt.globs
+
+			var got []string
+			for _, err := range r.validateConfig(config) {
+				got = append(got, err.Field)
+			}
+			if !reflect.DeepEqual(got, tt.wantErrs) {
+				t.Errorf("validation errors on %v, want %v", got, tt.wantErrs)
+			}
+		})
+	}
+}
+
+func TestValidateConfigOwnerReferenceStrategy(t *testing.T) {
+	tests := []struct {
+		name         string
+		strategy     rbacoperatorv1.OwnerReferenceStrategy
+		useFinalizer *bool
+		wantErrs     []string
+	}{
+		{name: "namespace", strategy: rbacoperatorv1.OwnerReferenceNamespace},
+		{name: "config", strategy: rbacoperatorv1.OwnerReferenceConfig},
+		{name: "none", strategy: rbacoperatorv1.OwnerReferenceNone},
+		{name: "unknown", strategy: "parent", wantErrs: []string{"spec.config.ownerReferenceStrategy"}},
+		{name: "no finalizer with config owner", strategy: rbacoperatorv1.OwnerReferenceConfig, useFinalizer: utils.GetBoolPtr(false)},
+		{name: "no finalizer with namespace owner", strategy: rbacoperatorv1.OwnerReferenceNamespace, useFinalizer: utils.GetBoolPtr(false),
+			wantErrs: []string{"spec.config.cleanup.useFinalizer"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, _ := newTestReconciler(t, rbac.Options{}, interceptor.Funcs{})
+			strategy := tt.strategy
+			config := testConfig("cfg")
+			config.Spec.Config = &rbacoperatorv1.NamespaceRBACConfigConfig{OwnerReferenceStrategy: &strategy}
+			if tt.useFinalizer != nil {
+				config.Spec.Config.Cleanup = &rbacoperatorv1.CleanupConfig{UseFinalizer: tt.useFinalizer}
+			}
+
+			var got []string
+			for _, err := range r.validateConfig(config) {
+				got = append(got, err.Field)
+			}
+			if !reflect.DeepEqual(got, tt.wantErrs) {
+				t.Errorf("validation errors on %v, want %v", got, tt.wantErrs)
+			}
+		})
+	}
+}
+
+func TestReconcileReportsEscalationDenied(t *testing.T) {
+	tests := []struct {
+		name       string
+		createErr  error
+		wantDenied bool
+	}{
+		{
+			name:       "forbidden role create",
+			createErr:  errors.NewForbidden(rbacv1.Resource("roles"), "viewer", fmt.Errorf("attempting to grant RBAC permissions not currently held")),
+			wantDenied: true,
+		},
+		{
+			name: "accepted",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, _ := newTestReconciler(t, rbac.Options{}, interceptor.Funcs{
+				Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+					if _, ok := obj.(*rbacv1.Role); ok && tt.createErr != nil {
+						return tt.createErr
+					}
+					return c.Create(ctx, obj, opts...)
+				},
+			}, testConfig("cfg"), testNamespace("team-a", map[string]string{"team": "a"}))
+			r.DegradedGracePeriod = 0
+			metrics.ResetMetrics()
+
+			req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "cfg"}}
+			for i := 0; i < 5; i++ {
+				result, _ := r.Reconcile(context.Background(), req)
+				if !result.Requeue {
+					break
+				}
+			}
+			config := &rbacoperatorv1.NamespaceRBACConfig{}
+			if err := r.Get(context.Background(), req.NamespacedName, config); err != nil {
+				t.Fatal(err)
+			}
+
+			cond := meta.FindStatusCondition(config.Status.Conditions, ConditionTypeEscalationDenied)
+			if !tt.wantDenied {
+				if cond == nil || cond.Status != metav1.ConditionFalse {
+					t.Errorf("%s = %+v, want False", ConditionTypeEscalationDenied, cond)
+				}
+				return
+			}
+			if cond == nil || cond.Status != metav1.ConditionTrue || cond.Reason != ReasonEscalationDenied {
+				t.Fatalf("%s = %+v, want True/%s", ConditionTypeEscalationDenied, cond, ReasonEscalationDenied)
+			}
+			if !strings.Contains(cond.Message, "viewer") {
+				t.Errorf("%s message %q does not name the rejected resource", ConditionTypeEscalationDenied, cond.Message)
+			}
+			degraded := meta.FindStatusCondition(config.Status.Conditions, ConditionTypeDegraded)
+			if degraded == nil || degraded.Reason != ReasonEscalationDenied {
+				t.Errorf("Degraded = %+v, want reason %s", degraded, ReasonEscalationDenied)
+			}
+			if got := testutil.ToFloat64(metrics.ReconciliationErrors.WithLabelValues("cfg", "NamespaceRBACConfig", "escalation")); got == 0 {
+				t.Error("no reconcile error recorded as escalation")
+			}
+		})
+	}
+}
+
+func TestReconcileReportsTemplateWarnings(t *testing.T) {
+	tests := []struct {
+		name       string
+		roleName   string
+		wantStatus metav1.ConditionStatus
+	}{
+		{name: "no warnings", roleName: "viewer", wantStatus: metav1.ConditionFalse},
+		{name: "empty rendered name", roleName: `{{ getOrDefault .Namespace.Labels "owner" "" }}`, wantStatus: metav1.ConditionTrue},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := testConfig("cfg")
+			config.Spec.RBACTemplates.Roles[0].Name = tt.roleName
+			config.Spec.RBACTemplates.RoleBindings = nil
+			r, _ := newTestReconciler(t, rbac.Options{}, interceptor.Funcs{},
+				config, testNamespace("team-a", map[string]string{"team": "a"}))
+
+			stored := reconcileConfig(t, r, "cfg")
+
+			cond := meta.FindStatusCondition(stored.Status.Conditions, ConditionTypeTemplateWarnings)
+			if cond == nil || cond.Status != tt.wantStatus {
+				t.Fatalf("%s = %+v, want %s", ConditionTypeTemplateWarnings, cond, tt.wantStatus)
+			}
+			if tt.wantStatus == metav1.ConditionTrue && (cond.Reason != ReasonLintWarnings || !strings.Contains(cond.Message, "empty name")) {
+				t.Errorf("%s = %s/%q, want %s mentioning the empty name", ConditionTypeTemplateWarnings, cond.Reason, cond.Message, ReasonLintWarnings)
+			}
+			if ready := meta.FindStatusCondition(stored.Status.Conditions, ConditionTypeReady); ready == nil || ready.Status != metav1.ConditionTrue {
+				t.Errorf("Ready = %+v, want True despite warnings", ready)
+			}
+		})
+	}
+}
+
+func TestClusterRoleDeleteEnqueuesProducingConfigs(t *testing.T) {
+	withClusterRole := func(name, clusterRole string) *rbacoperatorv1.NamespaceRBACConfig {
+		config := testConfig(name)
+		config.Spec.RBACTemplates.ClusterRoles = []rbacoperatorv1.ClusterRoleTemplate{{Name: clusterRole}}
+		return config
+	}
+	clusterRole := func(name, config string) *rbacv1.ClusterRole {
+		role := &rbacv1.ClusterRole{ObjectMeta: metav1.ObjectMeta{Name: name}}
+		if config != "" {
+			role.Labels = map[string]string{rbac.OwnerLabel: "namespace-rbac-operator", rbac.ConfigLabel: config}
+		}
+		return role
+	}
+
+	tests := []struct {
+		name    string
+		deleted *rbacv1.ClusterRole
+		want    []string
+	}{
+		{name: "shared ClusterRole", deleted: clusterRole("shared-viewer", "cfg-a"), want: []string{"cfg-a", "cfg-b"}},
+		{name: "per-namespace ClusterRole", deleted: clusterRole("viewer-team-a", "cfg-c"), want: []string{"cfg-c"}},
+		{name: "foreign ClusterRole", deleted: clusterRole("shared-viewer", "")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, _ := newTestReconciler(t, rbac.Options{}, interceptor.Funcs{},
+				withClusterRole("cfg-a", "shared-viewer"),
+				withClusterRole("cfg-b", "shared-viewer"),
+				withClusterRole("cfg-c", "viewer-{{ .Namespace.Name }}"),
+				testNamespace("team-a", map[string]string{"team": "a"}))
+			q := workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())
+			defer q.ShutDown()
+
+			e := event.DeleteEvent{Object: tt.deleted}
+			if deletePredicate.Delete(e) {
+				handler.EnqueueRequestsFromMapFunc(r.mapClusterRoleToConfigs).Delete(context.Background(), e, q)
+			}
+
+			var got []string
+			for q.Len() > 0 {
+				item, _ := q.Get()
+				got = append(got, item.(reconcile.Request).Name)
+				q.Done(item)
+			}
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("enqueued %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateConfigLimits(t *testing.T) {
+	one, two, zero := 1, 2, 0
+
+	tests := []struct {
+		name     string
+		limits   rbacoperatorv1.LimitsConfig
+		wantErrs []string
+	}{
+		{name: "rules at the limit", limits: rbacoperatorv1.LimitsConfig{MaxRulesPerRole: &two}},
+		{
+			name:     "rules above the limit",
+			limits:   rbacoperatorv1.LimitsConfig{MaxRulesPerRole: &one},
+			wantErrs: []string{"spec.rbacTemplates.roles[0].rules"},
+		},
+		{name: "subjects at the limit", limits: rbacoperatorv1.LimitsConfig{MaxSubjectsPerBinding: &two}},
+		{
+			name:     "subjects above the limit",
+			limits:   rbacoperatorv1.LimitsConfig{MaxSubjectsPerBinding: &one},
+			wantErrs: []string{"spec.rbacTemplates.roleBindings[0].subjects"},
+		},
+		{
+			name:     "non-positive limits",
+			limits:   rbacoperatorv1.LimitsConfig{MaxRulesPerRole: &zero, MaxSubjectsPerBinding: &zero},
+			wantErrs: []string{"spec.config.limits.maxRulesPerRole", "spec.config.limits.maxSubjectsPerBinding"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, _ := newTestReconciler(t, rbac.Options{}, interceptor.Funcs{})
+			config := testConfig("cfg")
+			// Two rules, one of them simple, and two subjects
+			role := &config.Spec.RBACTemplates.Roles[0]
+			role.SimpleRules = []rbacoperatorv1.SimpleRule{{Resources: []string{"configmaps"}, Access: rbacoperatorv1.AccessRead}}
+			binding := &config.Spec.RBACTemplates.RoleBindings[0]
+			binding.Subjects = append(binding.Subjects, rbacv1.Subject{Kind: rbacv1.GroupKind, APIGroup: rbacv1.GroupName, Name: "team-b"})
+			config.Spec.Config = &rbacoperatorv1.NamespaceRBACConfigConfig{Limits: &tt.limits}
+
+			var got []string
+			for _, err := range r.validateConfig(config) {
+				got = append(got, err.Field)
+			}
+			if !reflect.DeepEqual(got, tt.wantErrs) {
+				t.Errorf("validation errors on %v, want %v", got, tt.wantErrs)
+			}
+		})
+	}
+}
+
+func TestReconcileSkipsUnchangedStatusWrites(t *testing.T) {
+	tests := []struct {
+		name       string
+		change     func(t *testing.T, c client.Client)
+		wantWrites int
+	}{
+		{name: "identical reconcile"},
+		{
+			name: "new matching namespace",
+			change: func(t *testing.T, c client.Client) {
+				if err := c.Create(context.Background(), testNamespace("team-1", map[string]string{"team": "a"})); err != nil {
+					t.Fatal(err)
+				}
+			},
+			wantWrites: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var counting bool
+			writes := 0
+			count := func() {
+				if counting {
+					writes++
+				}
+			}
+			r, c := newTestReconciler(t, rbac.Options{}, interceptor.Funcs{
+				SubResourceUpdate: func(ctx context.Context, c client.Client, subResourceName string, obj client.Object, opts ...client.SubResourceUpdateOption) error {
+					count()
+					return c.SubResource(subResourceName).Update(ctx, obj, opts...)
+				},
+				SubResourcePatch: func(ctx context.Context, c client.Client, subResourceName string, obj client.Object, patch client.Patch, opts ...client.SubResourcePatchOption) error {
+					count()
+					return c.SubResource(subResourceName).Patch(ctx, obj, patch, opts...)
+				},
+			}, testConfig("cfg"), testNamespace("team-0", map[string]string{"team": "a"}))
+
+			reconcileConfig(t, r, "cfg")
+			if tt.change != nil {
+				tt.change(t, c)
+			}
+			counting = true
+			reconcileConfig(t, r, "cfg")
+
+			if writes != tt.wantWrites {
+				t.Errorf("status writes = %d, want %d", writes, tt.wantWrites)
+			}
+		})
+	}
+}
+
+func TestReconcileDefersUntilNamespaceReady(t *testing.T) {
+	const annotation = "example.com/rbac-ready"
+
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		wantApplied bool
+	}{
+		{name: "annotation missing"},
+		{name: "annotation not true", annotations: map[string]string{annotation: "false"}},
+		{name: "annotation true", annotations: map[string]string{annotation: "true"}, wantApplied: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := testConfig("cfg")
+			config.Spec.Config = &rbacoperatorv1.NamespaceRBACConfigConfig{
+				WaitForNamespaceAnnotation: annotation,
+				RequireMatch:               utils.GetBoolPtr(true),
+			}
+			ns := testNamespace("team-0", map[string]string{"team": "a"})
+			ns.Annotations = tt.annotations
+			r, c := newTestReconciler(t, rbac.Options{}, interceptor.Funcs{}, config, ns)
+
+			reconcileConfig(t, r, "cfg")
+			result, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: types.NamespacedName{Name: "cfg"}})
+			if err != nil {
+				t.Fatal(err)
+			}
+			stored := &rbacoperatorv1.NamespaceRBACConfig{}
+			if err := c.Get(context.Background(), types.NamespacedName{Name: "cfg"}, stored); err != nil {
+				t.Fatal(err)
+			}
+
+			err = c.Get(context.Background(), types.NamespacedName{Namespace: "team-0", Name: "viewer"}, &rbacv1.Role{})
+			if applied := err == nil; applied != tt.wantApplied {
+				t.Errorf("Role created = %v, want %v (err %v)", applied, tt.wantApplied, err)
+			}
+			if waiting := meta.IsStatusConditionTrue(stored.Status.Conditions, ConditionTypeWaitingForNamespaces); waiting == tt.wantApplied {
+				t.Errorf("%s = %v, want %v", ConditionTypeWaitingForNamespaces, waiting, !tt.wantApplied)
+			}
+			if polling := result.RequeueAfter == NamespaceReadinessRequeueInterval; polling == tt.wantApplied {
+				t.Errorf("RequeueAfter = %v, want polling %v", result.RequeueAfter, !tt.wantApplied)
+			}
+			// A waiting namespace still matched the selector
+			noMatch := meta.FindStatusCondition(stored.Status.Conditions, ConditionTypeNoMatchingNamespaces)
+			if noMatch == nil || noMatch.Status != metav1.ConditionFalse || noMatch.Message != "Selector matched 1 namespace(s)" {
+				t.Errorf("%s = %+v, want False reporting 1 matched namespace", ConditionTypeNoMatchingNamespaces, noMatch)
+			}
+			if !meta.IsStatusConditionTrue(stored.Status.Conditions, ConditionTypeReady) {
+				t.Errorf("Ready = %+v, want True", meta.FindStatusCondition(stored.Status.Conditions, ConditionTypeReady))
+			}
+		})
+	}
+}
+
+func TestReconcileRecoversFromPanic(t *testing.T) {
+	tests := []struct {
+		name      string
+		panicOn   func(list client.ObjectList) bool
+		wantPanic bool
+	}{
+		{name: "no panic", panicOn: func(client.ObjectList) bool { return false }},
+		{
+			name:      "panic while listing namespaces",
+			panicOn:   func(list client.ObjectList) bool { _, ok := list.(*corev1.NamespaceList); return ok },
+			wantPanic: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configName := "panic-" + strings.ReplaceAll(tt.name, " ", "-")
+			r, _ := newTestReconciler(t, rbac.Options{}, interceptor.Funcs{
+				List: func(ctx context.Context, c client.WithWatch, list client.ObjectList, opts ...client.ListOption) error {
+					if tt.panicOn(list) {
+						panic("boom")
+					}
+					return c.List(ctx, list, opts...)
+				},
+			}, testConfig(configName), testNamespace("team-0", map[string]string{"team": "a"}))
+			r.healthChecker.RecordReconcile()
+			panics := metrics.ReconcilePanics.WithLabelValues("NamespaceRBACConfig")
+			panicErrors := metrics.ReconciliationErrors.WithLabelValues(configName, "NamespaceRBACConfig", "panic")
+			panicsBefore, errorsBefore := testutil.ToFloat64(panics), testutil.ToFloat64(panicErrors)
+
+			req := ctrl.Request{NamespacedName: types.NamespacedName{Name: configName}}
+			var err error
+			for i := 0; i < 3 && err == nil; i++ {
+				_, err = r.Reconcile(context.Background(), req)
+			}
+
+			if gotPanic := err != nil && strings.Contains(err.Error(), "recovered from panic: boom"); gotPanic != tt.wantPanic {
+				t.Fatalf("Reconcile() error = %v, want recovered panic %v", err, tt.wantPanic)
+			}
+			want := 0.0
+			if tt.wantPanic {
+				want = 1
+			}
+			if got := testutil.ToFloat64(panics) - panicsBefore; got != want {
+				t.Errorf("rbac_operator_reconcile_panics_total increased by %v, want %v", got, want)
+			}
+			if got := testutil.ToFloat64(panicErrors) - errorsBefore; got != want {
+				t.Errorf("panic reconciliation errors increased by %v, want %v", got, want)
+			}
+			if healthy := r.healthChecker.IsHealthy(); healthy == tt.wantPanic {
+				t.Errorf("IsHealthy() = %v, want %v", healthy, !tt.wantPanic)
+			}
+		})
+	}
+}
+
+func TestResourceQuotaEventsEnqueueQuotaConfigs(t *testing.T) {
+	required := true
+	quotaConfig := testConfig("with-quota")
+	quotaConfig.Spec.NamespaceSelector.HasResourceQuota = &required
+	r, _ := newTestReconciler(t, rbac.Options{}, interceptor.Funcs{}, quotaConfig, testConfig("without-quota"))
+	quota := &corev1.ResourceQuota{ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "compute"}}
+
+	tests := []struct {
+		name  string
+		event func(h handler.EventHandler, q workqueue.RateLimitingInterface)
+		want  []string
+	}{
+		{
+			name: "create",
+			event: func(h handler.EventHandler, q workqueue.RateLimitingInterface) {
+				if e := (event.CreateEvent{Object: quota}); createDeletePredicate.Create(e) {
+					h.Create(context.Background(), e, q)
+				}
+			},
+			want: []string{"with-quota"},
+		},
+		{
+			name: "delete",
+			event: func(h handler.EventHandler, q workqueue.RateLimitingInterface) {
+				if e := (event.DeleteEvent{Object: quota}); createDeletePredicate.Delete(e) {
+					h.Delete(context.Background(), e, q)
+				}
+			},
+			want: []string{"with-quota"},
+		},
+		{
+			name: "update",
+			event: func(h handler.EventHandler, q workqueue.RateLimitingInterface) {
+				if e := (event.UpdateEvent{ObjectOld: quota, ObjectNew: quota}); createDeletePredicate.Update(e) {
+					h.Update(context.Background(), e, q)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())
+			defer q.ShutDown()
+
+			tt.event(handler.EnqueueRequestsFromMapFunc(r.mapResourceQuotaToConfigs), q)
+
+			var got []string
+			for q.Len() > 0 {
+				item, _ := q.Get()
+				got = append(got, item.(reconcile.Request).Name)
+				q.Done(item)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("enqueued %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsConditionTrueForGeneration(t *testing.T) {
+	tests := []struct {
+		name       string
+		conditions []metav1.Condition
+		want       bool
+	}{
+		{name: "missing"},
+		{name: "current", conditions: []metav1.Condition{{Type: ConditionTypeReady, Status: metav1.ConditionTrue, ObservedGeneration: 2}}, want: true},
+		{name: "stale", conditions: []metav1.Condition{{Type: ConditionTypeReady, Status: metav1.ConditionTrue, ObservedGeneration: 1}}},
+		{name: "current but false", conditions: []metav1.Condition{{Type: ConditionTypeReady, Status: metav1.ConditionFalse, ObservedGeneration: 2}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := testConfig("cfg")
+			config.Generation = 2
+			config.Status.Conditions = tt.conditions
+			if got := IsConditionTrueForGeneration(config, ConditionTypeReady); got != tt.want {
+				t.Errorf("IsConditionTrueForGeneration() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReconcileStampsConditionsWithGeneration(t *testing.T) {
+	r, c := newTestReconciler(t, rbac.Options{}, interceptor.Funcs{},
+		testConfig("cfg"), testNamespace("team-0", map[string]string{"team": "a"}))
+	stored := reconcileConfig(t, r, "cfg")
+	if !IsConditionTrueForGeneration(stored, ConditionTypeReady) {
+		t.Fatalf("Ready not current after reconcile: %+v", stored.Status.Conditions)
+	}
+
+	// A spec change bumps the generation; until reconciled, Ready is stale
+	stored.Spec.RBACTemplates.Roles[0].Rules[0].Verbs = []string{"get", "list"}
+	stored.Generation++
+	if err := c.Update(context.Background(), stored); err != nil {
+		t.Fatal(err)
+	}
+	if IsConditionTrueForGeneration(stored, ConditionTypeReady) {
+		t.Errorf("Ready reported current for generation %d before reconciling", stored.Generation)
+	}
+
+	stored = reconcileConfig(t, r, "cfg")
+	for _, condition := range stored.Status.Conditions {
+		if condition.ObservedGeneration != stored.Generation {
+			t.Errorf("%s observedGeneration = %d, want %d", condition.Type, condition.ObservedGeneration, stored.Generation)
+		}
+	}
+	if !IsConditionTrueForGeneration(stored, ConditionTypeReady) {
+		t.Errorf("Ready not current after reconciling generation %d", stored.Generation)
+	}
+}
+
+func TestValidateConfigClusterRoleBindingRoleRefKind(t *testing.T) {
+	tests := []struct {
+		name     string
+		kind     string
+		wantErrs []string
+	}{
+		{name: "ClusterRole", kind: rbac.KindClusterRole},
+		{name: "Role", kind: rbac.KindRole, wantErrs: []string{"spec.rbacTemplates.clusterRoleBindings[0].roleRef.kind"}},
+		{name: "empty", kind: "", wantErrs: []string{"spec.rbacTemplates.clusterRoleBindings[0].roleRef.kind"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, _ := newTestReconciler(t, rbac.Options{}, interceptor.Funcs{})
+			config := testConfig("cfg")
+			config.Spec.RBACTemplates.ClusterRoleBindings = []rbacoperatorv1.ClusterRoleBindingTemplate{{
+				Name:     "viewer-{{ .Namespace.Name }}",
+				RoleRef:  rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: tt.kind, Name: "view"},
+				Subjects: []rbacv1.Subject{{Kind: rbacv1.GroupKind, APIGroup: rbacv1.GroupName, Name: "team-a"}},
+			}}
+
+			var got []string
+			for _, err := range r.validateConfig(config) {
+				got = append(got, err.Field)
+			}
+			if !reflect.DeepEqual(got, tt.wantErrs) {
+				t.Errorf("validation errors on %v, want %v", got, tt.wantErrs)
+			}
+		})
+	}
+}
+
+func TestValidateConfigSimpleRules(t *testing.T) {
+	tests := []struct {
+		name     string
+		rule     rbacoperatorv1.SimpleRule
+		wantErrs []string
+	}{
+		{name: "valid", rule: rbacoperatorv1.SimpleRule{Resources: []string{"pods"}, Access: rbacoperatorv1.AccessWrite}},
+		{
+			name:     "no resources",
+			rule:     rbacoperatorv1.SimpleRule{Access: rbacoperatorv1.AccessRead},
+			wantErrs: []string{"spec.rbacTemplates.roles[0].simpleRules[0].resources"},
+		},
+		{
+			name:     "unknown access",
+			rule:     rbacoperatorv1.SimpleRule{Resources: []string{"pods"}, Access: "owner"},
+			wantErrs: []string{"spec.rbacTemplates.roles[0].simpleRules[0].access"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, _ := newTestReconciler(t, rbac.Options{}, interceptor.Funcs{})
+			config := testConfig("cfg")
+			config.Spec.RBACTemplates.Roles[0].SimpleRules = []rbacoperatorv1.SimpleRule{tt.rule}
+
+			var got []string
+			for _, err := range r.validateConfig(config) {
+				got = append(got, err.Field)
+			}
+			if !reflect.DeepEqual(got, tt.wantErrs) {
+				t.Errorf("validation errors on %v, want %v", got, tt.wantErrs)
+			}
+		})
+	}
+}
+
+func TestReconcileRequeuesPartialFailuresWithBackoff(t *testing.T) {
+	failing := true
+	r, _ := newTestReconciler(t, rbac.Options{}, interceptor.Funcs{
+		Get: func(ctx context.Context, c client.WithWatch, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+			if _, ok := obj.(*rbacv1.Role); ok && failing && key.Namespace == "team-1" {
+				return errors.NewInternalError(fmt.Errorf("etcd unavailable"))
+			}
+			return c.Get(ctx, key, obj, opts...)
+		},
+	}, testConfig("cfg"),
+		testNamespace("team-0", map[string]string{"team": "a"}),
+		testNamespace("team-1", map[string]string{"team": "a"}))
+	reconcileConfig(t, r, "cfg")
+
+	steps := []struct {
+		name        string
+		failing     bool
+		wantRequeue time.Duration
+		wantPartial metav1.ConditionStatus
+	}{
+		{name: "second failure backs off", failing: true, wantRequeue: 2 * DefaultPartialFailureRetryInterval, wantPartial: metav1.ConditionTrue},
+		{name: "third failure backs off", failing: true, wantRequeue: 4 * DefaultPartialFailureRetryInterval, wantPartial: metav1.ConditionTrue},
+		{name: "recovered", wantPartial: metav1.ConditionFalse},
+		{name: "backoff restarts", failing: true, wantRequeue: DefaultPartialFailureRetryInterval, wantPartial: metav1.ConditionTrue},
+	}
+
+	for _, step := range steps {
+		t.Run(step.name, func(t *testing.T) {
+			failing = step.failing
+			result, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: types.NamespacedName{Name: "cfg"}})
+			if err != nil {
+				t.Fatalf("Reconcile() error = %v, want partial failures handled", err)
+			}
+			if result.RequeueAfter != step.wantRequeue {
+				t.Errorf("RequeueAfter = %v, want %v", result.RequeueAfter, step.wantRequeue)
+			}
+			stored := &rbacoperatorv1.NamespaceRBACConfig{}
+			if err := r.Get(context.Background(), types.NamespacedName{Name: "cfg"}, stored); err != nil {
+				t.Fatal(err)
+			}
+			if cond := meta.FindStatusCondition(stored.Status.Conditions, ConditionTypePartiallyApplied); cond == nil || cond.Status != step.wantPartial {
+				t.Errorf("%s = %+v, want %s", ConditionTypePartiallyApplied, cond, step.wantPartial)
+			}
+		})
+	}
+}
+
+func TestReconcileUseFinalizer(t *testing.T) {
+	tests := []struct {
+		name          string
+		useFinalizer  *bool
+		hasFinalizer  bool
+		wantFinalizer bool
+	}{
+		{name: "default", wantFinalizer: true},
+		{name: "enabled", useFinalizer: utils.GetBoolPtr(true), wantFinalizer: true},
+		{name: "disabled", useFinalizer: utils.GetBoolPtr(false)},
+		{name: "disabled after creation", useFinalizer: utils.GetBoolPtr(false), hasFinalizer: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			strategy := rbacoperatorv1.OwnerReferenceConfig
+			config := testConfig("cfg")
+			config.Spec.Config = &rbacoperatorv1.NamespaceRBACConfigConfig{
+				OwnerReferenceStrategy: &strategy,
+				Cleanup:                &rbacoperatorv1.CleanupConfig{UseFinalizer: tt.useFinalizer},
+			}
+			if tt.hasFinalizer {
+				controllerutil.AddFinalizer(config, FinalizerName)
+			}
+			r, c := newTestReconciler(t, rbac.Options{}, interceptor.Funcs{},
+				config, testNamespace("team-a", map[string]string{"team": "a"}))
+
+			stored := reconcileConfig(t, r, "cfg")
+			if got := controllerutil.ContainsFinalizer(stored, FinalizerName); got != tt.wantFinalizer {
+				t.Fatalf("finalizer present = %v, want %v", got, tt.wantFinalizer)
+			}
+			if err := c.Get(context.Background(), types.NamespacedName{Namespace: "team-a", Name: "viewer"}, &rbacv1.Role{}); err != nil {
+				t.Fatalf("role not applied: %v", err)
+			}
+
+			// Without the finalizer deletion is not held for cleanup
+			if err := c.Delete(context.Background(), stored); err != nil {
+				t.Fatal(err)
+			}
+			err := c.Get(context.Background(), types.NamespacedName{Name: "cfg"}, &rbacoperatorv1.NamespaceRBACConfig{})
+			if gone := errors.IsNotFound(err); gone == tt.wantFinalizer {
+				t.Errorf("config deleted immediately = %v, want %v (err %v)", gone, !tt.wantFinalizer, err)
+			}
+		})
+	}
+}
+
+func TestReconcileRendersMatchNamespaceCount(t *testing.T) {
+	tests := []struct {
+		name       string
+		namespaces []string
+		want       string
+	}{
+		{name: "one namespace", namespaces: []string{"team-0"}, want: "shared by 1 namespaces"},
+		{name: "two namespaces", namespaces: []string{"team-0", "team-1"}, want: "shared by 2 namespaces"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := testConfig("cfg")
+			config.Spec.RBACTemplates.ClusterRoles = []rbacoperatorv1.ClusterRoleTemplate{{
+				Name:        "shared-viewer",
+				Rules:       []rbacv1.PolicyRule{{APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: []string{"get"}}},
+				Annotations: map[string]string{"description": "shared by {{ .Match.NamespaceCount }} namespaces"},
+			}}
+			objs := []client.Object{config, testNamespace("other", map[string]string{"team": "b"})}
+			for _, name := range tt.namespaces {
+				objs = append(objs, testNamespace(name, map[string]string{"team": "a"}))
+			}
+			r, c := newTestReconciler(t, rbac.Options{}, interceptor.Funcs{}, objs...)
+
+			reconcileConfig(t, r, "cfg")
+
+			clusterRole := &rbacv1.ClusterRole{}
+			if err := c.Get(context.Background(), types.NamespacedName{Name: "shared-viewer"}, clusterRole); err != nil {
+				t.Fatal(err)
+			}
+			if got := clusterRole.Annotations["description"]; got != tt.want {
+				t.Errorf("description annotation = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReconcileDegradesAfterGracePeriod(t *testing.T) {
+	type step struct {
+		fail          bool
+		elapsed       time.Duration // Backdates the start of the failure run before reconciling
+		wantDegraded  metav1.ConditionStatus
+		wantReason    string // Progressing reason
+		wantUnhealthy bool
+	}
+	apiUnavailable := &meta.NoKindMatchError{GroupKind: schema.GroupKind{Group: rbacv1.GroupName, Kind: "Role"}, SearchedVersions: []string{"v1"}}
+	tests := []struct {
+		name          string
+		err           error  // Returned for Role reads while a step fails
+		failNamespace string // Limits failures to this namespace; empty fails every namespace
+		grace         time.Duration
+		steps         []step
+	}{
+		{
+			name:  "transient error",
+			err:   apiUnavailable,
+			grace: time.Minute,
+			steps: []step{
+				{fail: true, wantDegraded: metav1.ConditionFalse, wantReason: ReasonRetrying},
+				{wantDegraded: metav1.ConditionFalse, wantReason: ReasonReconcileSuccess},
+			},
+		},
+		{
+			name:  "sustained error",
+			err:   apiUnavailable,
+			grace: time.Minute,
+			steps: []step{
+				{fail: true, wantDegraded: metav1.ConditionFalse, wantReason: ReasonRetrying},
+				{fail: true, elapsed: 30 * time.Second, wantDegraded: metav1.ConditionFalse, wantReason: ReasonRetrying},
+				{fail: true, elapsed: time.Minute, wantDegraded: metav1.ConditionTrue, wantReason: ReasonReconcileError, wantUnhealthy: true},
+				{wantDegraded: metav1.ConditionFalse, wantReason: ReasonReconcileSuccess},
+			},
+		},
+		{
+			name: "no grace period",
+			err:  apiUnavailable,
+			steps: []step{
+				{fail: true, wantDegraded: metav1.ConditionTrue, wantReason: ReasonReconcileError, wantUnhealthy: true},
+			},
+		},
+		{
+			// A single namespace failing is a partial failure: the rest of the RBAC was
+			// applied, so only Degraded is deferred and health is left alone
+			name:          "sustained namespace error",
+			err:           errors.NewInternalError(fmt.Errorf("etcd leader changed")),
+			failNamespace: "team-b",
+			grace:         time.Minute,
+			steps: []step{
+				{fail: true, wantDegraded: metav1.ConditionFalse, wantReason: ReasonRetrying},
+				{fail: true, elapsed: time.Minute, wantDegraded: metav1.ConditionTrue, wantReason: ReasonReconcileSuccess},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			failing := false
+			r, _ := newTestReconciler(t, rbac.Options{}, interceptor.Funcs{
+				Get: func(ctx context.Context, c client.WithWatch, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+					if _, ok := obj.(*rbacv1.Role); ok && failing && (tt.failNamespace == "" || key.Namespace == tt.failNamespace) {
+						return tt.err
+					}
+					return c.Get(ctx, key, obj, opts...)
+				},
+			}, testConfig("cfg"),
+				testNamespace("team-a", map[string]string{"team": "a"}),
+				testNamespace("team-b", map[string]string{"team": "a"}))
+			r.DegradedGracePeriod = tt.grace
+			reconcileConfig(t, r, "cfg")
+
+			req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "cfg"}}
+			for i, step := range tt.steps {
+				failing = step.fail
+				if step.elapsed > 0 {
+					r.failingSinceMu.Lock()
+					r.failingSince["cfg"] = time.Now().Add(-step.elapsed)
+					r.failingSinceMu.Unlock()
+				}
+				result, err := r.Reconcile(context.Background(), req)
+				if err != nil {
+					t.Fatalf("step %d: Reconcile: %v", i, err)
+				}
+
+				config := &rbacoperatorv1.NamespaceRBACConfig{}
+				if err := r.Get(context.Background(), req.NamespacedName, config); err != nil {
+					t.Fatal(err)
+				}
+				if got := meta.FindStatusCondition(config.Status.Conditions, ConditionTypeDegraded); got == nil || got.Status != step.wantDegraded {
+					t.Errorf("step %d: Degraded = %+v, want %s", i, got, step.wantDegraded)
+				}
+				if got := meta.FindStatusCondition(config.Status.Conditions, ConditionTypeProgressing); got == nil || got.Reason != step.wantReason {
+					t.Errorf("step %d: Progressing = %+v, want reason %s", i, got, step.wantReason)
+				}
+				if unhealthy := !r.healthChecker.IsHealthy(); unhealthy != step.wantUnhealthy {
+					t.Errorf("step %d: unhealthy = %v, want %v", i, unhealthy, step.wantUnhealthy)
+				}
+				if step.wantReason == ReasonRetrying && (result.RequeueAfter <= 0 || result.RequeueAfter > tt.grace-step.elapsed) {
+					t.Errorf("step %d: RequeueAfter = %s, want within the remaining grace period %s", i, result.RequeueAfter, tt.grace-step.elapsed)
+				}
+			}
+		})
+	}
+}
+
+func TestReconcileReportsAllValidationErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		mutate   func(config *rbacoperatorv1.NamespaceRBACConfig)
+		wantErrs []string
+	}{
+		{
+			name:   "valid",
+			mutate: func(config *rbacoperatorv1.NamespaceRBACConfig) {},
+		},
+		{
+			name: "duplicate name",
+			mutate: func(config *rbacoperatorv1.NamespaceRBACConfig) {
+				config.Spec.RBACTemplates.Roles = append(config.Spec.RBACTemplates.Roles, config.Spec.RBACTemplates.Roles[0])
+			},
+			wantErrs: []string{"spec.rbacTemplates.roles[1].name"},
+		},
+		{
+			name: "errors across the spec",
+			mutate: func(config *rbacoperatorv1.NamespaceRBACConfig) {
+				strategy := rbacoperatorv1.MergeStrategy("overwrite")
+				config.Spec.RBACTemplates.RoleBindings[0].Subjects[0].Kind = "Team"
+				config.Spec.Config = &rbacoperatorv1.NamespaceRBACConfigConfig{
+					MergeStrategy: &strategy,
+					Hooks:         &rbacoperatorv1.HooksConfig{PostApplyURL: "/rbac"},
+				}
+			},
+			wantErrs: []string{
+				"spec.rbacTemplates.roleBindings[0].subjects[0].kind",
+				"spec.config.mergeStrategy",
+				"spec.config.hooks.postApplyURL",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := testConfig("cfg")
+			tt.mutate(config)
+			r, _ := newTestReconciler(t, rbac.Options{}, interceptor.Funcs{},
+				config, testNamespace("team-a", map[string]string{"team": "a"}))
+
+			stored := reconcileConfig(t, r, "cfg")
+			degraded := meta.FindStatusCondition(stored.Status.Conditions, ConditionTypeDegraded)
+			if len(tt.wantErrs) == 0 {
+				if degraded == nil || degraded.Reason == ReasonValidationError {
+					t.Fatalf("Degraded = %+v, want no validation error", degraded)
+				}
+				return
+			}
+			if degraded == nil || degraded.Status != metav1.ConditionTrue || degraded.Reason != ReasonValidationError {
+				t.Fatalf("Degraded = %+v, want True/%s", degraded, ReasonValidationError)
+			}
+			lines := strings.Split(degraded.Message, "\n")
+			if header := fmt.Sprintf("%d validation error(s):", len(tt.wantErrs)); lines[0] != header {
+				t.Errorf("message header = %q, want %q", lines[0], header)
+			}
+			if len(lines) != len(tt.wantErrs)+1 {
+				t.Fatalf("message has %d error lines, want %d:\n%s", len(lines)-1, len(tt.wantErrs), degraded.Message)
+			}
+			for _, path := range tt.wantErrs {
+				if !strings.Contains(degraded.Message, "- "+path+": ") {
+					t.Errorf("message does not list %s:\n%s", path, degraded.Message)
+				}
+			}
+		})
+	}
+}
+
+func TestReconcileFailsWhenEveryNamespaceFails(t *testing.T) {
+	tests := []struct {
+		name         string
+		failing      []string
+		waiting      []string
+		wantErr      bool
+		wantFailures int // Consecutive failures counted by the circuit breaker
+	}{
+		{name: "one namespace failing", failing: []string{"team-1"}},
+		{name: "every namespace failing", failing: []string{"team-0", "team-1"}, wantErr: true, wantFailures: 1},
+		{name: "every ready namespace failing", failing: []string{"team-1"}, waiting: []string{"team-0"}, wantErr: true, wantFailures: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			const annotation = "example.com/rbac-ready"
+			config := testConfig("cfg")
+			config.Spec.Config = &rbacoperatorv1.NamespaceRBACConfigConfig{WaitForNamespaceAnnotation: annotation}
+			objs := []client.Object{config}
+			for _, name := range []string{"team-0", "team-1"} {
+				ns := testNamespace(name, map[string]string{"team": "a"})
+				if !utils.SliceContains(tt.waiting, name) {
+					ns.Annotations = map[string]string{annotation: "true"}
+				}
+				objs = append(objs, ns)
+			}
+			r, _ := newTestReconciler(t, rbac.Options{}, interceptor.Funcs{
+				Get: func(ctx context.Context, c client.WithWatch, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+					if _, ok := obj.(*rbacv1.Role); ok && utils.SliceContains(tt.failing, key.Namespace) {
+						return errors.NewInternalError(fmt.Errorf("etcd unavailable"))
+					}
+					return c.Get(ctx, key, obj, opts...)
+				},
+			}, objs...)
+
+			stored := reconcileConfig(t, r, "cfg")
+
+			partial := meta.FindStatusCondition(stored.Status.Conditions, ConditionTypePartiallyApplied)
+			if gotPartial := partial != nil && partial.Status == metav1.ConditionTrue; gotPartial == tt.wantErr {
+				t.Errorf("%s = %+v, want True only when some namespaces applied", ConditionTypePartiallyApplied, partial)
+			}
+			degraded := meta.FindStatusCondition(stored.Status.Conditions, ConditionTypeDegraded)
+			if gotErr := degraded != nil && degraded.Reason == ReasonReconcileError; gotErr != tt.wantErr {
+				t.Errorf("Degraded = %+v, want reason %s = %v", degraded, ReasonReconcileError, tt.wantErr)
+			}
+			r.circuitsMu.Lock()
+			failures := 0
+			if state, ok := r.circuits["cfg"]; ok {
+				failures = state.failures
+			}
+			r.circuitsMu.Unlock()
+			if failures != tt.wantFailures {
+				t.Errorf("circuit breaker failures = %d, want %d", failures, tt.wantFailures)
+			}
+		})
+	}
+}
+
+func TestReconcileClearsWaitingWhenGatingDisabled(t *testing.T) {
+	tests := []struct {
+		name       string
+		annotation string // WaitForNamespaceAnnotation after the update
+		wantStatus metav1.ConditionStatus
+	}{
+		{name: "gating removed"},
+		{name: "gating kept", annotation: "example.com/rbac-ready", wantStatus: metav1.ConditionTrue},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := testConfig("cfg")
+			config.Spec.Config = &rbacoperatorv1.NamespaceRBACConfigConfig{WaitForNamespaceAnnotation: "example.com/rbac-ready"}
+			r, c := newTestReconciler(t, rbac.Options{}, interceptor.Funcs{},
+				config, testNamespace("team-0", map[string]string{"team": "a"}))
+
+			stored := reconcileConfig(t, r, "cfg")
+			if !meta.IsStatusConditionTrue(stored.Status.Conditions, ConditionTypeWaitingForNamespaces) {
+				t.Fatalf("%s not True while the namespace lacks the annotation", ConditionTypeWaitingForNamespaces)
+			}
+
+			stored.Spec.Config.WaitForNamespaceAnnotation = tt.annotation
+			if err := c.Update(context.Background(), stored); err != nil {
+				t.Fatal(err)
+			}
+			result, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: types.NamespacedName{Name: "cfg"}})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := c.Get(context.Background(), types.NamespacedName{Name: "cfg"}, stored); err != nil {
+				t.Fatal(err)
+			}
+
+			cond := meta.FindStatusCondition(stored.Status.Conditions, ConditionTypeWaitingForNamespaces)
+			switch {
+			case tt.wantStatus == "" && cond != nil:
+				t.Errorf("%s = %+v, want it removed", ConditionTypeWaitingForNamespaces, cond)
+			case tt.wantStatus != "" && (cond == nil || cond.Status != tt.wantStatus):
+				t.Errorf("%s = %+v, want %s", ConditionTypeWaitingForNamespaces, cond, tt.wantStatus)
+			}
+			if polling := result.RequeueAfter == NamespaceReadinessRequeueInterval; polling != (tt.wantStatus == metav1.ConditionTrue) {
+				t.Errorf("RequeueAfter = %v, want polling %v", result.RequeueAfter, tt.wantStatus == metav1.ConditionTrue)
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package namespacerbacconfig
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	"github.com/cropalato/k8s-acl-operator/pkg/rbac"
+	"github.com/cropalato/k8s-acl-operator/pkg/tracing"
+)
+
+func TestReconcileCreatesSpans(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)))
+	defer otel.SetTracerProvider(previous)
+
+	r, _ := newTestReconciler(t, rbac.Options{}, interceptor.Funcs{},
+		testConfig("cfg"), testNamespace("team-0", map[string]string{"team": "a"}))
+	// Add the finalizer first so the traced reconcile is a single steady-state pass
+	reconcileConfig(t, r, "cfg")
+	exporter.Reset()
+	if _, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: types.NamespacedName{Name: "cfg"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	spans := exporter.GetSpans()
+	var names []string
+	for _, span := range spans {
+		names = append(names, span.Name)
+		if span.SpanContext.TraceID() != spans[0].SpanContext.TraceID() {
+			t.Errorf("span %s is not part of the reconcile trace", span.Name)
+		}
+	}
+	sort.Strings(names)
+	wantNames := []string{
+		"NamespaceRBACConfig.Reconcile",
+		"NamespaceRBACConfig.reconcileRBAC",
+		"rbac.ApplyRBACForNamespace",
+		"rbac.applyRole",
+		"rbac.applyRoleBinding",
+	}
+	if !reflect.DeepEqual(names, wantNames) {
+		t.Fatalf("span names = %v, want %v", names, wantNames)
+	}
+
+	tests := []struct {
+		span string
+		want []attribute.KeyValue
+	}{
+		{span: "NamespaceRBACConfig.Reconcile", want: []attribute.KeyValue{tracing.ConfigKey.String("cfg")}},
+		{span: "NamespaceRBACConfig.reconcileRBAC", want: []attribute.KeyValue{tracing.ConfigKey.String("cfg")}},
+		{span: "rbac.ApplyRBACForNamespace", want: []attribute.KeyValue{tracing.ConfigKey.String("cfg"), tracing.NamespaceKey.String("team-0")}},
+		{span: "rbac.applyRole", want: []attribute.KeyValue{tracing.ConfigKey.String("cfg"), tracing.NamespaceKey.String("team-0"), tracing.KindKey.String("Role")}},
+		{span: "rbac.applyRoleBinding", want: []attribute.KeyValue{tracing.ConfigKey.String("cfg"), tracing.NamespaceKey.String("team-0"), tracing.KindKey.String("RoleBinding")}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.span, func(t *testing.T) {
+			for _, span := range spans {
+				if span.Name == tt.span {
+					if !reflect.DeepEqual(span.Attributes, tt.want) {
+						t.Errorf("attributes = %v, want %v", span.Attributes, tt.want)
+					}
+					return
+				}
+			}
+			t.Errorf("no %s span", tt.span)
+		})
+	}
+}
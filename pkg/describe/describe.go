@@ -0,0 +1,88 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package describe formats NamespaceRBACConfig status for humans, in the style of
+// kubectl describe. It holds no client logic so it can back a CLI or plugin.
+package describe
+
+import (
+	"fmt"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	rbacoperatorv1 "github.com/cropalato/k8s-acl-operator/pkg/apis/rbac/v1"
+)
+
+// Describe returns a human-readable summary of the config's conditions, applied
+// namespaces and created resource counts
+func Describe(config *rbacoperatorv1.NamespaceRBACConfig) string {
+	var b strings.Builder
+	w := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintf(w, "Name:\t%s\n", config.Name)
+	fmt.Fprintf(w, "Generation:\t%d (observed %d)\n", config.Generation, config.Status.ObservedGeneration)
+	suspended := config.Spec.Suspend != nil && *config.Spec.Suspend
+	fmt.Fprintf(w, "Suspended:\t%t\n", suspended)
+
+	fmt.Fprintf(w, "Conditions:\n")
+	if len(config.Status.Conditions) == 0 {
+		fmt.Fprintf(w, "  <none>\n")
+	} else {
+		fmt.Fprintf(w, "  Type\tStatus\tReason\tLast Transition\tMessage\n")
+		for _, c := range config.Status.Conditions {
+			fmt.Fprintf(w, "  %s\t%s\t%s\t%s\t%s\n",
+				c.Type, c.Status, c.Reason, c.LastTransitionTime.UTC().Format(time.RFC3339), c.Message)
+		}
+	}
+
+	// A bounded list already ends with its "… and N more" summary entry
+	count := config.Status.ManagedResourceCount
+	if count < len(config.Status.AppliedNamespaces) {
+		count = len(config.Status.AppliedNamespaces)
+	}
+	fmt.Fprintf(w, "Applied Namespaces (%d):\n", count)
+	if count == 0 {
+		fmt.Fprintf(w, "  <none>\n")
+	}
+	for _, ns := range config.Status.AppliedNamespaces {
+		fmt.Fprintf(w, "  %s\n", ns)
+	}
+
+	created := config.Status.CreatedResources
+	if created == nil {
+		created = &rbacoperatorv1.CreatedResources{}
+	}
+	fmt.Fprintf(w, "Created Resources:\n")
+	fmt.Fprintf(w, "  Roles:\t%d\n", len(created.Roles))
+	fmt.Fprintf(w, "  ClusterRoles:\t%d\n", len(created.ClusterRoles))
+	fmt.Fprintf(w, "  RoleBindings:\t%d\n", len(created.RoleBindings))
+	fmt.Fprintf(w, "  ClusterRoleBindings:\t%d\n", len(created.ClusterRoleBindings))
+
+	if len(config.Status.RecentErrors) > 0 {
+		fmt.Fprintf(w, "Recent Errors:\n")
+		for _, e := range config.Status.RecentErrors {
+			namespace := e.Namespace
+			if namespace == "" {
+				namespace = "-"
+			}
+			fmt.Fprintf(w, "  %s\t%s\t%s\n", e.Timestamp.UTC().Format(time.RFC3339), namespace, e.Message)
+		}
+	}
+
+	w.Flush()
+	return b.String()
+}
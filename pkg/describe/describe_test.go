@@ -0,0 +1,107 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package describe
+
+import (
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	rbacoperatorv1 "github.com/cropalato/k8s-acl-operator/pkg/apis/rbac/v1"
+)
+
+func TestDescribe(t *testing.T) {
+	at := metav1.NewTime(time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC))
+	suspend := true
+
+	tests := []struct {
+		name   string
+		config *rbacoperatorv1.NamespaceRBACConfig
+		want   string
+	}{
+		{
+			name:   "empty status",
+			config: &rbacoperatorv1.NamespaceRBACConfig{ObjectMeta: metav1.ObjectMeta{Name: "cfg", Generation: 1}},
+			want: `Name:        cfg
+Generation:  1 (observed 0)
+Suspended:   false
+Conditions:
+  <none>
+Applied Namespaces (0):
+  <none>
+Created Resources:
+  Roles:                0
+  ClusterRoles:         0
+  RoleBindings:         0
+  ClusterRoleBindings:  0
+`,
+		},
+		{
+			name: "conditions, namespaces and errors",
+			config: &rbacoperatorv1.NamespaceRBACConfig{
+				ObjectMeta: metav1.ObjectMeta{Name: "team-rbac", Generation: 3},
+				Spec:       rbacoperatorv1.NamespaceRBACConfigSpec{Suspend: &suspend},
+				Status: rbacoperatorv1.NamespaceRBACConfigStatus{
+					ObservedGeneration: 2,
+					Conditions: []metav1.Condition{
+						{Type: "Ready", Status: metav1.ConditionTrue, Reason: "ReconcileSuccess", Message: "All namespaces applied", LastTransitionTime: at},
+						{Type: "Degraded", Status: metav1.ConditionFalse, Reason: "NoErrors", Message: "No errors", LastTransitionTime: at},
+					},
+					AppliedNamespaces:    []string{"team-a", "team-b", "… and 3 more"},
+					ManagedResourceCount: 5,
+					CreatedResources: &rbacoperatorv1.CreatedResources{
+						Roles:        []rbacoperatorv1.ResourceReference{{Namespace: "team-a", Name: "viewer"}, {Namespace: "team-b", Name: "viewer"}},
+						ClusterRoles: []string{"viewer-shared"},
+					},
+					RecentErrors: []rbacoperatorv1.ErrorRecord{
+						{Timestamp: at, Namespace: "team-c", Message: "roles \"viewer\" is forbidden"},
+						{Timestamp: at, Message: "failed to list namespaces"},
+					},
+				},
+			},
+			want: `Name:        team-rbac
+Generation:  3 (observed 2)
+Suspended:   true
+Conditions:
+  Type      Status  Reason            Last Transition       Message
+  Ready     True    ReconcileSuccess  2024-05-01T12:00:00Z  All namespaces applied
+  Degraded  False   NoErrors          2024-05-01T12:00:00Z  No errors
+Applied Namespaces (5):
+  team-a
+  team-b
+  … and 3 more
+Created Resources:
+  Roles:                2
+  ClusterRoles:         1
+  RoleBindings:         0
+  ClusterRoleBindings:  0
+Recent Errors:
+  2024-05-01T12:00:00Z  team-c  roles "viewer" is forbidden
+  2024-05-01T12:00:00Z  -       failed to list namespaces
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Describe(tt.config); got != tt.want {
+				t.Errorf("Describe() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
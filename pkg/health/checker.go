@@ -16,6 +16,8 @@ type Checker struct {
 	healthy       int32
 	lastReconcile int64
 	logger        logr.Logger
+	// apiUnavailable holds why a required API is missing; empty when all are served
+	apiUnavailable atomic.Value
 }
 
 // NewChecker creates a health checker
@@ -58,6 +60,16 @@ func (c *Checker) RecordReconcile() {
 	metrics.SetOperatorHealth("health_checker", true)
 }
 
+// SetAPIUnavailable records that an API the operator depends on is not served, which
+// keeps the operator unready. An empty reason clears it.
+func (c *Checker) SetAPIUnavailable(reason string) {
+	c.apiUnavailable.Store(reason)
+	metrics.SetOperatorHealth("api_discovery", reason == "")
+	if reason != "" {
+		c.logger.Info("Required API unavailable, operator will not become ready", "reason", reason)
+	}
+}
+
 // IsReady returns readiness state
 func (c *Checker) IsReady() bool {
 	return atomic.LoadInt32(&c.ready) == 1
@@ -89,6 +101,9 @@ func (c *Checker) LivenessCheck(req *http.Request) error {
 
 // ReadinessCheck implements readyz check
 func (c *Checker) ReadinessCheck(req *http.Request) error {
+	if reason, _ := c.apiUnavailable.Load().(string); reason != "" {
+		return fmt.Errorf("required API unavailable: %s", reason)
+	}
 	if !c.IsReady() || !c.IsHealthy() {
 		return fmt.Errorf("operator not ready")
 	}
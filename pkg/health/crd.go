@@ -0,0 +1,123 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package health
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/cropalato/k8s-acl-operator/pkg/metrics"
+)
+
+// crdGVK identifies CustomResourceDefinition objects; they are read as unstructured
+// to avoid depending on the apiextensions API types
+var crdGVK = schema.GroupVersionKind{Group: "apiextensions.k8s.io", Version: "v1", Kind: "CustomResourceDefinition"}
+
+// +kubebuilder:rbac:groups=apiextensions.k8s.io,resources=customresourcedefinitions,verbs=get
+
+// CacheSyncWaiter blocks until informer caches are synced, e.g. mgr.GetCache()
+type CacheSyncWaiter interface {
+	WaitForCacheSync(ctx context.Context) bool
+}
+
+// CRDWaiter is a manager runnable that marks the operator ready only once the
+// given CRD is established and the caches are synced, so reconciles don't start
+// against a missing API or act on a partial view of the cluster
+type CRDWaiter struct {
+	Reader   client.Reader   // Uncached reader, e.g. mgr.GetAPIReader()
+	Cache    CacheSyncWaiter // Waited on after the CRD; nil skips the cache sync wait
+	Checker  *Checker        // Marked ready once the CRD is established and caches synced
+	CRDName  string          // e.g. namespacerbacconfigs.rbac.operator.io
+	Interval time.Duration   // Poll interval
+	Timeout  time.Duration   // Maximum time to wait before failing startup
+}
+
+// Start waits for the CRD and the caches, then marks the checker ready. It returns an
+// error if the CRD is not established within the timeout or the caches never sync,
+// which stops the manager.
+func (w *CRDWaiter) Start(ctx context.Context) error {
+	if err := w.Wait(ctx); err != nil {
+		return err
+	}
+	if err := w.waitForCacheSync(ctx); err != nil {
+		return err
+	}
+	w.Checker.SetReady(true)
+	return nil
+}
+
+// waitForCacheSync blocks until the caches are synced, recording the state as a metric
+func (w *CRDWaiter) waitForCacheSync(ctx context.Context) error {
+	if w.Cache == nil {
+		return nil
+	}
+	metrics.SetCacheSynced(false)
+	w.Checker.logger.Info("Waiting for caches to sync")
+	if !w.Cache.WaitForCacheSync(ctx) {
+		return fmt.Errorf("caches did not sync: %w", ctx.Err())
+	}
+	metrics.SetCacheSynced(true)
+	w.Checker.logger.Info("Caches synced")
+	return nil
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable; readiness must be
+// reported by every replica, not just the leader
+func (w *CRDWaiter) NeedLeaderElection() bool {
+	return false
+}
+
+// Wait polls until the CRD reports an Established=True condition or the timeout expires
+func (w *CRDWaiter) Wait(ctx context.Context) error {
+	w.Checker.logger.Info("Waiting for CRD to be established", "crd", w.CRDName)
+	err := wait.PollUntilContextTimeout(ctx, w.Interval, w.Timeout, true, func(ctx context.Context) (bool, error) {
+		crd := &unstructured.Unstructured{}
+		crd.SetGroupVersionKind(crdGVK)
+		if err := w.Reader.Get(ctx, types.NamespacedName{Name: w.CRDName}, crd); err != nil {
+			// Not found or transient API errors: keep polling until timeout
+			return false, nil
+		}
+		return isEstablished(crd), nil
+	})
+	if err != nil {
+		return fmt.Errorf("CRD %s not established within %s: %w", w.CRDName, w.Timeout, err)
+	}
+	w.Checker.logger.Info("CRD established", "crd", w.CRDName)
+	return nil
+}
+
+// isEstablished reports whether the CRD has an Established condition with status True
+func isEstablished(crd *unstructured.Unstructured) bool {
+	conditions, _, _ := unstructured.NestedSlice(crd.Object, "status", "conditions")
+	for _, c := range conditions {
+		condition, ok := c.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if condition["type"] == "Established" && condition["status"] == "True" {
+			return true
+		}
+	}
+	return false
+}
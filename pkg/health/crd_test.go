@@ -0,0 +1,166 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package health
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	"github.com/cropalato/k8s-acl-operator/pkg/metrics"
+)
+
+const testCRDName = "namespacerbacconfigs.rbac.operator.io"
+
+// testCRD returns a CRD with an Established condition of the given status
+func testCRD(established string) *unstructured.Unstructured {
+	crd := &unstructured.Unstructured{}
+	crd.SetGroupVersionKind(crdGVK)
+	crd.SetName(testCRDName)
+	_ = unstructured.SetNestedSlice(crd.Object, []interface{}{
+		map[string]interface{}{"type": "NamesAccepted", "status": "True"},
+		map[string]interface{}{"type": "Established", "status": established},
+	}, "status", "conditions")
+	return crd
+}
+
+// syncedCache is a CacheSyncWaiter reporting a fixed result
+type syncedCache bool
+
+func (s syncedCache) WaitForCacheSync(context.Context) bool {
+	return bool(s)
+}
+
+func TestCRDWaiterStart(t *testing.T) {
+	tests := []struct {
+		name         string
+		crd          *unstructured.Unstructured
+		missingPolls int // Gets answered NotFound before the stored CRD is returned
+		cache        CacheSyncWaiter
+		wantErr      bool
+		wantReady    bool
+	}{
+		{name: "established", crd: testCRD("True"), wantReady: true},
+		{name: "established after polling", crd: testCRD("True"), missingPolls: 2, wantReady: true},
+		{name: "not established", crd: testCRD("False"), wantErr: true},
+		{name: "missing", wantErr: true},
+		{name: "caches synced", crd: testCRD("True"), cache: syncedCache(true), wantReady: true},
+		{name: "caches never sync", crd: testCRD("True"), cache: syncedCache(false), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			builder := fake.NewClientBuilder().WithScheme(runtime.NewScheme())
+			if tt.crd != nil {
+				builder = builder.WithObjects(tt.crd)
+			}
+			gets := 0
+			reader := builder.WithInterceptorFuncs(interceptor.Funcs{
+				Get: func(ctx context.Context, c client.WithWatch, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+					gets++
+					if gets <= tt.missingPolls {
+						return errors.NewNotFound(crdGVK.GroupVersion().WithResource("customresourcedefinitions").GroupResource(), key.Name)
+					}
+					return c.Get(ctx, key, obj, opts...)
+				},
+			}).Build()
+
+			checker := NewChecker(logr.Discard())
+			waiter := &CRDWaiter{
+				Reader:   reader,
+				Cache:    tt.cache,
+				Checker:  checker,
+				CRDName:  testCRDName,
+				Interval: time.Millisecond,
+				Timeout:  50 * time.Millisecond,
+			}
+
+			err := waiter.Start(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Start() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if checker.IsReady() != tt.wantReady {
+				t.Errorf("IsReady() = %v, want %v", checker.IsReady(), tt.wantReady)
+			}
+			if tt.missingPolls > 0 && gets <= tt.missingPolls {
+				t.Errorf("CRD read %d times, want more than %d", gets, tt.missingPolls)
+			}
+		})
+	}
+}
+
+// gatedCache is a CacheSyncWaiter that signals entered and syncs once released
+type gatedCache struct {
+	entered chan struct{}
+	release chan struct{}
+}
+
+func (g gatedCache) WaitForCacheSync(ctx context.Context) bool {
+	close(g.entered)
+	select {
+	case <-g.release:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+func TestCRDWaiterGatesReadinessOnCacheSync(t *testing.T) {
+	reader := fake.NewClientBuilder().WithScheme(runtime.NewScheme()).WithObjects(testCRD("True")).Build()
+	checker := NewChecker(logr.Discard())
+	cache := gatedCache{entered: make(chan struct{}), release: make(chan struct{})}
+	waiter := &CRDWaiter{
+		Reader:   reader,
+		Cache:    cache,
+		Checker:  checker,
+		CRDName:  testCRDName,
+		Interval: time.Millisecond,
+		Timeout:  time.Second,
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- waiter.Start(context.Background()) }()
+
+	// The CRD is established at once; readiness waits for the caches
+	<-cache.entered
+	if checker.IsReady() {
+		t.Error("ready before caches synced")
+	}
+	if got := testutil.ToFloat64(metrics.CacheSynced); got != 0 {
+		t.Errorf("rbac_operator_cache_synced = %v while waiting, want 0", got)
+	}
+
+	close(cache.release)
+	if err := <-done; err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	if !checker.IsReady() {
+		t.Error("not ready after caches synced")
+	}
+	if got := testutil.ToFloat64(metrics.CacheSynced); got != 1 {
+		t.Errorf("rbac_operator_cache_synced = %v, want 1", got)
+	}
+}
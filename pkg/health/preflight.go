@@ -0,0 +1,158 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package health
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/cropalato/k8s-acl-operator/pkg/metrics"
+)
+
+// +kubebuilder:rbac:groups=authorization.k8s.io,resources=selfsubjectaccessreviews,verbs=create
+
+// SelfSubjectAccessReviewer creates SelfSubjectAccessReviews; the typed client-go
+// AuthorizationV1().SelfSubjectAccessReviews() client satisfies it
+type SelfSubjectAccessReviewer interface {
+	Create(ctx context.Context, review *authorizationv1.SelfSubjectAccessReview, opts metav1.CreateOptions) (*authorizationv1.SelfSubjectAccessReview, error)
+}
+
+// Permission is a cluster-wide permission checked by the preflight
+type Permission struct {
+	Group    string
+	Resource string
+	Verb     string
+	Critical bool // Missing critical permissions fail the preflight; others are only reported
+}
+
+// String formats the permission as verb group/resource
+func (p Permission) String() string {
+	if p.Group == "" {
+		return p.Verb + " " + p.Resource
+	}
+	return p.Verb + " " + p.Group + "/" + p.Resource
+}
+
+// RequiredPermissions are the permissions checked by default. Reading namespaces and
+// managing RBAC resources and ServiceAccounts are critical; patch, used for namespace
+// metadata and the managed-by annotation, and escalate/bind, without which grants beyond
+// the operator's own permissions are denied, are only reported.
+var RequiredPermissions = requiredPermissions()
+
+func requiredPermissions() []Permission {
+	permissions := []Permission{
+		{Resource: "namespaces", Verb: "get", Critical: true},
+		{Resource: "namespaces", Verb: "list", Critical: true},
+		{Resource: "namespaces", Verb: "watch", Critical: true},
+		{Resource: "namespaces", Verb: "patch"},
+	}
+	for _, resource := range rbacResources {
+		for _, verb := range []string{"get", "list", "watch", "create", "update", "delete"} {
+			permissions = append(permissions, Permission{Group: "rbac.authorization.k8s.io", Resource: resource, Verb: verb, Critical: true})
+		}
+		permissions = append(permissions, Permission{Group: "rbac.authorization.k8s.io", Resource: resource, Verb: "patch"})
+	}
+	// ServiceAccount templates are managed like the RBAC resources they are bound by
+	for _, verb := range []string{"get", "list", "watch", "create", "update", "delete"} {
+		permissions = append(permissions, Permission{Resource: "serviceaccounts", Verb: verb, Critical: true})
+	}
+	permissions = append(permissions, Permission{Resource: "serviceaccounts", Verb: "patch"})
+	for _, resource := range []string{"roles", "clusterroles"} {
+		permissions = append(permissions,
+			Permission{Group: "rbac.authorization.k8s.io", Resource: resource, Verb: "escalate"},
+			Permission{Group: "rbac.authorization.k8s.io", Resource: resource, Verb: "bind"})
+	}
+	return permissions
+}
+
+// PermissionResult is the outcome of one preflight check
+type PermissionResult struct {
+	Permission
+	Allowed bool
+	Reason  string // Authorizer's explanation, if any
+}
+
+// Preflight is a manager runnable that verifies, through SelfSubjectAccessReviews, that
+// the operator holds the permissions it needs. Results are logged and exported as
+// rbac_operator_preflight_permission_allowed; a missing critical permission stops the manager.
+type Preflight struct {
+	Reviews     SelfSubjectAccessReviewer // e.g. clientset.AuthorizationV1().SelfSubjectAccessReviews()
+	Checker     *Checker                  // Provides the logger
+	Permissions []Permission              // Permissions to check; defaults to RequiredPermissions
+}
+
+// Start runs the checks and returns an error when critical permissions are missing
+func (p *Preflight) Start(ctx context.Context) error {
+	_, err := p.Run(ctx)
+	return err
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable; every replica checks its
+// own permissions so a misconfigured standby fails before it takes over
+func (p *Preflight) NeedLeaderElection() bool {
+	return false
+}
+
+// Run checks every permission and returns the results. The error lists the missing
+// critical permissions, or reports a review that could not be created.
+func (p *Preflight) Run(ctx context.Context) ([]PermissionResult, error) {
+	permissions := p.Permissions
+	if permissions == nil {
+		permissions = RequiredPermissions
+	}
+
+	results := make([]PermissionResult, 0, len(permissions))
+	missing := make([]string, 0)
+	for _, permission := range permissions {
+		review := &authorizationv1.SelfSubjectAccessReview{
+			Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+				ResourceAttributes: &authorizationv1.ResourceAttributes{
+					Group:    permission.Group,
+					Resource: permission.Resource,
+					Verb:     permission.Verb,
+				},
+			},
+		}
+		response, err := p.Reviews.Create(ctx, review, metav1.CreateOptions{})
+		if err != nil {
+			return results, fmt.Errorf("failed to review permission %s: %w", permission, err)
+		}
+
+		result := PermissionResult{Permission: permission, Allowed: response.Status.Allowed, Reason: response.Status.Reason}
+		results = append(results, result)
+		metrics.SetPreflightPermission(permission.Group, permission.Resource, permission.Verb, result.Allowed)
+		switch {
+		case result.Allowed:
+			p.Checker.logger.V(1).Info("Preflight permission granted", "permission", permission.String())
+		case permission.Critical:
+			p.Checker.logger.Info("Preflight critical permission missing", "permission", permission.String(), "reason", result.Reason)
+			missing = append(missing, permission.String())
+		default:
+			p.Checker.logger.Info("Preflight permission missing", "permission", permission.String(), "reason", result.Reason)
+		}
+	}
+
+	if len(missing) > 0 {
+		return results, fmt.Errorf("preflight failed, missing %d critical permission(s): %s", len(missing), strings.Join(missing, ", "))
+	}
+	p.Checker.logger.Info("Preflight passed", "checked", len(results))
+	return results, nil
+}
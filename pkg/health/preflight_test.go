@@ -0,0 +1,159 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package health
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
+
+	"github.com/cropalato/k8s-acl-operator/pkg/metrics"
+)
+
+func TestPreflightRun(t *testing.T) {
+	getRoles := Permission{Group: "rbac.authorization.k8s.io", Resource: "roles", Verb: "get", Critical: true}
+	escalateRoles := Permission{Group: "rbac.authorization.k8s.io", Resource: "roles", Verb: "escalate"}
+	listNamespaces := Permission{Resource: "namespaces", Verb: "list", Critical: true}
+	reviewErr := errors.New("authorizer unavailable")
+
+	tests := []struct {
+		name        string
+		permissions []Permission
+		denied      []string
+		reviewErr   error
+		wantAllowed []bool
+		wantErr     string
+	}{
+		{
+			name:        "all required permissions granted",
+			wantAllowed: repeatBool(true, len(RequiredPermissions)),
+		},
+		{
+			name:        "non-critical permission missing",
+			permissions: []Permission{getRoles, escalateRoles},
+			denied:      []string{escalateRoles.String()},
+			wantAllowed: []bool{true, false},
+		},
+		{
+			name:        "critical permissions missing",
+			permissions: []Permission{getRoles, escalateRoles, listNamespaces},
+			denied:      []string{getRoles.String(), listNamespaces.String()},
+			wantAllowed: []bool{false, true, false},
+			wantErr:     "missing 2 critical permission(s): get rbac.authorization.k8s.io/roles, list namespaces",
+		},
+		{
+			name:        "service account writes missing",
+			denied:      []string{"create serviceaccounts"},
+			wantAllowed: requiredAllowedExcept("create serviceaccounts"),
+			wantErr:     "missing 1 critical permission(s): create serviceaccounts",
+		},
+		{
+			name:        "review error",
+			permissions: []Permission{getRoles},
+			reviewErr:   reviewErr,
+			wantErr:     "failed to review permission get rbac.authorization.k8s.io/roles",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clientset := fake.NewSimpleClientset()
+			clientset.PrependReactor("create", "selfsubjectaccessreviews", func(action clienttesting.Action) (bool, runtime.Object, error) {
+				if tt.reviewErr != nil {
+					return true, nil, tt.reviewErr
+				}
+				review := action.(clienttesting.CreateAction).GetObject().(*authorizationv1.SelfSubjectAccessReview)
+				attrs := review.Spec.ResourceAttributes
+				permission := Permission{Group: attrs.Group, Resource: attrs.Resource, Verb: attrs.Verb}
+				review.Status.Allowed = true
+				for _, denied := range tt.denied {
+					if permission.String() == denied {
+						review.Status.Allowed = false
+						review.Status.Reason = "no RBAC policy matched"
+					}
+				}
+				return true, review, nil
+			})
+
+			p := &Preflight{
+				Reviews:     clientset.AuthorizationV1().SelfSubjectAccessReviews(),
+				Checker:     NewChecker(logr.Discard()),
+				Permissions: tt.permissions,
+			}
+			results, err := p.Run(context.Background())
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("Run() error = %v, want %q", err, tt.wantErr)
+			}
+			if tt.reviewErr != nil && !errors.Is(err, tt.reviewErr) {
+				t.Errorf("Run() error = %v, want it to wrap %v", err, tt.reviewErr)
+			}
+			if startErr := p.Start(context.Background()); (startErr != nil) != (err != nil) {
+				t.Errorf("Start() error = %v, want it to match Run() error %v", startErr, err)
+			}
+
+			var gotAllowed []bool
+			for _, result := range results {
+				gotAllowed = append(gotAllowed, result.Allowed)
+				if result.Allowed == (result.Reason != "") {
+					t.Errorf("%s: Reason = %q with Allowed = %v", result.Permission, result.Reason, result.Allowed)
+				}
+				want := 0.0
+				if result.Allowed {
+					want = 1
+				}
+				gauge := metrics.PreflightPermission.WithLabelValues(result.Group, result.Resource, result.Verb)
+				if got := testutil.ToFloat64(gauge); got != want {
+					t.Errorf("rbac_operator_preflight_permission_allowed{%s} = %v, want %v", result.Permission, got, want)
+				}
+			}
+			if !reflect.DeepEqual(gotAllowed, tt.wantAllowed) {
+				t.Errorf("Allowed = %v, want %v", gotAllowed, tt.wantAllowed)
+			}
+		})
+	}
+}
+
+// requiredAllowedExcept returns the expected Allowed results for RequiredPermissions
+// when only the named permission is denied
+func requiredAllowedExcept(denied string) []bool {
+	allowed := make([]bool, 0, len(RequiredPermissions))
+	for _, permission := range RequiredPermissions {
+		allowed = append(allowed, permission.String() != denied)
+	}
+	return allowed
+}
+
+// repeatBool returns a slice of n copies of v
+func repeatBool(v bool, n int) []bool {
+	s := make([]bool, n)
+	for i := range s {
+		s[i] = v
+	}
+	return s
+}
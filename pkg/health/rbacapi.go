@@ -0,0 +1,56 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package health
+
+import (
+	"fmt"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/discovery"
+)
+
+// rbacResources are the rbac.authorization.k8s.io/v1 resources the operator manages
+var rbacResources = []string{"roles", "clusterroles", "rolebindings", "clusterrolebindings"}
+
+// CheckRBACAPI uses discovery to verify that the cluster serves every RBAC resource
+// the operator manages at rbac.authorization.k8s.io/v1. The error names what is
+// missing, and notes when only the deprecated v1beta1 version is served.
+func CheckRBACAPI(client discovery.DiscoveryInterface) error {
+	groupVersion := rbacv1.SchemeGroupVersion.String()
+	resourceList, err := client.ServerResourcesForGroupVersion(groupVersion)
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			return fmt.Errorf("failed to discover %s: %w", groupVersion, err)
+		}
+		if _, betaErr := client.ServerResourcesForGroupVersion(rbacv1.GroupName + "/v1beta1"); betaErr == nil {
+			return fmt.Errorf("cluster serves only %s/v1beta1; the operator requires %s", rbacv1.GroupName, groupVersion)
+		}
+		return fmt.Errorf("cluster does not serve %s", groupVersion)
+	}
+
+	served := make(map[string]bool, len(resourceList.APIResources))
+	for _, resource := range resourceList.APIResources {
+		served[resource.Name] = true
+	}
+	for _, resource := range rbacResources {
+		if !served[resource] {
+			return fmt.Errorf("cluster does not serve %s in %s", resource, groupVersion)
+		}
+	}
+	return nil
+}
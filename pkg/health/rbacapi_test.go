@@ -0,0 +1,91 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package health
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-logr/logr"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	clienttesting "k8s.io/client-go/testing"
+)
+
+// rbacResourceList returns a discovery list serving resources at groupVersion
+func rbacResourceList(groupVersion string, resources ...string) *metav1.APIResourceList {
+	list := &metav1.APIResourceList{GroupVersion: groupVersion}
+	for _, resource := range resources {
+		list.APIResources = append(list.APIResources, metav1.APIResource{Name: resource})
+	}
+	return list
+}
+
+func TestCheckRBACAPI(t *testing.T) {
+	tests := []struct {
+		name      string
+		resources []*metav1.APIResourceList
+		wantErr   string
+	}{
+		{
+			name:      "v1 served",
+			resources: []*metav1.APIResourceList{rbacResourceList("rbac.authorization.k8s.io/v1", rbacResources...)},
+		},
+		{
+			name:      "only v1beta1 served",
+			resources: []*metav1.APIResourceList{rbacResourceList("rbac.authorization.k8s.io/v1beta1", rbacResources...)},
+			wantErr:   "cluster serves only rbac.authorization.k8s.io/v1beta1",
+		},
+		{
+			name:    "RBAC not served",
+			wantErr: "cluster does not serve rbac.authorization.k8s.io/v1",
+		},
+		{
+			name:      "resource missing",
+			resources: []*metav1.APIResourceList{rbacResourceList("rbac.authorization.k8s.io/v1", "roles", "clusterroles", "rolebindings")},
+			wantErr:   "cluster does not serve clusterrolebindings",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			discovery := &fakediscovery.FakeDiscovery{Fake: &clienttesting.Fake{Resources: tt.resources}}
+
+			err := CheckRBACAPI(discovery)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("CheckRBACAPI() error = %v", err)
+				}
+			} else if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("CheckRBACAPI() error = %v, want %q", err, tt.wantErr)
+			}
+
+			// main marks the operator unready with the check's error
+			checker := NewChecker(logr.Discard())
+			checker.SetReady(true)
+			checker.RecordReconcile()
+			if err != nil {
+				checker.SetAPIUnavailable(err.Error())
+			}
+			readyErr := checker.ReadinessCheck(httptest.NewRequest("GET", "/readyz", nil))
+			if (readyErr != nil) != (tt.wantErr != "") {
+				t.Errorf("ReadinessCheck() error = %v, want unready %v", readyErr, tt.wantErr != "")
+			}
+		})
+	}
+}
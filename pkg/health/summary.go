@@ -0,0 +1,118 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package health
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	rbacoperatorv1 "github.com/cropalato/k8s-acl-operator/pkg/apis/rbac/v1"
+)
+
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+
+// Summary is an operator-wide snapshot of config state
+type Summary struct {
+	Configs           int      // Number of NamespaceRBACConfigs
+	ManagedNamespaces int      // Sum of applied namespaces across configs
+	DegradedConfigs   []string // Names of configs with Degraded=True, sorted
+	Healthy           bool     // Reconciler health as reported by the Checker
+}
+
+// String renders the summary as a single event message
+func (s Summary) String() string {
+	msg := fmt.Sprintf("%d configs, %d managed namespaces, %d degraded, healthy=%t",
+		s.Configs, s.ManagedNamespaces, len(s.DegradedConfigs), s.Healthy)
+	if len(s.DegradedConfigs) > 0 {
+		msg += ": " + strings.Join(s.DegradedConfigs, ", ")
+	}
+	return msg
+}
+
+// GatherSummary lists all NamespaceRBACConfigs and summarizes their status
+func GatherSummary(ctx context.Context, reader client.Reader, checker *Checker) (Summary, error) {
+	configList := &rbacoperatorv1.NamespaceRBACConfigList{}
+	if err := reader.List(ctx, configList); err != nil {
+		return Summary{}, fmt.Errorf("failed to list NamespaceRBACConfigs: %w", err)
+	}
+
+	summary := Summary{
+		Configs:         len(configList.Items),
+		DegradedConfigs: make([]string, 0),
+		Healthy:         checker.IsHealthy(),
+	}
+	for _, config := range configList.Items {
+		summary.ManagedNamespaces += config.Status.ManagedResourceCount
+		if meta.IsStatusConditionTrue(config.Status.Conditions, "Degraded") {
+			summary.DegradedConfigs = append(summary.DegradedConfigs, config.Name)
+		}
+	}
+	sort.Strings(summary.DegradedConfigs)
+
+	return summary, nil
+}
+
+// SummaryReporter is a manager runnable that periodically records a summary
+// Event on Object (typically the operator Deployment)
+type SummaryReporter struct {
+	Reader   client.Reader        // Reader used to list configs
+	Checker  *Checker             // Source of reconciler health
+	Recorder record.EventRecorder // e.g. mgr.GetEventRecorderFor("rbac-operator")
+	Object   runtime.Object       // Object the Event is attached to
+	Interval time.Duration        // Time between summaries
+}
+
+// Start records a summary every Interval until ctx is cancelled
+func (r *SummaryReporter) Start(ctx context.Context) error {
+	logger := log.FromContext(ctx).WithName("summary-reporter")
+	ticker := time.NewTicker(r.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			summary, err := GatherSummary(ctx, r.Reader, r.Checker)
+			if err != nil {
+				logger.Error(err, "Failed to gather operator summary")
+				continue
+			}
+			eventType := corev1.EventTypeNormal
+			if len(summary.DegradedConfigs) > 0 || !summary.Healthy {
+				eventType = corev1.EventTypeWarning
+			}
+			r.Recorder.Event(r.Object, eventType, "OperatorSummary", summary.String())
+		}
+	}
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable; only the leader
+// reports so replicas don't emit duplicate summaries
+func (r *SummaryReporter) NeedLeaderElection() bool {
+	return true
+}
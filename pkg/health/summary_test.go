@@ -0,0 +1,141 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package health
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/go-logr/logr"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	rbacoperatorv1 "github.com/cropalato/k8s-acl-operator/pkg/apis/rbac/v1"
+)
+
+// summaryConfig returns a config managing count resources, optionally Degraded
+func summaryConfig(name string, count int, degraded bool) *rbacoperatorv1.NamespaceRBACConfig {
+	config := &rbacoperatorv1.NamespaceRBACConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+	}
+	config.Status.ManagedResourceCount = count
+	if degraded {
+		config.Status.Conditions = []metav1.Condition{{
+			Type:   "Degraded",
+			Status: metav1.ConditionTrue,
+			Reason: "ApplyFailed",
+		}}
+	}
+	return config
+}
+
+func TestGatherSummary(t *testing.T) {
+	listErr := errors.New("list failed")
+
+	tests := []struct {
+		name      string
+		objs      []client.Object
+		unhealthy bool
+		listErr   error
+		want      Summary
+		wantMsg   string
+	}{
+		{
+			name:    "no configs",
+			want:    Summary{DegradedConfigs: []string{}, Healthy: true},
+			wantMsg: "0 configs, 0 managed namespaces, 0 degraded, healthy=true",
+		},
+		{
+			name: "degraded configs are sorted",
+			objs: []client.Object{
+				summaryConfig("zeta", 2, true),
+				summaryConfig("alpha", 3, true),
+				summaryConfig("ok", 5, false),
+			},
+			want: Summary{
+				Configs:           3,
+				ManagedNamespaces: 10,
+				DegradedConfigs:   []string{"alpha", "zeta"},
+				Healthy:           true,
+			},
+			wantMsg: "3 configs, 10 managed namespaces, 2 degraded, healthy=true: alpha, zeta",
+		},
+		{
+			name:      "unhealthy checker",
+			objs:      []client.Object{summaryConfig("ok", 1, false)},
+			unhealthy: true,
+			want: Summary{
+				Configs:           1,
+				ManagedNamespaces: 1,
+				DegradedConfigs:   []string{},
+			},
+			wantMsg: "1 configs, 1 managed namespaces, 0 degraded, healthy=false",
+		},
+		{
+			name:    "list error",
+			listErr: listErr,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			if err := rbacoperatorv1.AddToScheme(scheme); err != nil {
+				t.Fatal(err)
+			}
+			c := fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithObjects(tt.objs...).
+				WithInterceptorFuncs(interceptor.Funcs{
+					List: func(ctx context.Context, c client.WithWatch, list client.ObjectList, opts ...client.ListOption) error {
+						if tt.listErr != nil {
+							return tt.listErr
+						}
+						return c.List(ctx, list, opts...)
+					},
+				}).
+				Build()
+
+			checker := NewChecker(logr.Discard())
+			if tt.unhealthy {
+				checker.SetHealthy(false)
+			}
+
+			got, err := GatherSummary(context.Background(), c, checker)
+			if tt.listErr != nil {
+				if !errors.Is(err, tt.listErr) {
+					t.Fatalf("GatherSummary() error = %v, want %v", err, tt.listErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GatherSummary() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GatherSummary() = %+v, want %+v", got, tt.want)
+			}
+			if msg := got.String(); msg != tt.wantMsg {
+				t.Errorf("String() = %q, want %q", msg, tt.wantMsg)
+			}
+		})
+	}
+}
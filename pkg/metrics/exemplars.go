@@ -0,0 +1,61 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// OpenMetricsPath is the metrics server path that serves the OpenMetrics format,
+// the only exposition format that carries exemplars
+const OpenMetricsPath = "/metrics/openmetrics"
+
+// exemplarsEnabled controls whether observations carry trace ID exemplars
+var exemplarsEnabled atomic.Bool
+
+// EnableExemplars turns trace ID exemplars on duration observations on or off
+func EnableExemplars(enabled bool) {
+	exemplarsEnabled.Store(enabled)
+}
+
+// OpenMetricsHandler serves the metrics in gatherer in the OpenMetrics format, so
+// exemplars are exposed. The default /metrics endpoint never negotiates it.
+func OpenMetricsHandler(gatherer prometheus.Gatherer) http.Handler {
+	return promhttp.HandlerFor(gatherer, promhttp.HandlerOpts{
+		ErrorHandling:     promhttp.HTTPErrorOnError,
+		EnableOpenMetrics: true,
+	})
+}
+
+// observeWithTraceExemplar observes value on observer, attaching the trace ID from
+// ctx as an exemplar when exemplars are enabled and ctx carries a sampled span
+func observeWithTraceExemplar(ctx context.Context, observer prometheus.Observer, value float64) {
+	if exemplarsEnabled.Load() {
+		spanContext := trace.SpanContextFromContext(ctx)
+		if exemplarObserver, ok := observer.(prometheus.ExemplarObserver); ok && spanContext.HasTraceID() {
+			exemplarObserver.ObserveWithExemplar(value, prometheus.Labels{"trace_id": spanContext.TraceID().String()})
+			return
+		}
+	}
+	observer.Observe(value)
+}
@@ -0,0 +1,96 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestRecordReconciliationAttachesTraceExemplar(t *testing.T) {
+	traceID := trace.TraceID{0x4b, 0xf9, 0x2f, 0x35, 0x77, 0xb3, 0x4d, 0xa6, 0xa3, 0xce, 0x92, 0x9d, 0x0e, 0x0e, 0x47, 0x36}
+	traced := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceID,
+		SpanID:     trace.SpanID{0x00, 0xf0, 0x67, 0xaa, 0x0b, 0xa9, 0x02, 0xb7},
+		TraceFlags: trace.FlagsSampled,
+	}))
+
+	tests := []struct {
+		name         string
+		enabled      bool
+		ctx          context.Context
+		wantExemplar bool
+	}{
+		{name: "enabled with trace", enabled: true, ctx: traced, wantExemplar: true},
+		{name: "enabled without trace", enabled: true, ctx: context.Background()},
+		{name: "disabled with trace", ctx: traced},
+	}
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(ReconciliationDuration)
+	server := httptest.NewServer(OpenMetricsHandler(registry))
+	defer server.Close()
+	defer EnableExemplars(false)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := "exemplar-" + strings.ReplaceAll(tt.name, " ", "-")
+			EnableExemplars(tt.enabled)
+			RecordReconciliation(tt.ctx, config, "test", 20*time.Millisecond, nil)
+
+			req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.Header.Set("Accept", "application/openmetrics-text; version=1.0.0")
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			body, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var series, exemplar bool
+			for _, line := range strings.Split(string(body), "\n") {
+				if !strings.HasPrefix(line, "rbac_operator_reconciliation_duration_seconds_bucket{") || !strings.Contains(line, `config="`+config+`"`) {
+					continue
+				}
+				series = true
+				if strings.Contains(line, `# {trace_id="`+traceID.String()+`"} 0.02`) {
+					exemplar = true
+				}
+			}
+			if !series {
+				t.Fatalf("no duration buckets for %s in:\n%s", config, body)
+			}
+			if exemplar != tt.wantExemplar {
+				t.Errorf("exemplar attached = %v, want %v", exemplar, tt.wantExemplar)
+			}
+		})
+	}
+}
@@ -0,0 +1,45 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"context"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// LeaderTracker is a manager runnable that requires leader election, so the
+// manager starts it only once this instance acquires the lease. It sets the
+// rbac_operator_is_leader gauge and logs leadership transitions.
+type LeaderTracker struct{}
+
+// Start marks this instance as leader until ctx is cancelled
+func (t *LeaderTracker) Start(ctx context.Context) error {
+	logger := log.FromContext(ctx).WithName("leader-election")
+	logger.Info("Acquired leadership")
+	SetLeader(true)
+
+	<-ctx.Done()
+	logger.Info("Leadership released, stopping")
+	SetLeader(false)
+	return nil
+}
+
+// NeedLeaderElection returns true so Start runs only on the leader
+func (t *LeaderTracker) NeedLeaderElection() bool {
+	return true
+}
@@ -0,0 +1,65 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+func TestLeaderTrackerTogglesMetric(t *testing.T) {
+	var _ manager.LeaderElectionRunnable = &LeaderTracker{}
+	tracker := &LeaderTracker{}
+	if !tracker.NeedLeaderElection() {
+		t.Fatal("NeedLeaderElection() = false, want true so only the leader starts the tracker")
+	}
+
+	ResetMetrics()
+	if got := testutil.ToFloat64(IsLeader); got != 0 {
+		t.Fatalf("is_leader before election = %v, want 0", got)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- tracker.Start(ctx) }()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for testutil.ToFloat64(IsLeader) != 1 {
+		if time.Now().After(deadline) {
+			t.Fatal("is_leader never became 1 after Start")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// Losing the lease cancels the runnable's context
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Start() error = %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Start did not return after the context was cancelled")
+	}
+	if got := testutil.ToFloat64(IsLeader); got != 0 {
+		t.Errorf("is_leader after losing leadership = %v, want 0", got)
+	}
+}
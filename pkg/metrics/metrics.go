@@ -20,12 +20,13 @@ limitations under the License.
 package metrics
 
 import (
+	"context"
 	"strings"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"k8s.io/apimachinery/pkg/api/errors"
-	"sigs.k8s.io/controller-runtime/pkg/metrics"
+	"k8s.io/apimachinery/pkg/api/meta"
 )
 
 var (
@@ -47,12 +48,29 @@ var (
 		[]string{"config", "controller"},
 	)
 
+	ReconcileDurationByNamespaceCount = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "rbac_operator_reconcile_duration_by_namespace_count",
+			Help:    "Duration of NamespaceRBACConfig reconciliations by number of managed namespaces",
+			Buckets: prometheus.DefBuckets,
+		},
+		[]string{"namespace_count"}, // namespace_count: 0/1-10/11-100/100+
+	)
+
 	ReconciliationErrors = prometheus.NewCounterVec(
 		prometheus.CounterOpts{
 			Name: "rbac_operator_reconciliation_errors_total",
 			Help: "Total reconciliation errors by type",
 		},
-		[]string{"config", "controller", "error_type"}, // error_type: validation/template/api/conflict
+		[]string{"config", "controller", "error_type"}, // error_type: validation/template/api/conflict/api_unavailable/escalation/panic
+	)
+
+	ReconcilePanics = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "rbac_operator_reconcile_panics_total",
+			Help: "Panics recovered during reconciliation, converted into errors and requeued",
+		},
+		[]string{"controller"},
 	)
 
 	// Resource management metrics
@@ -69,7 +87,7 @@ var (
 			Name: "rbac_operator_resource_operations_total",
 			Help: "Total RBAC resource operations",
 		},
-		[]string{"config", "resource_type", "operation", "result"}, // operation: create/update/delete
+		[]string{"config", "resource_type", "operation", "result"}, // operation: create/update/patch/delete/noop
 	)
 
 	TemplateProcessingErrors = prometheus.NewCounterVec(
@@ -89,6 +107,30 @@ var (
 		[]string{"config"},
 	)
 
+	DriftCorrections = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "rbac_operator_drift_corrections_total",
+			Help: "Total number of resources restored after being deleted or modified outside the operator",
+		},
+		[]string{"config", "resource_type"},
+	)
+
+	ResourcesAdopted = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "rbac_operator_resources_adopted_total",
+			Help: "Total number of pre-existing resources the operator took over on update",
+		},
+		[]string{"config", "resource_type"},
+	)
+
+	GenerationLag = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "rbac_operator_generation_lag_seconds",
+			Help: "Seconds a config's observed generation has trailed its spec generation (0 when caught up)",
+		},
+		[]string{"config"},
+	)
+
 	ActiveConfigs = prometheus.NewGauge(
 		prometheus.GaugeOpts{
 			Name: "rbac_operator_namespace_configs_total",
@@ -110,7 +152,7 @@ var (
 			Name: "rbac_operator_conflict_resolution_total",
 			Help: "Conflict resolution operations by strategy",
 		},
-		[]string{"config", "strategy", "resource_type"}, // strategy: merge/replace/ignore
+		[]string{"config", "strategy", "resource_type"}, // strategy: merge/replace/ignore/authoritative/freeze
 	)
 
 	// Template engine metrics
@@ -123,6 +165,14 @@ var (
 		[]string{"config", "template_type"},
 	)
 
+	TemplateFunctionCalls = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "rbac_operator_template_function_calls_total",
+			Help: "Template function calls while rendering RBAC, by function name; validation is not counted",
+		},
+		[]string{"function"},
+	)
+
 	// Cleanup metrics
 	CleanupOperations = prometheus.NewCounterVec(
 		prometheus.CounterOpts{
@@ -132,6 +182,54 @@ var (
 		[]string{"resource_type", "result"},
 	)
 
+	CleanupDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "rbac_operator_cleanup_duration_seconds",
+			Help:    "Duration of cleanup operations for resources of deleted or unmatched namespaces",
+			Buckets: prometheus.DefBuckets,
+		},
+		[]string{"resource_type"},
+	)
+
+	// Webhook metrics
+	WebhookAdmissions = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "rbac_operator_webhook_admissions_total",
+			Help: "Admission decisions made by the admission webhooks",
+		},
+		[]string{"result", "reason"}, // result: allowed/denied
+	)
+
+	WorkqueueDepth = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "rbac_operator_workqueue_depth",
+			Help: "Reconcile requests waiting in each controller's workqueue, sampled periodically",
+		},
+		[]string{"controller"}, // controller: namespacerbacconfig/namespace
+	)
+
+	IsLeader = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "rbac_operator_is_leader",
+			Help: "Whether this instance holds the leader election lease (1=leader, 0=follower)",
+		},
+	)
+
+	CacheSynced = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "rbac_operator_cache_synced",
+			Help: "Whether the informer caches have synced since startup (1=synced, 0=waiting)",
+		},
+	)
+
+	PreflightPermission = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "rbac_operator_preflight_permission_allowed",
+			Help: "Whether the preflight found the operator holds a permission (1=allowed, 0=denied)",
+		},
+		[]string{"group", "resource", "verb"},
+	)
+
 	// Health metrics
 	OperatorHealth = prometheus.NewGaugeVec(
 		prometheus.GaugeOpts{
@@ -142,29 +240,42 @@ var (
 	)
 )
 
-func init() {
-	// Register metrics with controller-runtime
-	metrics.Registry.MustRegister(
+// collectors returns every operator metric, in registration order
+func collectors() []prometheus.Collector {
+	return []prometheus.Collector{
 		ReconciliationTotal,
 		ReconciliationDuration,
+		ReconcileDurationByNamespaceCount,
 		ReconciliationErrors,
+		ReconcilePanics,
 		ManagedResources,
 		ResourceOperations,
 		TemplateProcessingErrors,
 		ManagedNamespaces,
+		GenerationLag,
+		DriftCorrections,
+		ResourcesAdopted,
 		ActiveConfigs,
 		LastSuccessfulReconcile,
 		ConflictResolution,
 		TemplateProcessingDuration,
+		TemplateFunctionCalls,
 		CleanupOperations,
+		CleanupDuration,
+		WebhookAdmissions,
+		WorkqueueDepth,
+		IsLeader,
+		CacheSynced,
+		PreflightPermission,
 		OperatorHealth,
-	)
+	}
 }
 
 // Helper functions for recording metrics
 
-// RecordReconciliation records reconciliation metrics with error categorization
-func RecordReconciliation(config, controller string, duration time.Duration, err error) {
+// RecordReconciliation records reconciliation metrics with error categorization. The
+// duration observation carries the trace ID in ctx as an exemplar when enabled.
+func RecordReconciliation(ctx context.Context, config, controller string, duration time.Duration, err error) {
 	result := "success"
 	if err != nil {
 		result = "error"
@@ -174,13 +285,38 @@ func RecordReconciliation(config, controller string, duration time.Duration, err
 	}
 
 	ReconciliationTotal.WithLabelValues(config, controller, result).Inc()
-	ReconciliationDuration.WithLabelValues(config, controller).Observe(duration.Seconds())
+	observeWithTraceExemplar(ctx, ReconciliationDuration.WithLabelValues(config, controller), duration.Seconds())
 
 	if err == nil {
 		LastSuccessfulReconcile.WithLabelValues(config, controller).SetToCurrentTime()
 	}
 }
 
+// RecordPanic records a panic recovered while reconciling in the given controller
+func RecordPanic(controller string) {
+	ReconcilePanics.WithLabelValues(controller).Inc()
+}
+
+// RecordReconcileDurationByNamespaceCount records a reconcile duration under the
+// bucket for the number of namespaces the config manages
+func RecordReconcileDurationByNamespaceCount(namespaceCount int, duration time.Duration) {
+	ReconcileDurationByNamespaceCount.WithLabelValues(namespaceCountBucket(namespaceCount)).Observe(duration.Seconds())
+}
+
+// namespaceCountBucket maps a namespace count to its label value
+func namespaceCountBucket(count int) string {
+	switch {
+	case count == 0:
+		return "0"
+	case count <= 10:
+		return "1-10"
+	case count <= 100:
+		return "11-100"
+	default:
+		return "100+"
+	}
+}
+
 // RecordResourceOperation records RBAC resource create/update/delete operations
 func RecordResourceOperation(config, resourceType, operation string, err error) {
 	result := "success"
@@ -198,6 +334,11 @@ func RecordTemplateProcessing(config, templateType string, duration time.Duratio
 	TemplateProcessingDuration.WithLabelValues(config, templateType).Observe(duration.Seconds())
 }
 
+// RecordTemplateFunctionCall counts a call to the named template function
+func RecordTemplateFunctionCall(function string) {
+	TemplateFunctionCalls.WithLabelValues(function).Inc()
+}
+
 // UpdateManagedResources updates the count of managed resources
 func UpdateManagedResources(config, resourceType, namespace string, count int) {
 	ManagedResources.WithLabelValues(config, resourceType, namespace).Set(float64(count))
@@ -208,6 +349,24 @@ func UpdateManagedNamespaces(config string, count int) {
 	ManagedNamespaces.WithLabelValues(config).Set(float64(count))
 }
 
+// UpdateGenerationLag sets how long the config's spec has been ahead of its observed generation
+func UpdateGenerationLag(config string, lag time.Duration) {
+	if lag < 0 {
+		lag = 0
+	}
+	GenerationLag.WithLabelValues(config).Set(lag.Seconds())
+}
+
+// RecordDriftCorrection records a resource restored to its rendered state
+func RecordDriftCorrection(config, resourceType string) {
+	DriftCorrections.WithLabelValues(config, resourceType).Inc()
+}
+
+// RecordAdoption records a pre-existing resource taken over by the operator
+func RecordAdoption(config, resourceType string) {
+	ResourcesAdopted.WithLabelValues(config, resourceType).Inc()
+}
+
 // RecordConflictResolution records merge strategy usage
 func RecordConflictResolution(config, strategy, resourceType string) {
 	ConflictResolution.WithLabelValues(config, strategy, resourceType).Inc()
@@ -222,6 +381,47 @@ func RecordCleanup(resourceType string, err error) {
 	CleanupOperations.WithLabelValues(resourceType, result).Inc()
 }
 
+// RecordCleanupDuration records how long a cleanup operation took
+func RecordCleanupDuration(resourceType string, duration time.Duration) {
+	CleanupDuration.WithLabelValues(resourceType).Observe(duration.Seconds())
+}
+
+// RecordWebhookAdmission records an admission webhook decision
+func RecordWebhookAdmission(allowed bool, reason string) {
+	result := "allowed"
+	if !allowed {
+		result = "denied"
+	}
+	WebhookAdmissions.WithLabelValues(result, reason).Inc()
+}
+
+// SetLeader records whether this instance is the leader
+func SetLeader(leader bool) {
+	value := float64(0)
+	if leader {
+		value = 1
+	}
+	IsLeader.Set(value)
+}
+
+// SetCacheSynced records whether the informer caches have synced
+func SetCacheSynced(synced bool) {
+	value := float64(0)
+	if synced {
+		value = 1
+	}
+	CacheSynced.Set(value)
+}
+
+// SetPreflightPermission records whether the preflight found a permission granted
+func SetPreflightPermission(group, resource, verb string, allowed bool) {
+	value := float64(0)
+	if allowed {
+		value = 1
+	}
+	PreflightPermission.WithLabelValues(group, resource, verb).Set(value)
+}
+
 // SetOperatorHealth sets health status for components
 func SetOperatorHealth(component string, healthy bool) {
 	value := float64(0)
@@ -253,11 +453,24 @@ func categorizeError(err error) string {
 	if errors.IsUnauthorized(err) {
 		return "unauthorized"
 	}
+	// Forbidden RBAC writes are escalation prevention rejections, wrapped by the rbac package
+	if strings.Contains(errStrLower, "escalation denied") {
+		return "escalation"
+	}
 	if errors.IsForbidden(err) {
 		return "forbidden"
 	}
+	if meta.IsNoMatchError(err) {
+		return "api_unavailable"
+	}
 
 	// Check error message content for categorization
+	if strings.Contains(errStrLower, "recovered from panic") {
+		return "panic"
+	}
+	if strings.Contains(errStrLower, "api unavailable") {
+		return "api_unavailable"
+	}
 	if strings.Contains(errStrLower, "template") {
 		return "template"
 	}
@@ -281,14 +494,26 @@ func categorizeError(err error) string {
 func ResetMetrics() {
 	ReconciliationTotal.Reset()
 	ReconciliationDuration.Reset()
+	ReconcileDurationByNamespaceCount.Reset()
 	ReconciliationErrors.Reset()
+	ReconcilePanics.Reset()
 	ManagedResources.Reset()
 	ResourceOperations.Reset()
 	TemplateProcessingErrors.Reset()
 	ManagedNamespaces.Reset()
+	GenerationLag.Reset()
+	DriftCorrections.Reset()
+	ResourcesAdopted.Reset()
 	ConflictResolution.Reset()
 	TemplateProcessingDuration.Reset()
+	TemplateFunctionCalls.Reset()
 	CleanupOperations.Reset()
+	CleanupDuration.Reset()
+	WebhookAdmissions.Reset()
+	WorkqueueDepth.Reset()
 	OperatorHealth.Reset()
+	IsLeader.Set(0)
+	CacheSynced.Set(0)
+	PreflightPermission.Reset()
 	// Note: ActiveConfigs and LastSuccessfulReconcile are not resettable
 }
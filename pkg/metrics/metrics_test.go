@@ -0,0 +1,139 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestCategorizeError(t *testing.T) {
+	noMatch := &meta.NoKindMatchError{GroupKind: schema.GroupKind{Group: "rbac.authorization.k8s.io", Kind: "Role"}}
+
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{name: "nil", err: nil, want: "none"},
+		{name: "no match", err: noMatch, want: "api_unavailable"},
+		{name: "wrapped no match", err: fmt.Errorf("failed to get Role: %w", noMatch), want: "api_unavailable"},
+		{name: "RBAC API unavailable message", err: fmt.Errorf("RBAC API unavailable: no matches for kind"), want: "api_unavailable"},
+		{name: "not found", err: errors.NewNotFound(schema.GroupResource{Resource: "roles"}, "viewer"), want: "not_found"},
+		{name: "conflict", err: errors.NewConflict(schema.GroupResource{Resource: "roles"}, "viewer", fmt.Errorf("stale")), want: "conflict"},
+		{name: "escalation denied", err: fmt.Errorf("RBAC escalation denied for Role team-a/viewer: %w", errors.NewForbidden(schema.GroupResource{Resource: "roles"}, "viewer", fmt.Errorf("escalation"))), want: "escalation"},
+		{name: "other forbidden", err: errors.NewForbidden(schema.GroupResource{Resource: "configmaps"}, "export", fmt.Errorf("denied")), want: "forbidden"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := categorizeError(tt.err); got != tt.want {
+				t.Errorf("categorizeError(%v) = %q, want %q", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecordWebhookAdmission(t *testing.T) {
+	tests := []struct {
+		name       string
+		allowed    bool
+		reason     string
+		wantResult string
+	}{
+		{name: "allowed", allowed: true, reason: "valid", wantResult: "allowed"},
+		{name: "denied", allowed: false, reason: "invalid_template", wantResult: "denied"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ResetMetrics()
+			RecordWebhookAdmission(tt.allowed, tt.reason)
+			RecordWebhookAdmission(tt.allowed, tt.reason)
+
+			if got := testutil.ToFloat64(WebhookAdmissions.WithLabelValues(tt.wantResult, tt.reason)); got != 2 {
+				t.Errorf("%s/%s admissions = %v, want 2", tt.wantResult, tt.reason, got)
+			}
+			if got := testutil.CollectAndCount(WebhookAdmissions); got != 1 {
+				t.Errorf("admission series = %d, want 1", got)
+			}
+
+			ResetMetrics()
+			if got := testutil.CollectAndCount(WebhookAdmissions); got != 0 {
+				t.Errorf("admission series after reset = %d, want 0", got)
+			}
+		})
+	}
+}
+
+func TestUpdateGenerationLag(t *testing.T) {
+	tests := []struct {
+		name string
+		lag  time.Duration
+		want float64
+	}{
+		{name: "lagging", lag: 90 * time.Second, want: 90},
+		{name: "caught up", lag: 0, want: 0},
+		{name: "negative is clamped", lag: -time.Second, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ResetMetrics()
+			UpdateGenerationLag("cfg", tt.lag)
+			if got := testutil.ToFloat64(GenerationLag.WithLabelValues("cfg")); got != tt.want {
+				t.Errorf("generation lag = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecordReconcileDurationByNamespaceCount(t *testing.T) {
+	tests := []struct {
+		name       string
+		count      int
+		wantBucket string
+	}{
+		{name: "no namespaces", count: 0, wantBucket: "0"},
+		{name: "single namespace", count: 1, wantBucket: "1-10"},
+		{name: "upper edge of small", count: 10, wantBucket: "1-10"},
+		{name: "lower edge of medium", count: 11, wantBucket: "11-100"},
+		{name: "upper edge of medium", count: 100, wantBucket: "11-100"},
+		{name: "large", count: 101, wantBucket: "100+"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ResetMetrics()
+			RecordReconcileDurationByNamespaceCount(tt.count, 250*time.Millisecond)
+
+			if got := testutil.CollectAndCount(ReconcileDurationByNamespaceCount); got != 1 {
+				t.Fatalf("duration series = %d, want 1", got)
+			}
+			// Deleting succeeds only if the observation landed under the expected label
+			if !ReconcileDurationByNamespaceCount.DeleteLabelValues(tt.wantBucket) {
+				t.Errorf("no observation under namespace_count=%q", tt.wantBucket)
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// DefaultQueueDepthInterval is how often QueueDepthReporter samples the workqueues
+const DefaultQueueDepthInterval = 10 * time.Second
+
+// workqueueDepthMetric is the per-queue depth gauge controller-runtime registers
+// for every controller, labeled by controller name
+const workqueueDepthMetric = "workqueue_depth"
+
+// QueueDepthReporter periodically copies controller-runtime's workqueue_depth gauges
+// into rbac_operator_workqueue_depth, so dashboards and alerts on the operator's own
+// metric prefix can detect it falling behind. It samples rather than collects on
+// scrape because gathering a registry from one of its own collectors can deadlock.
+type QueueDepthReporter struct {
+	Gatherer prometheus.Gatherer // Defaults to controller-runtime's registry
+	Interval time.Duration       // Defaults to DefaultQueueDepthInterval
+}
+
+// Start samples queue depths until ctx is cancelled; it implements manager.Runnable
+func (q *QueueDepthReporter) Start(ctx context.Context) error {
+	logger := log.FromContext(ctx).WithName("queue-depth")
+	gatherer := q.Gatherer
+	if gatherer == nil {
+		gatherer = metrics.Registry
+	}
+	interval := q.Interval
+	if interval <= 0 {
+		interval = DefaultQueueDepthInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if err := RecordQueueDepths(gatherer); err != nil {
+				logger.Error(err, "Failed to sample workqueue depth")
+			}
+		}
+	}
+}
+
+// NeedLeaderElection returns false so the reporter runs on every replica; queues only
+// exist once a replica leads and starts its controllers
+func (q *QueueDepthReporter) NeedLeaderElection() bool {
+	return false
+}
+
+// RecordQueueDepths sets rbac_operator_workqueue_depth from the workqueue_depth
+// gauges in gatherer
+func RecordQueueDepths(gatherer prometheus.Gatherer) error {
+	families, err := gatherer.Gather()
+	if err != nil {
+		return err
+	}
+	for _, family := range families {
+		if family.GetName() != workqueueDepthMetric {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				if label.GetName() == "name" {
+					WorkqueueDepth.WithLabelValues(label.GetValue()).Set(metric.GetGauge().GetValue())
+				}
+			}
+		}
+	}
+	return nil
+}
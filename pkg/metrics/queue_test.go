@@ -0,0 +1,70 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestRecordQueueDepths(t *testing.T) {
+	tests := []struct {
+		name   string
+		depths map[string]float64
+	}{
+		{name: "no controllers", depths: map[string]float64{}},
+		{name: "idle controller", depths: map[string]float64{"namespace": 0}},
+		{name: "backlog", depths: map[string]float64{"namespace": 3, "namespacerbacconfig": 42}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			WorkqueueDepth.Reset()
+			// Stands in for controller-runtime's registry and its workqueue_depth gauge
+			source := prometheus.NewRegistry()
+			depth := prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: workqueueDepthMetric, Help: "Current depth of workqueue"}, []string{"name"})
+			source.MustRegister(depth)
+			for name, value := range tt.depths {
+				depth.WithLabelValues(name).Set(value)
+			}
+
+			if err := RecordQueueDepths(source); err != nil {
+				t.Fatalf("RecordQueueDepths() error = %v", err)
+			}
+
+			if got := testutil.CollectAndCount(WorkqueueDepth); got != len(tt.depths) {
+				t.Errorf("rbac_operator_workqueue_depth series = %d, want %d", got, len(tt.depths))
+			}
+			for name, want := range tt.depths {
+				if got := testutil.ToFloat64(WorkqueueDepth.WithLabelValues(name)); got != want {
+					t.Errorf("rbac_operator_workqueue_depth{name=%q} = %v, want %v", name, got, want)
+				}
+			}
+
+			// The gauge is exposed by the operator's registry
+			registry := prometheus.NewRegistry()
+			if err := Register(registry); err != nil {
+				t.Fatal(err)
+			}
+			if got, err := testutil.GatherAndCount(registry, "rbac_operator_workqueue_depth"); err != nil || got != len(tt.depths) {
+				t.Errorf("gathered rbac_operator_workqueue_depth series = %d (%v), want %d", got, err, len(tt.depths))
+			}
+		})
+	}
+}
@@ -0,0 +1,31 @@
+//go:build !rbac_operator_no_auto_register
+
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// Register metrics with controller-runtime on import. Build with the
+// rbac_operator_no_auto_register tag to control registration via Register instead.
+func init() {
+	if err := Register(metrics.Registry); err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,75 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestRegister(t *testing.T) {
+	tests := []struct {
+		name    string
+		setup   func(registry *prometheus.Registry) error
+		wantErr bool
+	}{
+		{
+			name:  "fresh registry",
+			setup: func(*prometheus.Registry) error { return nil },
+		},
+		{
+			name:  "registered twice",
+			setup: func(registry *prometheus.Registry) error { return Register(registry) },
+		},
+		{
+			name: "conflicting collector",
+			setup: func(registry *prometheus.Registry) error {
+				return registry.Register(prometheus.NewGauge(prometheus.GaugeOpts{
+					Name: "rbac_operator_is_leader",
+					Help: "A different metric with the operator's name",
+				}))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry := prometheus.NewRegistry()
+			if err := tt.setup(registry); err != nil {
+				t.Fatal(err)
+			}
+
+			err := Register(registry)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Register() error = %v, wantErr %t", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			// Every collector is now known to the registry
+			for _, collector := range collectors() {
+				var alreadyRegistered prometheus.AlreadyRegisteredError
+				if err := registry.Register(collector); !errors.As(err, &alreadyRegistered) {
+					t.Errorf("collector %T not registered: %v", collector, err)
+				}
+			}
+		})
+	}
+}
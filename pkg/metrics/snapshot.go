@@ -0,0 +1,90 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/expfmt"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// Snapshot gathers all metrics from the gatherer and renders them in the
+// Prometheus text exposition format
+func Snapshot(gatherer prometheus.Gatherer) (string, error) {
+	families, err := gatherer.Gather()
+	if err != nil {
+		return "", fmt.Errorf("failed to gather metrics: %w", err)
+	}
+
+	var buf bytes.Buffer
+	for _, family := range families {
+		if _, err := expfmt.MetricFamilyToText(&buf, family); err != nil {
+			return "", fmt.Errorf("failed to format metric %s: %w", family.GetName(), err)
+		}
+	}
+	return buf.String(), nil
+}
+
+// SnapshotDumper writes a metrics snapshot to Writer whenever the process
+// receives SIGUSR1, for debugging where Prometheus does not scrape the operator
+// (e.g. `kill -USR1 1` via kubectl exec).
+type SnapshotDumper struct {
+	Writer io.Writer // Defaults to os.Stderr, keeping stdout free for the audit log
+}
+
+// Start handles SIGUSR1 until ctx is cancelled; it implements manager.Runnable
+func (d *SnapshotDumper) Start(ctx context.Context) error {
+	logger := log.FromContext(ctx).WithName("metrics-snapshot")
+	writer := d.Writer
+	if writer == nil {
+		writer = os.Stderr
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGUSR1)
+	defer signal.Stop(signals)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-signals:
+			snapshot, err := Snapshot(metrics.Registry)
+			if err != nil {
+				logger.Error(err, "Failed to gather metrics snapshot")
+				continue
+			}
+			if _, err := io.WriteString(writer, snapshot); err != nil {
+				logger.Error(err, "Failed to write metrics snapshot")
+			}
+		}
+	}
+}
+
+// NeedLeaderElection returns false so every replica can dump its own metrics
+func (d *SnapshotDumper) NeedLeaderElection() bool {
+	return false
+}
@@ -0,0 +1,72 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestSnapshot(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	if err := Register(registry); err != nil {
+		t.Fatal(err)
+	}
+	// Registering again is a no-op
+	if err := Register(registry); err != nil {
+		t.Fatalf("second Register: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		record func()
+		want   []string
+	}{
+		{
+			name:   "counter",
+			record: func() { RecordCleanup("role", nil) },
+			want: []string{
+				"# TYPE rbac_operator_cleanup_operations_total counter\n",
+				`rbac_operator_cleanup_operations_total{resource_type="role",result="success"} 1` + "\n",
+			},
+		},
+		{
+			name:   "gauge",
+			record: func() { UpdateManagedNamespaces("cfg", 3) },
+			want:   []string{`rbac_operator_managed_namespaces_total{config="cfg"} 3` + "\n"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ResetMetrics()
+			tt.record()
+
+			got, err := Snapshot(registry)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("snapshot does not contain %q:\n%s", want, got)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,45 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rbac
+
+import (
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// carryAdoption stamps AdoptedAnnotation on desired when the existing resource is being
+// taken over, i.e. it lacks the OwnerLabel, and reports whether that is the case. An
+// annotation from an earlier adoption is carried over so later updates keep it.
+func carryAdoption(existing, desired client.Object) bool {
+	adoptedAt, previously := existing.GetAnnotations()[AdoptedAnnotation]
+	_, owned := existing.GetLabels()[OwnerLabel]
+	adopting := !owned && !previously
+	if adopting {
+		adoptedAt = time.Now().UTC().Format(time.RFC3339)
+	} else if !previously {
+		return false
+	}
+
+	annotations := desired.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	annotations[AdoptedAnnotation] = adoptedAt
+	desired.SetAnnotations(annotations)
+	return adopting
+}
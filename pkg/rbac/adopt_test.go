@@ -0,0 +1,117 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rbac
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	rbacoperatorv1 "github.com/cropalato/k8s-acl-operator/pkg/apis/rbac/v1"
+	"github.com/cropalato/k8s-acl-operator/pkg/metrics"
+)
+
+func TestApplyRecordsAdoption(t *testing.T) {
+	tests := []struct {
+		name        string
+		existing    *rbacv1.Role
+		wantAdopted bool
+	}{
+		{name: "created by the operator"},
+		{
+			name:        "pre-existing foreign role",
+			existing:    &rbacv1.Role{ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "viewer", Labels: map[string]string{"app": "legacy"}}},
+			wantAdopted: true,
+		},
+		{
+			name: "pre-existing owned role",
+			existing: &rbacv1.Role{ObjectMeta: metav1.ObjectMeta{
+				Namespace: "team-a",
+				Name:      "viewer",
+				Labels:    map[string]string{OwnerLabel: "namespace-rbac-operator"},
+			}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ns := testNamespace("team-a", map[string]string{"team": "a"})
+			objs := []client.Object{ns}
+			if tt.existing != nil {
+				objs = append(objs, tt.existing)
+			}
+			c := newFakeClient(t, interceptor.Funcs{}, objs...)
+			m := NewManager(c, Options{})
+
+			configName := "adopt-" + strings.ReplaceAll(tt.name, " ", "-")
+			config := testConfig(configName)
+			merge := rbacoperatorv1.MergeStrategyMerge
+			config.Spec.Config = &rbacoperatorv1.NamespaceRBACConfigConfig{MergeStrategy: &merge}
+			config.Spec.RBACTemplates.Roles = []rbacoperatorv1.RoleTemplate{{
+				Name:  "viewer",
+				Rules: []rbacv1.PolicyRule{{APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: []string{"get"}}},
+			}}
+			adoptions := metrics.ResourcesAdopted.WithLabelValues(configName, "role")
+
+			// The second apply must neither re-adopt nor restamp the annotation
+			var firstAdoptedAt string
+			for i := 0; i < 2; i++ {
+				if _, err := m.ApplyRBACForNamespace(context.Background(), ns, config, []string{ns.Name}); err != nil {
+					t.Fatal(err)
+				}
+				role := &rbacv1.Role{}
+				if err := c.Get(context.Background(), types.NamespacedName{Namespace: ns.Name, Name: "viewer"}, role); err != nil {
+					t.Fatal(err)
+				}
+
+				adoptedAt, adopted := role.Annotations[AdoptedAnnotation]
+				if adopted != tt.wantAdopted {
+					t.Fatalf("apply %d: %s present = %v, want %v", i+1, AdoptedAnnotation, adopted, tt.wantAdopted)
+				}
+				if adopted {
+					if _, err := time.Parse(time.RFC3339, adoptedAt); err != nil {
+						t.Errorf("apply %d: %s = %q, want an RFC 3339 time", i+1, AdoptedAnnotation, adoptedAt)
+					}
+					if i == 0 {
+						firstAdoptedAt = adoptedAt
+					} else if adoptedAt != firstAdoptedAt {
+						t.Errorf("apply %d: %s = %q, want it kept as %q", i+1, AdoptedAnnotation, adoptedAt, firstAdoptedAt)
+					}
+				}
+				if role.Labels[OwnerLabel] == "" {
+					t.Errorf("apply %d: role not labelled as owned: %v", i+1, role.Labels)
+				}
+			}
+
+			want := 0.0
+			if tt.wantAdopted {
+				want = 1
+			}
+			if got := testutil.ToFloat64(adoptions); got != want {
+				t.Errorf("rbac_operator_resources_adopted_total = %v, want %v", got, want)
+			}
+		})
+	}
+}
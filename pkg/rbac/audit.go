@@ -0,0 +1,142 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rbac
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// AuditEntry describes a single write performed by the Manager
+type AuditEntry struct {
+	Action    string    `json:"action"`
+	Kind      string    `json:"kind"`
+	Name      string    `json:"name"`
+	Namespace string    `json:"namespace,omitempty"`
+	Config    string    `json:"config,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// AuditSink receives an entry for every successful write performed by the Manager.
+// Implementations must be safe for concurrent use.
+type AuditSink interface {
+	Record(ctx context.Context, entry AuditEntry) error
+}
+
+// JSONAuditSink writes each entry as one JSON line, giving an append-only record
+type JSONAuditSink struct {
+	mu     sync.Mutex
+	writer io.Writer
+}
+
+// NewJSONAuditSink creates an AuditSink writing JSON lines to w
+func NewJSONAuditSink(w io.Writer) *JSONAuditSink {
+	return &JSONAuditSink{writer: w}
+}
+
+// Record writes the entry as a single JSON line
+func (s *JSONAuditSink) Record(ctx context.Context, entry AuditEntry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err = s.writer.Write(append(line, '\n'))
+	return err
+}
+
+// auditingClient passes every call through to the wrapped client and reports
+// successful writes to the audit sink
+type auditingClient struct {
+	client.Client
+	sink AuditSink
+}
+
+// Create creates the object and records the write
+func (c *auditingClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	if err := c.Client.Create(ctx, obj, opts...); err != nil {
+		return err
+	}
+	c.record(ctx, "create", obj)
+	return nil
+}
+
+// Update updates the object and records the write
+func (c *auditingClient) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	if err := c.Client.Update(ctx, obj, opts...); err != nil {
+		return err
+	}
+	c.record(ctx, "update", obj)
+	return nil
+}
+
+// Patch patches the object and records the write
+func (c *auditingClient) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	if err := c.Client.Patch(ctx, obj, patch, opts...); err != nil {
+		return err
+	}
+	c.record(ctx, "patch", obj)
+	return nil
+}
+
+// Delete deletes the object and records the write
+func (c *auditingClient) Delete(ctx context.Context, obj client.Object, opts ...client.DeleteOption) error {
+	if err := c.Client.Delete(ctx, obj, opts...); err != nil {
+		return err
+	}
+	c.record(ctx, "delete", obj)
+	return nil
+}
+
+// DeleteAllOf deletes the matching objects and records the write
+func (c *auditingClient) DeleteAllOf(ctx context.Context, obj client.Object, opts ...client.DeleteAllOfOption) error {
+	if err := c.Client.DeleteAllOf(ctx, obj, opts...); err != nil {
+		return err
+	}
+	c.record(ctx, "deleteAllOf", obj)
+	return nil
+}
+
+// record sends an entry for the write to the sink. Sink failures are logged rather
+// than returned since the write itself has already happened.
+func (c *auditingClient) record(ctx context.Context, action string, obj client.Object) {
+	kind := fmt.Sprintf("%T", obj)
+	if gvk, err := apiutil.GVKForObject(obj, c.Scheme()); err == nil {
+		kind = gvk.Kind
+	}
+
+	entry := AuditEntry{
+		Action:    action,
+		Kind:      kind,
+		Name:      obj.GetName(),
+		Namespace: obj.GetNamespace(),
+		Config:    obj.GetLabels()[ConfigLabel],
+		Timestamp: time.Now().UTC(),
+	}
+	if err := c.sink.Record(ctx, entry); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to record audit entry", "action", action, "kind", kind, "name", entry.Name)
+	}
+}
@@ -0,0 +1,117 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rbac
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	rbacoperatorv1 "github.com/cropalato/k8s-acl-operator/pkg/apis/rbac/v1"
+)
+
+// captureSink records audit entries in memory
+type captureSink struct {
+	mu      sync.Mutex
+	entries []AuditEntry
+}
+
+func (s *captureSink) Record(ctx context.Context, entry AuditEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries = append(s.entries, entry)
+	return nil
+}
+
+func TestAuditSinkRecordsEveryWrite(t *testing.T) {
+	ns := testNamespace("team-a", map[string]string{"team": "a"})
+	sink := &captureSink{}
+	m := NewManager(newFakeClient(t, interceptor.Funcs{}, ns), Options{AuditSink: sink})
+
+	config := testConfig("cfg")
+	config.Spec.RBACTemplates.Roles = []rbacoperatorv1.RoleTemplate{{
+		Name:  "viewer",
+		Rules: []rbacv1.PolicyRule{{APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: []string{"get"}}},
+	}}
+	replace := rbacoperatorv1.MergeStrategyReplace
+	config.Spec.Config = &rbacoperatorv1.NamespaceRBACConfigConfig{MergeStrategy: &replace}
+
+	if _, err := m.ApplyRBACForNamespace(context.Background(), ns, config, []string{ns.Name}); err != nil {
+		t.Fatal(err)
+	}
+	config.Spec.RBACTemplates.Roles[0].Rules[0].Verbs = []string{"get", "list"}
+	if _, err := m.ApplyRBACForNamespace(context.Background(), ns, config, []string{ns.Name}); err != nil {
+		t.Fatal(err)
+	}
+	// Unchanged, so nothing is written or recorded
+	if _, err := m.ApplyRBACForNamespace(context.Background(), ns, config, []string{ns.Name}); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.CleanupRBACForNamespace(context.Background(), ns.Name, config, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	var actions []string
+	for _, entry := range sink.entries {
+		if entry.Kind != "Role" {
+			continue
+		}
+		if entry.Name != "viewer" || entry.Namespace != "team-a" || entry.Config != "cfg" {
+			t.Errorf("entry = %+v, want Role team-a/viewer of config cfg", entry)
+		}
+		actions = append(actions, entry.Action)
+	}
+	if want := []string{"create", "update", "delete"}; !reflect.DeepEqual(actions, want) {
+		t.Errorf("Role audit actions = %v, want %v", actions, want)
+	}
+}
+
+func TestJSONAuditSinkWritesOneLinePerEntry(t *testing.T) {
+	var buf bytes.Buffer
+	sink := NewJSONAuditSink(&buf)
+	entries := []AuditEntry{
+		{Action: "create", Kind: "Role", Name: "viewer", Namespace: "team-a", Config: "cfg", Timestamp: time.Unix(0, 0).UTC()},
+		{Action: "delete", Kind: "ClusterRole", Name: "viewer", Timestamp: time.Unix(1, 0).UTC()},
+	}
+	for _, entry := range entries {
+		if err := sink.Record(context.Background(), entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != len(entries) {
+		t.Fatalf("wrote %d lines, want %d:\n%s", len(lines), len(entries), buf.String())
+	}
+	for i, line := range lines {
+		var got AuditEntry
+		if err := json.Unmarshal([]byte(line), &got); err != nil {
+			t.Fatalf("line %d: %v", i, err)
+		}
+		if !reflect.DeepEqual(got, entries[i]) {
+			t.Errorf("line %d = %+v, want %+v", i, got, entries[i])
+		}
+	}
+}
@@ -0,0 +1,124 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rbac
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	rbacoperatorv1 "github.com/cropalato/k8s-acl-operator/pkg/apis/rbac/v1"
+	"github.com/cropalato/k8s-acl-operator/pkg/utils"
+)
+
+// detectDrift reports whether applying desired would restore a resource that was
+// deleted or modified outside the operator. Drift is only reported for namespaces the
+// config had already been applied to at its current generation; otherwise a missing or
+// different resource is expected (new namespace or spec change). In read-only mode the
+// operator never writes, so every difference would be reported again on each reconcile.
+func (m *Manager) detectDrift(ctx context.Context, desired client.Object, ns *corev1.Namespace, config *rbacoperatorv1.NamespaceRBACConfig, mergeStrategy rbacoperatorv1.MergeStrategy) bool {
+	if m.readOnly || mergeStrategy == rbacoperatorv1.MergeStrategyIgnore || !wasApplied(config, ns) {
+		return false
+	}
+
+	existing, ok := desired.DeepCopyObject().(client.Object)
+	if !ok {
+		return false
+	}
+	if err := m.Get(ctx, client.ObjectKeyFromObject(desired), existing); err != nil {
+		return errors.IsNotFound(err)
+	}
+
+	// Only operator-owned resources can drift; foreign ones are merge conflicts
+	if existing.GetLabels()[ConfigLabel] != config.Name || isMergeFrozen(existing) {
+		return false
+	}
+	return !contentMatches(existing, desired, mergeStrategy, subjectMatchIgnoreCase(config))
+}
+
+// wasApplied reports whether the config's last successful reconcile covered the
+// namespace at its current generation. The namespace's ManagedByConfigsAnnotation is
+// authoritative, since AppliedNamespaces may be truncated; the status list still counts
+// for namespaces applied before the annotation existed.
+func wasApplied(config *rbacoperatorv1.NamespaceRBACConfig, ns *corev1.Namespace) bool {
+	if config.Status.ObservedGeneration != config.Generation {
+		return false
+	}
+	return ManagedBy(ns, config.Name) || utils.SliceContains(config.Status.AppliedNamespaces, ns.Name)
+}
+
+// contentMatches compares the RBAC content of an existing resource with the desired
+// one. With the merge strategy the existing resource may carry extra rules or subjects
+// from other configs, so it only has to contain the desired ones.
+func contentMatches(existing, desired client.Object, mergeStrategy rbacoperatorv1.MergeStrategy, ignoreCase bool) bool {
+	merge := mergeStrategy == rbacoperatorv1.MergeStrategyMerge
+	switch want := desired.(type) {
+	case *rbacv1.Role:
+		return rulesMatch(existing.(*rbacv1.Role).Rules, want.Rules, merge)
+	case *rbacv1.ClusterRole:
+		return rulesMatch(existing.(*rbacv1.ClusterRole).Rules, want.Rules, merge)
+	case *rbacv1.RoleBinding:
+		have := existing.(*rbacv1.RoleBinding)
+		return have.RoleRef == want.RoleRef && subjectsMatch(have.Subjects, want.Subjects, merge, ignoreCase)
+	case *rbacv1.ClusterRoleBinding:
+		have := existing.(*rbacv1.ClusterRoleBinding)
+		return have.RoleRef == want.RoleRef && subjectsMatch(have.Subjects, want.Subjects, merge, ignoreCase)
+	}
+	return true
+}
+
+// rulesMatch reports whether have equals want, or contains every rule of want when merging
+func rulesMatch(have, want []rbacv1.PolicyRule, merge bool) bool {
+	if !merge {
+		return equality.Semantic.DeepEqual(have, want)
+	}
+	for _, rule := range want {
+		found := false
+		for _, existing := range have {
+			if equality.Semantic.DeepEqual(existing, rule) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// subjectsMatch reports whether have equals want, or contains every subject of want when
+// merging. ignoreCase compares merged subject names case-insensitively, as mergeSubjects does.
+func subjectsMatch(have, want []rbacv1.Subject, merge, ignoreCase bool) bool {
+	if !merge {
+		return equality.Semantic.DeepEqual(have, want)
+	}
+	present := make(map[string]bool, len(have))
+	for _, subject := range have {
+		present[subjectKey(subject, ignoreCase)] = true
+	}
+	for _, subject := range want {
+		if !present[subjectKey(subject, ignoreCase)] {
+			return false
+		}
+	}
+	return true
+}
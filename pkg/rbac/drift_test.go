@@ -0,0 +1,53 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rbac
+
+import (
+	"testing"
+)
+
+func TestWasApplied(t *testing.T) {
+	tests := []struct {
+		name               string
+		annotation         string
+		appliedNamespaces  []string
+		observedGeneration int64
+		want               bool
+	}{
+		{name: "never applied", observedGeneration: 1, want: false},
+		{name: "listed in status", appliedNamespaces: []string{"team-a"}, observedGeneration: 1, want: true},
+		{name: "summarised out of status but annotated", annotation: "other,cfg", appliedNamespaces: []string{"team-0", "… and 5 more"}, observedGeneration: 1, want: true},
+		{name: "annotated for another config", annotation: "other", observedGeneration: 1, want: false},
+		{name: "spec changed since", annotation: "cfg", observedGeneration: 0, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ns := testNamespace("team-a", nil)
+			if tt.annotation != "" {
+				ns.Annotations = map[string]string{ManagedByConfigsAnnotation: tt.annotation}
+			}
+			config := testConfig("cfg")
+			config.Status.AppliedNamespaces = tt.appliedNamespaces
+			config.Status.ObservedGeneration = tt.observedGeneration
+
+			if got := wasApplied(config, ns); got != tt.want {
+				t.Errorf("wasApplied() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
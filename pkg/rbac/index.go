@@ -0,0 +1,69 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rbac
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	rbacoperatorv1 "github.com/cropalato/k8s-acl-operator/pkg/apis/rbac/v1"
+)
+
+// ConfigLabelIndex is the cache index over the ConfigLabel value of generated RBAC
+// resources. The informer cache only serves field selectors backed by an index, while
+// a label selector alone scans every cached object of the kind.
+const ConfigLabelIndex = "metadata.labels." + ConfigLabel
+
+// IndexConfigLabel registers ConfigLabelIndex for ServiceAccounts, Roles, ClusterRoles,
+// RoleBindings and ClusterRoleBindings. It must be called before the manager's cache starts; managers
+// then need Options.ConfigLabelIndexed so their lists use the index.
+func IndexConfigLabel(ctx context.Context, indexer client.FieldIndexer) error {
+	for _, obj := range []client.Object{&corev1.ServiceAccount{}, &rbacv1.Role{}, &rbacv1.ClusterRole{}, &rbacv1.RoleBinding{}, &rbacv1.ClusterRoleBinding{}} {
+		if err := indexer.IndexField(ctx, obj, ConfigLabelIndex, configLabelValue); err != nil {
+			return fmt.Errorf("failed to index %T by %s: %w", obj, ConfigLabel, err)
+		}
+	}
+	return nil
+}
+
+// configLabelValue extracts the ConfigLabel value; unlabelled objects are not indexed
+func configLabelValue(obj client.Object) []string {
+	if name, ok := obj.GetLabels()[ConfigLabel]; ok {
+		return []string{name}
+	}
+	return nil
+}
+
+// listOwned lists the config's generated resources of the list's kind, selected by
+// ConfigLabel plus labels and narrowed by opts, which must not set a label selector of
+// their own. With the index registered the cache looks them up directly instead of
+// filtering every cached object.
+func (m *Manager) listOwned(ctx context.Context, list client.ObjectList, config *rbacoperatorv1.NamespaceRBACConfig, labels map[string]string, opts ...client.ListOption) error {
+	selector := client.MatchingLabels{ConfigLabel: config.Name}
+	for key, value := range labels {
+		selector[key] = value
+	}
+	opts = append(opts, selector)
+	if m.configLabelIndexed {
+		opts = append(opts, client.MatchingFields{ConfigLabelIndex: config.Name})
+	}
+	return m.List(ctx, list, opts...)
+}
@@ -0,0 +1,102 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rbac
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+// builderIndexer registers indexes on a fake client builder
+type builderIndexer struct {
+	builder *fake.ClientBuilder
+}
+
+func (i builderIndexer) IndexField(_ context.Context, obj client.Object, field string, extract client.IndexerFunc) error {
+	i.builder.WithIndex(obj, field, extract)
+	return nil
+}
+
+func TestListOwnedByConfigLabel(t *testing.T) {
+	role := func(namespace, name string, labels map[string]string) *rbacv1.Role {
+		return &rbacv1.Role{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name, Labels: labels}}
+	}
+	objs := []client.Object{
+		role("team-a", "viewer", map[string]string{ConfigLabel: "cfg-a"}),
+		role("team-b", "viewer", map[string]string{ConfigLabel: "cfg-a"}),
+		role("team-a", "editor", map[string]string{ConfigLabel: "cfg-b"}),
+		role("team-a", "manual", nil),
+	}
+
+	tests := []struct {
+		name    string
+		indexed bool
+		config  string
+		want    []string
+	}{
+		{name: "label selector", config: "cfg-a", want: []string{"team-a/viewer", "team-b/viewer"}},
+		{name: "index", indexed: true, config: "cfg-a", want: []string{"team-a/viewer", "team-b/viewer"}},
+		{name: "index other config", indexed: true, config: "cfg-b", want: []string{"team-a/editor"}},
+		{name: "index unknown config", indexed: true, config: "cfg-c"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var usedIndex bool
+			builder := fake.NewClientBuilder().
+				WithScheme(newTestScheme(t)).
+				WithObjects(objs...).
+				WithInterceptorFuncs(interceptor.Funcs{
+					List: func(ctx context.Context, c client.WithWatch, list client.ObjectList, opts ...client.ListOption) error {
+						listOpts := (&client.ListOptions{}).ApplyOptions(opts)
+						usedIndex = listOpts.FieldSelector != nil
+						return c.List(ctx, list, opts...)
+					},
+				})
+			if tt.indexed {
+				if err := IndexConfigLabel(context.Background(), builderIndexer{builder}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			m := NewManager(builder.Build(), Options{ConfigLabelIndexed: tt.indexed})
+
+			roles := &rbacv1.RoleList{}
+			if err := m.listOwned(context.Background(), roles, testConfig(tt.config), nil); err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, r := range roles.Items {
+				got = append(got, r.Namespace+"/"+r.Name)
+			}
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("listOwned() = %v, want %v", got, tt.want)
+			}
+			if usedIndex != tt.indexed {
+				t.Errorf("list used %s = %v, want %v", ConfigLabelIndex, usedIndex, tt.indexed)
+			}
+		})
+	}
+}
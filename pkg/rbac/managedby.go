@@ -0,0 +1,98 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rbac
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/cropalato/k8s-acl-operator/pkg/metrics"
+	"github.com/cropalato/k8s-acl-operator/pkg/utils"
+)
+
+// updateManagedByConfigs adds configName to, or removes it from, the namespace's
+// ManagedByConfigsAnnotation. Only that annotation is patched, with an optimistic lock
+// so configs reconciling concurrently don't drop each other's entries.
+func (m *Manager) updateManagedByConfigs(ctx context.Context, namespaceName, configName string, add bool) error {
+	var err error
+	for i := 0; i < DefaultMaxConflictRetries; i++ {
+		ns := &corev1.Namespace{}
+		if err = m.Get(ctx, types.NamespacedName{Name: namespaceName}, ns); err != nil {
+			if errors.IsNotFound(err) {
+				return nil // Namespace is gone, nothing to record
+			}
+			return err
+		}
+
+		current := ns.Annotations[ManagedByConfigsAnnotation]
+		value := editConfigList(current, configName, add)
+		if value == current {
+			return nil
+		}
+
+		updated := ns.DeepCopy()
+		if value == "" {
+			delete(updated.Annotations, ManagedByConfigsAnnotation)
+		} else {
+			if updated.Annotations == nil {
+				updated.Annotations = make(map[string]string)
+			}
+			updated.Annotations[ManagedByConfigsAnnotation] = value
+		}
+
+		err = m.Patch(ctx, updated, client.MergeFromWithOptions(ns, client.MergeFromWithOptimisticLock{}), client.FieldOwner(m.fieldManager))
+		metrics.RecordResourceOperation(configName, "namespace", "patch", err)
+		if err == nil || !errors.IsConflict(err) {
+			return err
+		}
+	}
+	return err
+}
+
+// ManagedBy reports whether the namespace's ManagedByConfigsAnnotation lists the config.
+// Unlike a config's bounded AppliedNamespaces, it records every namespace RBAC was applied to.
+func ManagedBy(ns *corev1.Namespace, configName string) bool {
+	for _, name := range strings.Split(ns.Annotations[ManagedByConfigsAnnotation], ",") {
+		if strings.TrimSpace(name) == configName {
+			return true
+		}
+	}
+	return false
+}
+
+// editConfigList adds name to, or removes it from, a comma-separated config list and
+// returns the result sorted and deduplicated
+func editConfigList(list, name string, add bool) string {
+	names := make([]string, 0)
+	for _, n := range strings.Split(list, ",") {
+		if n = strings.TrimSpace(n); n != "" && n != name {
+			names = append(names, n)
+		}
+	}
+	if add {
+		names = append(names, name)
+	}
+	names = utils.UniqueSlice(names)
+	sort.Strings(names)
+	return strings.Join(names, ",")
+}
@@ -0,0 +1,103 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rbac
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+func TestEditConfigList(t *testing.T) {
+	tests := []struct {
+		name string
+		list string
+		add  bool
+		want string
+	}{
+		{name: "add to empty", list: "", add: true, want: "cfg"},
+		{name: "add sorts", list: "zeta,alpha", add: true, want: "alpha,cfg,zeta"},
+		{name: "add existing", list: "cfg", add: true, want: "cfg"},
+		{name: "remove", list: "alpha,cfg", want: "alpha"},
+		{name: "remove last", list: "cfg", want: ""},
+		{name: "remove absent trims and dedups", list: " alpha, alpha ,,beta", want: "alpha,beta"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := editConfigList(tt.list, "cfg", tt.add); got != tt.want {
+				t.Errorf("editConfigList(%q, add=%v) = %q, want %q", tt.list, tt.add, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestManagedByConfigsAnnotation(t *testing.T) {
+	const foreign = "other-operator.io/owner"
+
+	tests := []struct {
+		name    string
+		initial string
+		cleanup bool
+		want    string
+	}{
+		{name: "apply adds", want: "cfg"},
+		{name: "apply keeps other configs", initial: "alpha,zeta", want: "alpha,cfg,zeta"},
+		{name: "cleanup prunes", initial: "alpha", cleanup: true, want: "alpha"},
+		{name: "cleanup of the last config removes the annotation", cleanup: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ns := testNamespace("team-a", map[string]string{"team": "a"})
+			ns.Annotations = map[string]string{foreign: "keep"}
+			if tt.initial != "" {
+				ns.Annotations[ManagedByConfigsAnnotation] = tt.initial
+			}
+			c := newFakeClient(t, interceptor.Funcs{}, ns)
+			m := NewManager(c, Options{})
+			config := testConfig("cfg")
+
+			if _, err := m.ApplyRBACForNamespace(context.Background(), ns, config, []string{ns.Name}); err != nil {
+				t.Fatal(err)
+			}
+			if tt.cleanup {
+				if err := m.CleanupRBACForNamespace(context.Background(), ns.Name, config, nil); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			stored := &corev1.Namespace{}
+			if err := c.Get(context.Background(), client.ObjectKeyFromObject(ns), stored); err != nil {
+				t.Fatal(err)
+			}
+			got, ok := stored.Annotations[ManagedByConfigsAnnotation]
+			if got != tt.want || ok != (tt.want != "") {
+				t.Errorf("%s = %q (present %v), want %q", ManagedByConfigsAnnotation, got, ok, tt.want)
+			}
+			if ManagedBy(stored, "cfg") == tt.cleanup {
+				t.Errorf("ManagedBy(cfg) = %v, want %v", !tt.cleanup, !tt.cleanup)
+			}
+			if stored.Annotations[foreign] != "keep" {
+				t.Errorf("foreign annotation clobbered: %v", stored.Annotations)
+			}
+		})
+	}
+}
@@ -22,20 +22,32 @@ package rbac
 
 import (
 	"context"
+	goerrors "errors"
 	"fmt"
+	"sort"
+	"strings"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/client-go/discovery"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/yaml"
 
 	rbacoperatorv1 "github.com/cropalato/k8s-acl-operator/pkg/apis/rbac/v1"
 	"github.com/cropalato/k8s-acl-operator/pkg/metrics"
 	"github.com/cropalato/k8s-acl-operator/pkg/template"
+	"github.com/cropalato/k8s-acl-operator/pkg/tracing"
+	"github.com/cropalato/k8s-acl-operator/pkg/utils"
 )
 
 const (
@@ -45,85 +57,837 @@ const (
 	ConfigLabel = "rbac.operator.io/config"
 	// NamespaceLabel references the target namespace for cluster-scoped resources
 	NamespaceLabel = "rbac.operator.io/namespace"
+	// DefaultFieldManager is the field manager recorded on writes unless overridden
+	DefaultFieldManager = "rbac-operator"
+	// DefaultMaxConflictRetries is how many times an update is retried on conflict
+	DefaultMaxConflictRetries = 3
+
+	// Kinds of RBAC resources managed by the operator
+	KindServiceAccount     = "ServiceAccount"
+	KindRole               = "Role"
+	KindClusterRole        = "ClusterRole"
+	KindRoleBinding        = "RoleBinding"
+	KindClusterRoleBinding = "ClusterRoleBinding"
+
+	// MergeFreezeAnnotation on an existing resource set to "true" prevents the
+	// operator from merging into or updating it, regardless of merge strategy
+	MergeFreezeAnnotation = "rbac.operator.io/merge-freeze"
+
+	// TemplateChecksumAnnotation records the hash of the template that produced a
+	// resource, so resources that are stale relative to their template can be found
+	TemplateChecksumAnnotation = "rbac.operator.io/template-checksum"
+
+	// ManagedByConfigsAnnotation on a namespace lists, comma-separated and sorted, the
+	// NamespaceRBACConfigs that currently apply RBAC to it
+	ManagedByConfigsAnnotation = "rbac.operator.io/managed-by-configs"
+
+	// AdoptedAnnotation records when the operator took over a pre-existing resource it
+	// did not create, i.e. one updated while it lacked the OwnerLabel
+	AdoptedAnnotation = "rbac.operator.io/adopted"
 )
 
+// DefaultApplyOrder is the order RBAC kinds are applied in unless Config.ApplyOrder overrides it.
+// ServiceAccounts come first so bindings never reference a subject that does not exist yet.
+var DefaultApplyOrder = []string{KindServiceAccount, KindRole, KindClusterRole, KindRoleBinding, KindClusterRoleBinding}
+
+// errMergeFrozen is returned by the createOrUpdate helpers when the existing
+// resource carries MergeFreezeAnnotation and was left untouched
+var errMergeFrozen = fmt.Errorf("resource is frozen by %s annotation", MergeFreezeAnnotation)
+
+// errUnchanged is returned by the createOrUpdate helpers when the existing resource
+// already matches the desired state and the Update was skipped
+var errUnchanged = goerrors.New("resource is unchanged")
+
+// ErrRBACAPIUnavailable wraps errors caused by the rbac.authorization.k8s.io API group
+// not being served (no REST mapping or failed discovery)
+var ErrRBACAPIUnavailable = goerrors.New("RBAC API unavailable")
+
+// IsAPIUnavailable reports whether err was caused by the RBAC API group being unavailable
+func IsAPIUnavailable(err error) bool {
+	return goerrors.Is(err, ErrRBACAPIUnavailable)
+}
+
+// wrapAPIUnavailable marks no-match and discovery errors with ErrRBACAPIUnavailable;
+// other errors are returned unchanged
+func wrapAPIUnavailable(err error) error {
+	if meta.IsNoMatchError(err) || discovery.IsGroupDiscoveryFailedError(err) {
+		return fmt.Errorf("%w: %w", ErrRBACAPIUnavailable, err)
+	}
+	return err
+}
+
+// ErrEscalationDenied wraps forbidden errors on RBAC writes. The operator holds full
+// CRUD rights on RBAC resources, so a forbidden write means the API server's
+// escalation prevention rejected rules or bindings the operator does not hold itself.
+var ErrEscalationDenied = goerrors.New("RBAC escalation denied")
+
+// IsEscalationDenied reports whether err was caused by RBAC escalation prevention
+func IsEscalationDenied(err error) bool {
+	return goerrors.Is(err, ErrEscalationDenied)
+}
+
+// wrapEscalationDenied marks forbidden errors with ErrEscalationDenied and names the
+// rejected resource; other errors are returned unchanged
+func wrapEscalationDenied(err error, resource string) error {
+	if errors.IsForbidden(err) {
+		return fmt.Errorf("%w for %s: %w", ErrEscalationDenied, resource, err)
+	}
+	return err
+}
+
+// ApplyResult reports per-namespace outcomes that callers may surface in status
+type ApplyResult struct {
+	// FrozenResources lists existing resources skipped due to MergeFreezeAnnotation
+	FrozenResources []string
+	// DriftCorrected lists resources restored after being deleted or modified outside the operator
+	DriftCorrected []string
+	// LintWarnings describes suspicious rendered output, such as empty names (the
+	// resource is skipped) or empty label values
+	LintWarnings []string
+	// Resources holds the rendered resources in apply order, before any merge with
+	// existing objects
+	Resources []client.Object
+}
+
+// Options configures a Manager
+type Options struct {
+	// TemplateSettings are operator-level values exposed to templates as .Settings
+	TemplateSettings map[string]string
+	// ReadOnly evaluates configs as usual but logs RBAC writes instead of performing them.
+	// Drift is not detected, since nothing would restore it.
+	ReadOnly bool
+	// FieldManager names the operator in managedFields on every write; defaults to DefaultFieldManager
+	FieldManager string
+	// OperatorNamespace is excluded from every config unless it sets AllowOperatorNamespace
+	OperatorNamespace string
+	// OperatorServiceAccount is the name of the operator's ServiceAccount in OperatorNamespace.
+	// ClusterRoleBindings granting it are never deleted by cleanup.
+	OperatorServiceAccount string
+	// AuditSink, if set, receives an entry for every RBAC write; unset disables auditing
+	AuditSink AuditSink
+	// ConfigLabelIndexed reports that IndexConfigLabel was registered on the cache backing
+	// the client, so lists of generated resources may select by ConfigLabelIndex
+	ConfigLabelIndexed bool
+}
+
 // Manager handles RBAC resource creation and management.
 // It processes templates from NamespaceRBACConfig resources and applies them
 // to namespaces, handling conflicts through configurable merge strategies.
 // The manager ensures proper labeling and ownership of created resources.
 type Manager struct {
-	client.Client                   // Kubernetes API client for CRUD operations
-	templateEngine *template.Engine // Template processor for variable substitution
+	client.Client                       // Kubernetes API client for CRUD operations
+	templateEngine     *template.Engine // Template processor for variable substitution
+	fieldManager       string           // Field manager recorded on writes
+	operatorNS         string           // Operator's own namespace, excluded by default
+	operatorSA         string           // Operator's own ServiceAccount, protected from cleanup
+	configLabelIndexed bool             // Lists of generated resources may use ConfigLabelIndex
+	readOnly           bool             // Writes are only logged, so existing resources never converge
 }
 
 // NewManager creates a new RBAC manager
-func NewManager(client client.Client) *Manager {
+func NewManager(client client.Client, opts Options) *Manager {
+	// Auditing wraps the real client so writes suppressed in read-only mode are not recorded
+	if opts.AuditSink != nil {
+		client = &auditingClient{Client: client, sink: opts.AuditSink}
+	}
+	if opts.ReadOnly {
+		client = &readOnlyClient{Client: client}
+	}
+	fieldManager := opts.FieldManager
+	if fieldManager == "" {
+		fieldManager = DefaultFieldManager
+	}
 	return &Manager{
-		Client:         client,
-		templateEngine: template.NewEngine(),
+		Client:             client,
+		templateEngine:     template.NewEngine(opts.TemplateSettings),
+		fieldManager:       fieldManager,
+		operatorNS:         opts.OperatorNamespace,
+		operatorSA:         opts.OperatorServiceAccount,
+		configLabelIndexed: opts.ConfigLabelIndexed,
+		readOnly:           opts.ReadOnly,
 	}
 }
 
+// NamespaceMatches reports whether the config applies to the namespace. On top of the
+// config's selector, the operator's own namespace is skipped to avoid locking the
+// operator out, unless the config sets AllowOperatorNamespace, and namespaces for
+// which the selector's SkipWhen template renders truthy are excluded.
+func (m *Manager) NamespaceMatches(ctx context.Context, ns *corev1.Namespace, config *rbacoperatorv1.NamespaceRBACConfig) (bool, error) {
+	if m.operatorNS != "" && ns.Name == m.operatorNS {
+		if config.Spec.Config == nil || !utils.BoolPtrValue(config.Spec.Config.AllowOperatorNamespace) {
+			return false, nil
+		}
+	}
+	matches, err := utils.NamespaceMatches(ns, config.Spec.NamespaceSelector)
+	if err != nil || !matches {
+		return matches, err
+	}
+	if want := config.Spec.NamespaceSelector.HasResourceQuota; want != nil {
+		has, err := m.hasResourceQuota(ctx, ns.Name)
+		if err != nil {
+			return false, err
+		}
+		if has != *want {
+			return false, nil
+		}
+	}
+	if config.Spec.NamespaceSelector.SkipWhen == "" {
+		return true, nil
+	}
+
+	// matchingNamespaces is unavailable here: it is what is being computed. Matching runs
+	// for every namespace event, so render unmetered to keep the function call metric
+	// tied to applies.
+	engine := m.templateEngine.Unmetered()
+	rendered, err := engine.ProcessTemplate(config.Spec.NamespaceSelector.SkipWhen, engine.BuildContext(ns, config, nil))
+	if err != nil {
+		return false, fmt.Errorf("failed to process skipWhen template: %w", err)
+	}
+	return !isTruthy(rendered), nil
+}
+
+// NamespaceReady reports whether RBAC may be applied to a matching namespace. It is
+// false while Config.WaitForNamespaceAnnotation is set and the namespace does not carry
+// that annotation with the value "true".
+func NamespaceReady(ns *corev1.Namespace, config *rbacoperatorv1.NamespaceRBACConfig) bool {
+	if config.Spec.Config == nil || config.Spec.Config.WaitForNamespaceAnnotation == "" {
+		return true
+	}
+	return ns.Annotations[config.Spec.Config.WaitForNamespaceAnnotation] == "true"
+}
+
+// hasResourceQuota reports whether the namespace contains at least one ResourceQuota
+func (m *Manager) hasResourceQuota(ctx context.Context, namespace string) (bool, error) {
+	quotas := &corev1.ResourceQuotaList{}
+	if err := m.List(ctx, quotas, client.InNamespace(namespace)); err != nil {
+		return false, fmt.Errorf("failed to list resource quotas: %w", err)
+	}
+	return len(quotas.Items) > 0, nil
+}
+
+// isTruthy reports whether rendered template output counts as true: anything but
+// empty output, "false", "0" or "no", ignoring case and surrounding whitespace
+func isTruthy(rendered string) bool {
+	switch strings.ToLower(strings.TrimSpace(rendered)) {
+	case "", "false", "0", "no":
+		return false
+	}
+	return true
+}
+
 // ApplyRBACForNamespace applies all RBAC templates from a config to a specific namespace.
 // It processes roles, cluster roles, role bindings, and cluster role bindings in sequence.
 // Template variables are substituted with actual namespace metadata and config values.
+// Resources frozen via MergeFreezeAnnotation are skipped and reported in the result.
+// matchingNamespaces are the sorted names of all namespaces the config matches, exposed
+// to templates; callers compute them once per reconcile rather than once per namespace.
 // Returns error if any resource creation/update fails.
-func (m *Manager) ApplyRBACForNamespace(ctx context.Context, ns *corev1.Namespace, config *rbacoperatorv1.NamespaceRBACConfig) error {
-	templateCtx := m.templateEngine.BuildContext(ns, config)
+func (m *Manager) ApplyRBACForNamespace(ctx context.Context, ns *corev1.Namespace, config *rbacoperatorv1.NamespaceRBACConfig, matchingNamespaces []string) (*ApplyResult, error) {
+	ctx, span := tracing.Start(ctx, "rbac.ApplyRBACForNamespace",
+		tracing.ConfigKey.String(config.Name), tracing.NamespaceKey.String(ns.Name))
+	defer span.End()
+
+	// The allowlist is enforced at render time as well as during validation, so no
+	// caller of the manager can render a disallowed function
+	if errs := m.checkAllowedFunctions(config); len(errs) > 0 {
+		return nil, errs.ToAggregate()
+	}
+
+	templateCtx := m.templateEngine.BuildContext(ns, config, matchingNamespaces)
+	result := &ApplyResult{}
+
+	// The merge strategy may be a template evaluated per namespace
+	mergeStrategy, err := m.resolveMergeStrategy(config, templateCtx)
+	if err != nil {
+		return nil, err
+	}
+
+	phases := map[string]func() error{
+		KindServiceAccount: func() error {
+			for _, serviceAccountTemplate := range config.Spec.RBACTemplates.ServiceAccounts {
+				if err := m.applyServiceAccount(ctx, ns, config, serviceAccountTemplate, templateCtx, mergeStrategy, result); err != nil {
+					return fmt.Errorf("failed to apply service account %s: %w", serviceAccountTemplate.Name, err)
+				}
+			}
+			return nil
+		},
+		KindRole: func() error {
+			for _, roleTemplate := range config.Spec.RBACTemplates.Roles {
+				if err := m.applyRole(ctx, ns, config, roleTemplate, templateCtx, mergeStrategy, result); err != nil {
+					return fmt.Errorf("failed to apply role %s: %w", roleTemplate.Name, err)
+				}
+			}
+			return nil
+		},
+		KindClusterRole: func() error {
+			for _, clusterRoleTemplate := range config.Spec.RBACTemplates.ClusterRoles {
+				if err := m.applyClusterRole(ctx, ns, config, clusterRoleTemplate, templateCtx, mergeStrategy, result); err != nil {
+					return fmt.Errorf("failed to apply cluster role %s: %w", clusterRoleTemplate.Name, err)
+				}
+			}
+			return nil
+		},
+		KindRoleBinding: func() error {
+			for _, roleBindingTemplate := range config.Spec.RBACTemplates.RoleBindings {
+				if err := m.applyRoleBinding(ctx, ns, config, roleBindingTemplate, templateCtx, mergeStrategy, result); err != nil {
+					return fmt.Errorf("failed to apply role binding %s: %w", roleBindingTemplate.Name, err)
+				}
+			}
+			return nil
+		},
+		KindClusterRoleBinding: func() error {
+			for _, clusterRoleBindingTemplate := range config.Spec.RBACTemplates.ClusterRoleBindings {
+				if err := m.applyClusterRoleBinding(ctx, ns, config, clusterRoleBindingTemplate, templateCtx, mergeStrategy, result); err != nil {
+					return fmt.Errorf("failed to apply cluster role binding %s: %w", clusterRoleBindingTemplate.Name, err)
+				}
+			}
+			return nil
+		},
+	}
+
+	// Apply each kind in the configured order
+	for _, kind := range applyOrder(config) {
+		if err := phases[kind](); err != nil {
+			return nil, err
+		}
+	}
+
+	// Apply namespace labels/annotations
+	if err := m.applyNamespaceMetadata(ctx, ns, config, templateCtx, mergeStrategy); err != nil {
+		return nil, fmt.Errorf("failed to apply namespace metadata: %w", err)
+	}
+	if err := m.updateManagedByConfigs(ctx, ns.Name, config.Name, true); err != nil {
+		return nil, fmt.Errorf("failed to record managing config on namespace: %w", err)
+	}
+
+	// Prune bindings left pointing at roles that no longer exist
+	if err := m.deleteDanglingBindings(ctx, ns.Name, config, result.Resources); err != nil {
+		return nil, fmt.Errorf("failed to delete dangling bindings: %w", err)
+	}
+
+	return result, nil
+}
+
+// applyNamespaceMetadata stamps the configured labels and annotations onto the target
+// namespace. Keys already holding a different value are only overwritten with the
+// replace strategy, so manual or foreign values are not clobbered.
+func (m *Manager) applyNamespaceMetadata(ctx context.Context, ns *corev1.Namespace, config *rbacoperatorv1.NamespaceRBACConfig, templateCtx *template.TemplateContext, mergeStrategy rbacoperatorv1.MergeStrategy) error {
+	if !hasNamespaceMetadata(config) {
+		return nil
+	}
+
+	start := time.Now()
+	labels, err := m.templateEngine.ProcessMap(config.Spec.Config.NamespaceLabels, templateCtx)
+	metrics.RecordTemplateProcessing(config.Name, "namespace_labels", time.Since(start), err)
+	if err != nil {
+		return fmt.Errorf("failed to process namespace labels: %w", err)
+	}
+
+	start = time.Now()
+	annotations, err := m.templateEngine.ProcessMap(config.Spec.Config.NamespaceAnnotations, templateCtx)
+	metrics.RecordTemplateProcessing(config.Name, "namespace_annotations", time.Since(start), err)
+	if err != nil {
+		return fmt.Errorf("failed to process namespace annotations: %w", err)
+	}
+
+	updated := ns.DeepCopy()
+	var labelsChanged, annotationsChanged bool
+	updated.Labels, labelsChanged = stampMetadata(updated.Labels, labels, mergeStrategy, config.Name)
+	updated.Annotations, annotationsChanged = stampMetadata(updated.Annotations, annotations, mergeStrategy, config.Name)
+	if !labelsChanged && !annotationsChanged {
+		return nil
+	}
+
+	err = m.Patch(ctx, updated, client.MergeFrom(ns), client.FieldOwner(m.fieldManager))
+	metrics.RecordResourceOperation(config.Name, "namespace", "patch", err)
+	return err
+}
 
-	// Apply Roles
-	for _, roleTemplate := range config.Spec.RBACTemplates.Roles {
-		if err := m.applyRole(ctx, ns, config, roleTemplate, templateCtx); err != nil {
-			return fmt.Errorf("failed to apply role %s: %w", roleTemplate.Name, err)
+// cleanupNamespaceMetadata removes labels and annotations stamped by the config from a
+// namespace that is no longer managed. Only keys still holding the rendered value are
+// removed, so values changed by hand are preserved.
+func (m *Manager) cleanupNamespaceMetadata(ctx context.Context, namespaceName string, config *rbacoperatorv1.NamespaceRBACConfig, matchingNamespaces []string) error {
+	if !hasNamespaceMetadata(config) {
+		return nil
+	}
+
+	ns := &corev1.Namespace{}
+	if err := m.Get(ctx, types.NamespacedName{Name: namespaceName}, ns); err != nil {
+		if errors.IsNotFound(err) {
+			return nil // Namespace is gone, nothing to clean up
+		}
+		return err
+	}
+
+	templateCtx := m.templateEngine.BuildContext(ns, config, matchingNamespaces)
+
+	labels, err := m.templateEngine.ProcessMap(config.Spec.Config.NamespaceLabels, templateCtx)
+	if err != nil {
+		return fmt.Errorf("failed to process namespace labels: %w", err)
+	}
+	annotations, err := m.templateEngine.ProcessMap(config.Spec.Config.NamespaceAnnotations, templateCtx)
+	if err != nil {
+		return fmt.Errorf("failed to process namespace annotations: %w", err)
+	}
+
+	updated := ns.DeepCopy()
+	labelsChanged := unstampMetadata(updated.Labels, labels)
+	annotationsChanged := unstampMetadata(updated.Annotations, annotations)
+	if !labelsChanged && !annotationsChanged {
+		return nil
+	}
+
+	err = m.Patch(ctx, updated, client.MergeFrom(ns), client.FieldOwner(m.fieldManager))
+	metrics.RecordCleanup("namespace", err)
+	return err
+}
+
+// hasNamespaceMetadata reports whether the config stamps labels or annotations on namespaces
+func hasNamespaceMetadata(config *rbacoperatorv1.NamespaceRBACConfig) bool {
+	return config.Spec.Config != nil &&
+		(len(config.Spec.Config.NamespaceLabels) > 0 || len(config.Spec.Config.NamespaceAnnotations) > 0)
+}
+
+// stampMetadata copies desired entries into target. Existing keys with a different value
+// are left untouched unless the strategy is replace. Returns the (possibly allocated)
+// target map and whether it was modified.
+func stampMetadata(target, desired map[string]string, strategy rbacoperatorv1.MergeStrategy, configName string) (map[string]string, bool) {
+	changed := false
+	for key, value := range desired {
+		current, exists := target[key]
+		if exists && current == value {
+			continue
+		}
+		if exists && strategy != rbacoperatorv1.MergeStrategyReplace {
+			metrics.RecordConflictResolution(configName, string(strategy), "namespace")
+			continue
+		}
+		if target == nil {
+			target = make(map[string]string)
 		}
+		target[key] = value
+		changed = true
 	}
+	return target, changed
+}
 
-	// Apply ClusterRoles
-	for _, clusterRoleTemplate := range config.Spec.RBACTemplates.ClusterRoles {
-		if err := m.applyClusterRole(ctx, ns, config, clusterRoleTemplate, templateCtx); err != nil {
-			return fmt.Errorf("failed to apply cluster role %s: %w", clusterRoleTemplate.Name, err)
+// unstampMetadata deletes keys from target whose value still equals the stamped value.
+// Returns whether target was modified.
+func unstampMetadata(target, stamped map[string]string) bool {
+	changed := false
+	for key, value := range stamped {
+		if current, exists := target[key]; exists && current == value {
+			delete(target, key)
+			changed = true
 		}
 	}
+	return changed
+}
+
+// MatchingNamespaces returns the sorted names of all namespaces matching the config selector
+func (m *Manager) MatchingNamespaces(ctx context.Context, config *rbacoperatorv1.NamespaceRBACConfig) ([]string, error) {
+	namespaceList := &corev1.NamespaceList{}
+	if err := m.List(ctx, namespaceList); err != nil {
+		return nil, fmt.Errorf("failed to list namespaces: %w", err)
+	}
+	return m.matchingNamespaceNames(ctx, namespaceList.Items, config)
+}
 
-	// Apply RoleBindings
-	for _, roleBindingTemplate := range config.Spec.RBACTemplates.RoleBindings {
-		if err := m.applyRoleBinding(ctx, ns, config, roleBindingTemplate, templateCtx); err != nil {
-			return fmt.Errorf("failed to apply role binding %s: %w", roleBindingTemplate.Name, err)
+// matchingNamespaceNames returns the sorted names of the namespaces matching the config selector
+func (m *Manager) matchingNamespaceNames(ctx context.Context, namespaces []corev1.Namespace, config *rbacoperatorv1.NamespaceRBACConfig) ([]string, error) {
+	names := make([]string, 0)
+	for i := range namespaces {
+		matches, err := m.NamespaceMatches(ctx, &namespaces[i], config)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check namespace match: %w", err)
+		}
+		if matches {
+			names = append(names, namespaces[i].Name)
 		}
 	}
+	sort.Strings(names)
+
+	return names, nil
+}
 
-	// Apply ClusterRoleBindings
-	for _, clusterRoleBindingTemplate := range config.Spec.RBACTemplates.ClusterRoleBindings {
-		if err := m.applyClusterRoleBinding(ctx, ns, config, clusterRoleBindingTemplate, templateCtx); err != nil {
-			return fmt.Errorf("failed to apply cluster role binding %s: %w", clusterRoleBindingTemplate.Name, err)
+// ValidateTemplates checks every template string in the config for functions outside
+// the config's allowlist and for references to fields that do not exist in the
+// template context
+func (m *Manager) ValidateTemplates(config *rbacoperatorv1.NamespaceRBACConfig) field.ErrorList {
+	errs := m.checkAllowedFunctions(config)
+	for _, t := range templateStrings(config) {
+		if err := m.templateEngine.ValidateTemplateFields(t.value); err != nil {
+			errs = append(errs, field.Invalid(t.path, t.value, err.Error()))
 		}
 	}
+	return errs
+}
 
-	return nil
+// checkAllowedFunctions reports every template in the config that calls a template
+// function outside Config.AllowedTemplateFunctions, when that list is set
+func (m *Manager) checkAllowedFunctions(config *rbacoperatorv1.NamespaceRBACConfig) field.ErrorList {
+	if config.Spec.Config == nil || config.Spec.Config.AllowedTemplateFunctions == nil {
+		return nil
+	}
+
+	var errs field.ErrorList
+	for _, t := range templateStrings(config) {
+		if err := m.templateEngine.CheckAllowedFunctions(t.value, config.Spec.Config.AllowedTemplateFunctions); err != nil {
+			errs = append(errs, field.Invalid(t.path, t.value, err.Error()))
+		}
+	}
+	return errs
+}
+
+// templateString is a template string in the config and its field path
+type templateString struct {
+	path  *field.Path
+	value string
+}
+
+// templateStrings returns every template string in the config, sorted by field path so
+// errors are reported in a stable order
+func templateStrings(config *rbacoperatorv1.NamespaceRBACConfig) []templateString {
+	templates := make([]templateString, 0)
+	add := func(path *field.Path, value string) {
+		templates = append(templates, templateString{path: path, value: value})
+	}
+	addMap := func(path *field.Path, values map[string]string) {
+		for k, v := range values {
+			add(path.Key(k), v)
+		}
+	}
+	addSubjects := func(path *field.Path, subjects []rbacv1.Subject) {
+		for i, subject := range subjects {
+			add(path.Child("subjects").Index(i).Child("name"), subject.Name)
+			add(path.Child("subjects").Index(i).Child("namespace"), subject.Namespace)
+		}
+	}
+
+	specPath := field.NewPath("spec")
+	templatesPath := specPath.Child("rbacTemplates")
+	for i, t := range config.Spec.RBACTemplates.ServiceAccounts {
+		path := templatesPath.Child("serviceAccounts").Index(i)
+		add(path.Child("name"), t.Name)
+		addMap(path.Child("labels"), t.Labels)
+		addMap(path.Child("annotations"), t.Annotations)
+	}
+	for i, t := range config.Spec.RBACTemplates.Roles {
+		path := templatesPath.Child("roles").Index(i)
+		add(path.Child("name"), t.Name)
+		addMap(path.Child("labels"), t.Labels)
+		addMap(path.Child("annotations"), t.Annotations)
+	}
+	for i, t := range config.Spec.RBACTemplates.ClusterRoles {
+		path := templatesPath.Child("clusterRoles").Index(i)
+		add(path.Child("name"), t.Name)
+		addMap(path.Child("labels"), t.Labels)
+		addMap(path.Child("annotations"), t.Annotations)
+	}
+	for i, t := range config.Spec.RBACTemplates.RoleBindings {
+		path := templatesPath.Child("roleBindings").Index(i)
+		add(path.Child("name"), t.Name)
+		add(path.Child("roleRef", "name"), t.RoleRef.Name)
+		addMap(path.Child("labels"), t.Labels)
+		addMap(path.Child("annotations"), t.Annotations)
+		addSubjects(path, t.Subjects)
+	}
+	for i, t := range config.Spec.RBACTemplates.ClusterRoleBindings {
+		path := templatesPath.Child("clusterRoleBindings").Index(i)
+		add(path.Child("name"), t.Name)
+		add(path.Child("roleRef", "name"), t.RoleRef.Name)
+		addMap(path.Child("labels"), t.Labels)
+		addMap(path.Child("annotations"), t.Annotations)
+		addSubjects(path, t.Subjects)
+	}
+	if config.Spec.NamespaceSelector.SkipWhen != "" {
+		add(specPath.Child("namespaceSelector", "skipWhen"), config.Spec.NamespaceSelector.SkipWhen)
+	}
+	if cfg := config.Spec.Config; cfg != nil {
+		configPath := specPath.Child("config")
+		if cfg.MergeStrategy != nil {
+			add(configPath.Child("mergeStrategy"), string(*cfg.MergeStrategy))
+		}
+		addMap(configPath.Child("commonLabels"), cfg.CommonLabels)
+		addMap(configPath.Child("commonAnnotations"), cfg.CommonAnnotations)
+		addMap(configPath.Child("namespaceLabels"), cfg.NamespaceLabels)
+		addMap(configPath.Child("namespaceAnnotations"), cfg.NamespaceAnnotations)
+	}
+
+	sort.Slice(templates, func(i, j int) bool {
+		return templates[i].path.String() < templates[j].path.String()
+	})
+	return templates
+}
+
+// CheckDuplicateNames reports every template that renders to the same name as an
+// earlier template of the same kind. Names are rendered for the first of the matching
+// namespaces, which must be sorted by name; when none match, the raw name templates are
+// compared instead, which still catches duplicate static names. Templates that fail to
+// render are skipped and left for apply to report.
+func (m *Manager) CheckDuplicateNames(config *rbacoperatorv1.NamespaceRBACConfig, matching []corev1.Namespace) field.ErrorList {
+	templatesPath := field.NewPath("spec", "rbacTemplates")
+
+	render := func(nameTemplate string) (string, error) { return nameTemplate, nil }
+	target := "any namespace"
+	if len(matching) > 0 {
+		ns := &matching[0]
+		names := make([]string, 0, len(matching))
+		for i := range matching {
+			names = append(names, matching[i].Name)
+		}
+		templateCtx := m.templateEngine.BuildContext(ns, config, names)
+		engine := m.templateEngine.Unmetered()
+		render = func(nameTemplate string) (string, error) {
+			return resolveNameWith(engine, config, nameTemplate, templateCtx)
+		}
+		target = "namespace " + ns.Name
+	}
+
+	templates := config.Spec.RBACTemplates
+	names := map[string][]string{
+		"serviceAccounts":     make([]string, 0, len(templates.ServiceAccounts)),
+		"roles":               make([]string, 0, len(templates.Roles)),
+		"clusterRoles":        make([]string, 0, len(templates.ClusterRoles)),
+		"roleBindings":        make([]string, 0, len(templates.RoleBindings)),
+		"clusterRoleBindings": make([]string, 0, len(templates.ClusterRoleBindings)),
+	}
+	for _, t := range templates.ServiceAccounts {
+		names["serviceAccounts"] = append(names["serviceAccounts"], t.Name)
+	}
+	for _, t := range templates.Roles {
+		names["roles"] = append(names["roles"], t.Name)
+	}
+	for _, t := range templates.ClusterRoles {
+		names["clusterRoles"] = append(names["clusterRoles"], t.Name)
+	}
+	for _, t := range templates.RoleBindings {
+		names["roleBindings"] = append(names["roleBindings"], t.Name)
+	}
+	for _, t := range templates.ClusterRoleBindings {
+		names["clusterRoleBindings"] = append(names["clusterRoleBindings"], t.Name)
+	}
+
+	var errs field.ErrorList
+	for _, kind := range []string{"serviceAccounts", "roles", "clusterRoles", "roleBindings", "clusterRoleBindings"} {
+		seen := make(map[string]int)
+		for i, nameTemplate := range names[kind] {
+			name, err := render(nameTemplate)
+			if err != nil {
+				continue
+			}
+			if first, exists := seen[name]; exists {
+				errs = append(errs, field.Invalid(templatesPath.Child(kind).Index(i).Child("name"), nameTemplate,
+					fmt.Sprintf("renders to name %q for %s, like %s", name, target, templatesPath.Child(kind).Index(first))))
+				continue
+			}
+			seen[name] = i
+		}
+	}
+
+	return errs
+}
+
+// ValidateClusterRoleScopes checks that ClusterRole templates marked perNamespace render
+// a namespace-unique name, and that those marked shared render the same name everywhere
+func (m *Manager) ValidateClusterRoleScopes(config *rbacoperatorv1.NamespaceRBACConfig) field.ErrorList {
+	var errs field.ErrorList
+	clusterRolesPath := field.NewPath("spec", "rbacTemplates", "clusterRoles")
+	for i, t := range config.Spec.RBACTemplates.ClusterRoles {
+		if t.PerNamespace == nil {
+			continue
+		}
+		varies, err := m.templateEngine.VariesByNamespace(t.Name)
+		if err != nil {
+			continue // Rendering errors are reported at apply time
+		}
+		if getNamingStrategy(config) == rbacoperatorv1.NamingStrategyHashed {
+			varies = true // The namespace is part of every hashed name
+		}
+		if *t.PerNamespace && !varies {
+			errs = append(errs, field.Invalid(clusterRolesPath.Index(i).Child("perNamespace"), true,
+				fmt.Sprintf("name %q is the same for every namespace", t.Name)))
+		}
+		if !*t.PerNamespace && varies {
+			errs = append(errs, field.Invalid(clusterRolesPath.Index(i).Child("perNamespace"), false,
+				fmt.Sprintf("name %q differs per namespace", t.Name)))
+		}
+	}
+	return errs
+}
+
+// ProducesClusterRole reports whether any ClusterRole template in the config renders
+// the given name for at least one matching namespace. It runs in event handlers for
+// every config, so literal names are compared before any namespace is listed, and
+// templated names are rendered unmetered.
+func (m *Manager) ProducesClusterRole(ctx context.Context, config *rbacoperatorv1.NamespaceRBACConfig, name string) (bool, error) {
+	literalMatch := false
+	templated := make([]string, 0, len(config.Spec.RBACTemplates.ClusterRoles))
+	for _, t := range config.Spec.RBACTemplates.ClusterRoles {
+		// Other naming strategies hash even literal names
+		if getNamingStrategy(config) == rbacoperatorv1.NamingStrategyTemplate && !strings.Contains(t.Name, "{{") {
+			literalMatch = literalMatch || t.Name == name
+			continue
+		}
+		templated = append(templated, t.Name)
+	}
+	if !literalMatch && len(templated) == 0 {
+		return false, nil
+	}
+
+	namespaceList := &corev1.NamespaceList{}
+	if err := m.List(ctx, namespaceList); err != nil {
+		return false, fmt.Errorf("failed to list namespaces: %w", err)
+	}
+
+	matching := make([]*corev1.Namespace, 0)
+	matchingNames := make([]string, 0)
+	for i := range namespaceList.Items {
+		matches, err := m.NamespaceMatches(ctx, &namespaceList.Items[i], config)
+		if err != nil {
+			return false, fmt.Errorf("failed to check namespace match: %w", err)
+		}
+		if !matches {
+			continue
+		}
+		// A literal name is produced as soon as any namespace matches
+		if literalMatch {
+			return true, nil
+		}
+		matching = append(matching, &namespaceList.Items[i])
+		matchingNames = append(matchingNames, namespaceList.Items[i].Name)
+	}
+	sort.Strings(matchingNames)
+
+	engine := m.templateEngine.Unmetered()
+	for _, ns := range matching {
+		templateCtx := engine.BuildContext(ns, config, matchingNames)
+		for _, nameTemplate := range templated {
+			rendered, err := resolveNameWith(engine, config, nameTemplate, templateCtx)
+			if err != nil {
+				continue // Rendering errors are reported at apply time
+			}
+			if rendered == name {
+				return true, nil
+			}
+		}
+	}
+
+	return false, nil
+}
+
+// applyServiceAccount creates or updates a ServiceAccount. Only its metadata is
+// templated, e.g. annotations carrying a cloud IAM role taken from the namespace.
+func (m *Manager) applyServiceAccount(ctx context.Context, ns *corev1.Namespace, config *rbacoperatorv1.NamespaceRBACConfig, template rbacoperatorv1.ServiceAccountTemplate, templateCtx *template.TemplateContext, mergeStrategy rbacoperatorv1.MergeStrategy, result *ApplyResult) error {
+	ctx, span := tracing.Start(ctx, "rbac.applyServiceAccount",
+		tracing.ConfigKey.String(config.Name), tracing.NamespaceKey.String(ns.Name), tracing.KindKey.String("ServiceAccount"))
+	defer span.End()
+
+	start := time.Now()
+	name, err := m.resolveName(config, template.Name, templateCtx)
+	metrics.RecordTemplateProcessing(config.Name, "serviceaccount_name", time.Since(start), err)
+	if err != nil {
+		return fmt.Errorf("failed to process service account name template: %w", err)
+	}
+	if name == "" {
+		// Creating would fail with an opaque API error; skip and surface a warning instead
+		result.LintWarnings = append(result.LintWarnings, fmt.Sprintf("ServiceAccount template %q rendered an empty name for namespace %s, skipped", template.Name, ns.Name))
+		return nil
+	}
+
+	labels, err := m.processLabels(config, template.Labels, templateCtx)
+	if err != nil {
+		return fmt.Errorf("failed to process service account labels: %w", err)
+	}
+	lintLabels("ServiceAccount", name, labels, result)
+
+	start = time.Now()
+	annotations, err := m.processAnnotations(config, template.Annotations, templateCtx)
+	metrics.RecordTemplateProcessing(config.Name, "serviceaccount_annotations", time.Since(start), err)
+	if err != nil {
+		return fmt.Errorf("failed to process service account annotations: %w", err)
+	}
+	if annotations, err = withTemplateChecksum(annotations, template); err != nil {
+		return err
+	}
+
+	serviceAccount := &corev1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Namespace:   ns.Name,
+			Labels:      m.mergeLabels(labels, config, ns.Name),
+			Annotations: annotations,
+		},
+	}
+
+	if err := m.setOwnerReference(ns, config, serviceAccount); err != nil {
+		return err
+	}
+
+	result.Resources = append(result.Resources, serviceAccount.DeepCopy())
+	resource := fmt.Sprintf("ServiceAccount %s/%s", serviceAccount.Namespace, serviceAccount.Name)
+	drifted := m.detectDrift(ctx, serviceAccount, ns, config, mergeStrategy)
+	operation, err := m.createOrUpdateServiceAccount(ctx, serviceAccount, config, mergeStrategy)
+	err = wrapAPIUnavailable(err)
+	if err == errMergeFrozen {
+		result.FrozenResources = append(result.FrozenResources, resource)
+		return nil
+	}
+	if err == nil && drifted {
+		result.DriftCorrected = append(result.DriftCorrected, resource)
+		metrics.RecordDriftCorrection(config.Name, "serviceaccount")
+	}
+	if err == errUnchanged {
+		operation, err = "noop", nil
+	}
+	metrics.RecordResourceOperation(config.Name, "serviceaccount", operation, err)
+
+	if err == nil {
+		metrics.UpdateManagedResources(config.Name, "serviceaccount", ns.Name, 1)
+	}
+
+	return err
 }
 
 // applyRole creates or updates a Role
-func (m *Manager) applyRole(ctx context.Context, ns *corev1.Namespace, config *rbacoperatorv1.NamespaceRBACConfig, template rbacoperatorv1.RoleTemplate, templateCtx *template.TemplateContext) error {
+func (m *Manager) applyRole(ctx context.Context, ns *corev1.Namespace, config *rbacoperatorv1.NamespaceRBACConfig, template rbacoperatorv1.RoleTemplate, templateCtx *template.TemplateContext, mergeStrategy rbacoperatorv1.MergeStrategy, result *ApplyResult) error {
+	ctx, span := tracing.Start(ctx, "rbac.applyRole",
+		tracing.ConfigKey.String(config.Name), tracing.NamespaceKey.String(ns.Name), tracing.KindKey.String("Role"))
+	defer span.End()
+
 	start := time.Now()
-	name, err := m.templateEngine.ProcessTemplate(template.Name, templateCtx)
+	name, err := m.resolveName(config, template.Name, templateCtx)
 	metrics.RecordTemplateProcessing(config.Name, "role_name", time.Since(start), err)
 	if err != nil {
 		return fmt.Errorf("failed to process role name template: %w", err)
 	}
+	if name == "" {
+		// Creating would fail with an opaque API error; skip and surface a warning instead
+		result.LintWarnings = append(result.LintWarnings, fmt.Sprintf("Role template %q rendered an empty name for namespace %s, skipped", template.Name, ns.Name))
+		return nil
+	}
 
 	start = time.Now()
-	labels, err := m.templateEngine.ProcessMap(template.Labels, templateCtx)
+	labels, err := m.processLabels(config, template.Labels, templateCtx)
 	metrics.RecordTemplateProcessing(config.Name, "role_labels", time.Since(start), err)
 	if err != nil {
 		return fmt.Errorf("failed to process role labels: %w", err)
 	}
+	lintLabels("Role", name, labels, result)
 
 	start = time.Now()
-	annotations, err := m.templateEngine.ProcessMap(template.Annotations, templateCtx)
+	annotations, err := m.processAnnotations(config, template.Annotations, templateCtx)
 	metrics.RecordTemplateProcessing(config.Name, "role_annotations", time.Since(start), err)
 	if err != nil {
 		return fmt.Errorf("failed to process role annotations: %w", err)
 	}
+	if annotations, err = withTemplateChecksum(annotations, template); err != nil {
+		return err
+	}
+	rules, err := templateRules(template.Rules, template.SimpleRules)
+	if err != nil {
+		return err
+	}
 
 	role := &rbacv1.Role{
 		ObjectMeta: metav1.ObjectMeta{
@@ -132,18 +896,30 @@ func (m *Manager) applyRole(ctx context.Context, ns *corev1.Namespace, config *r
 			Labels:      m.mergeLabels(labels, config, ns.Name),
 			Annotations: annotations,
 		},
-		Rules: template.Rules,
+		Rules: rules,
 	}
 
-	// Set owner reference to the namespace
-	if err := controllerutil.SetControllerReference(ns, role, m.Scheme()); err != nil {
-		return fmt.Errorf("failed to set owner reference: %w", err)
+	if err := m.setOwnerReference(ns, config, role); err != nil {
+		return err
 	}
 
-	err = m.createOrUpdateRole(ctx, role, config)
+	result.Resources = append(result.Resources, role.DeepCopy())
+	resource := fmt.Sprintf("Role %s/%s", role.Namespace, role.Name)
+	drifted := m.detectDrift(ctx, role, ns, config, mergeStrategy)
+	err = wrapEscalationDenied(wrapAPIUnavailable(m.createOrUpdateRole(ctx, role, config, mergeStrategy)), resource)
+	if err == errMergeFrozen {
+		result.FrozenResources = append(result.FrozenResources, resource)
+		return nil
+	}
+	if err == nil && drifted {
+		result.DriftCorrected = append(result.DriftCorrected, resource)
+		metrics.RecordDriftCorrection(config.Name, "role")
+	}
 	// Record resource operation
 	operation := "create"
-	if err == nil {
+	if err == errUnchanged {
+		operation, err = "noop", nil
+	} else if err == nil {
 		// Check if it was create or update by checking if resource already existed
 		existing := &rbacv1.Role{}
 		if getErr := m.Get(ctx, types.NamespacedName{Name: role.Name, Namespace: role.Namespace}, existing); getErr == nil {
@@ -161,23 +937,40 @@ func (m *Manager) applyRole(ctx context.Context, ns *corev1.Namespace, config *r
 }
 
 // applyClusterRole creates or updates a ClusterRole
-func (m *Manager) applyClusterRole(ctx context.Context, ns *corev1.Namespace, config *rbacoperatorv1.NamespaceRBACConfig, template rbacoperatorv1.ClusterRoleTemplate, templateCtx *template.TemplateContext) error {
+func (m *Manager) applyClusterRole(ctx context.Context, ns *corev1.Namespace, config *rbacoperatorv1.NamespaceRBACConfig, template rbacoperatorv1.ClusterRoleTemplate, templateCtx *template.TemplateContext, mergeStrategy rbacoperatorv1.MergeStrategy, result *ApplyResult) error {
+	ctx, span := tracing.Start(ctx, "rbac.applyClusterRole",
+		tracing.ConfigKey.String(config.Name), tracing.NamespaceKey.String(ns.Name), tracing.KindKey.String("ClusterRole"))
+	defer span.End()
+
 	start := time.Now()
-	name, err := m.templateEngine.ProcessTemplate(template.Name, templateCtx)
+	name, err := m.resolveName(config, template.Name, templateCtx)
 	metrics.RecordTemplateProcessing(config.Name, "clusterrole_name", time.Since(start), err)
 	if err != nil {
 		return fmt.Errorf("failed to process cluster role name template: %w", err)
 	}
+	if name == "" {
+		// Creating would fail with an opaque API error; skip and surface a warning instead
+		result.LintWarnings = append(result.LintWarnings, fmt.Sprintf("ClusterRole template %q rendered an empty name for namespace %s, skipped", template.Name, ns.Name))
+		return nil
+	}
 
-	labels, err := m.templateEngine.ProcessMap(template.Labels, templateCtx)
+	labels, err := m.processLabels(config, template.Labels, templateCtx)
 	if err != nil {
 		return fmt.Errorf("failed to process cluster role labels: %w", err)
 	}
+	lintLabels("ClusterRole", name, labels, result)
 
-	annotations, err := m.templateEngine.ProcessMap(template.Annotations, templateCtx)
+	annotations, err := m.processAnnotations(config, template.Annotations, templateCtx)
 	if err != nil {
 		return fmt.Errorf("failed to process cluster role annotations: %w", err)
 	}
+	if annotations, err = withTemplateChecksum(annotations, template); err != nil {
+		return err
+	}
+	rules, err := templateRules(template.Rules, template.SimpleRules)
+	if err != nil {
+		return err
+	}
 
 	clusterRole := &rbacv1.ClusterRole{
 		ObjectMeta: metav1.ObjectMeta{
@@ -185,11 +978,30 @@ func (m *Manager) applyClusterRole(ctx context.Context, ns *corev1.Namespace, co
 			Labels:      m.mergeLabels(labels, config, ns.Name),
 			Annotations: annotations,
 		},
-		Rules: template.Rules,
+		Rules: rules,
+	}
+
+	if err := m.setOwnerReference(ns, config, clusterRole); err != nil {
+		return err
 	}
 
-	err = m.createOrUpdateClusterRole(ctx, clusterRole, config)
-	metrics.RecordResourceOperation(config.Name, "clusterrole", "create", err)
+	result.Resources = append(result.Resources, clusterRole.DeepCopy())
+	resource := fmt.Sprintf("ClusterRole %s", clusterRole.Name)
+	drifted := m.detectDrift(ctx, clusterRole, ns, config, mergeStrategy)
+	err = wrapEscalationDenied(wrapAPIUnavailable(m.createOrUpdateClusterRole(ctx, clusterRole, config, mergeStrategy)), resource)
+	if err == errMergeFrozen {
+		result.FrozenResources = append(result.FrozenResources, resource)
+		return nil
+	}
+	if err == nil && drifted {
+		result.DriftCorrected = append(result.DriftCorrected, resource)
+		metrics.RecordDriftCorrection(config.Name, "clusterrole")
+	}
+	operation := "create"
+	if err == errUnchanged {
+		operation, err = "noop", nil
+	}
+	metrics.RecordResourceOperation(config.Name, "clusterrole", operation, err)
 	if err == nil {
 		metrics.UpdateManagedResources(config.Name, "clusterrole", "", 1)
 	}
@@ -197,26 +1009,39 @@ func (m *Manager) applyClusterRole(ctx context.Context, ns *corev1.Namespace, co
 }
 
 // applyRoleBinding creates or updates a RoleBinding
-func (m *Manager) applyRoleBinding(ctx context.Context, ns *corev1.Namespace, config *rbacoperatorv1.NamespaceRBACConfig, template rbacoperatorv1.RoleBindingTemplate, templateCtx *template.TemplateContext) error {
+func (m *Manager) applyRoleBinding(ctx context.Context, ns *corev1.Namespace, config *rbacoperatorv1.NamespaceRBACConfig, template rbacoperatorv1.RoleBindingTemplate, templateCtx *template.TemplateContext, mergeStrategy rbacoperatorv1.MergeStrategy, result *ApplyResult) error {
+	ctx, span := tracing.Start(ctx, "rbac.applyRoleBinding",
+		tracing.ConfigKey.String(config.Name), tracing.NamespaceKey.String(ns.Name), tracing.KindKey.String("RoleBinding"))
+	defer span.End()
+
 	start := time.Now()
-	name, err := m.templateEngine.ProcessTemplate(template.Name, templateCtx)
+	name, err := m.resolveName(config, template.Name, templateCtx)
 	metrics.RecordTemplateProcessing(config.Name, "rolebinding_name", time.Since(start), err)
 	if err != nil {
 		return fmt.Errorf("failed to process role binding name template: %w", err)
 	}
+	if name == "" {
+		// Creating would fail with an opaque API error; skip and surface a warning instead
+		result.LintWarnings = append(result.LintWarnings, fmt.Sprintf("RoleBinding template %q rendered an empty name for namespace %s, skipped", template.Name, ns.Name))
+		return nil
+	}
 
-	labels, err := m.templateEngine.ProcessMap(template.Labels, templateCtx)
+	labels, err := m.processLabels(config, template.Labels, templateCtx)
 	if err != nil {
 		return fmt.Errorf("failed to process role binding labels: %w", err)
 	}
+	lintLabels("RoleBinding", name, labels, result)
 
-	annotations, err := m.templateEngine.ProcessMap(template.Annotations, templateCtx)
+	annotations, err := m.processAnnotations(config, template.Annotations, templateCtx)
 	if err != nil {
 		return fmt.Errorf("failed to process role binding annotations: %w", err)
 	}
+	if annotations, err = withTemplateChecksum(annotations, template); err != nil {
+		return err
+	}
 
 	// Process role reference name
-	roleRefName, err := m.templateEngine.ProcessTemplate(template.RoleRef.Name, templateCtx)
+	roleRefName, err := m.resolveRoleRefName(config, template.RoleRef, templateCtx)
 	if err != nil {
 		return fmt.Errorf("failed to process role ref name template: %w", err)
 	}
@@ -227,6 +1052,24 @@ func (m *Manager) applyRoleBinding(ctx context.Context, ns *corev1.Namespace, co
 		return fmt.Errorf("failed to process subjects: %w", err)
 	}
 
+	// Expand subjects listed in a template variable
+	if template.SubjectsFromVar != "" {
+		varSubjects, err := m.subjectsFromVar(template.SubjectsFromVar, templateCtx)
+		if err != nil {
+			return err
+		}
+		subjects = append(subjects, varSubjects...)
+	}
+
+	// Expand ServiceAccounts selected by label into subjects
+	if len(template.FromServiceAccountSelector) > 0 {
+		saSubjects, err := m.serviceAccountSubjects(ctx, ns.Name, template.FromServiceAccountSelector)
+		if err != nil {
+			return err
+		}
+		subjects = append(subjects, saSubjects...)
+	}
+
 	roleBinding := &rbacv1.RoleBinding{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:        name,
@@ -242,13 +1085,27 @@ func (m *Manager) applyRoleBinding(ctx context.Context, ns *corev1.Namespace, co
 		Subjects: subjects,
 	}
 
-	// Set owner reference to the namespace
-	if err := controllerutil.SetControllerReference(ns, roleBinding, m.Scheme()); err != nil {
-		return fmt.Errorf("failed to set owner reference: %w", err)
+	if err := m.setOwnerReference(ns, config, roleBinding); err != nil {
+		return err
 	}
 
-	err = m.createOrUpdateRoleBinding(ctx, roleBinding, config)
-	metrics.RecordResourceOperation(config.Name, "rolebinding", "create", err)
+	result.Resources = append(result.Resources, roleBinding.DeepCopy())
+	resource := fmt.Sprintf("RoleBinding %s/%s", roleBinding.Namespace, roleBinding.Name)
+	drifted := m.detectDrift(ctx, roleBinding, ns, config, mergeStrategy)
+	err = wrapEscalationDenied(wrapAPIUnavailable(m.createOrUpdateRoleBinding(ctx, roleBinding, config, mergeStrategy)), resource)
+	if err == errMergeFrozen {
+		result.FrozenResources = append(result.FrozenResources, resource)
+		return nil
+	}
+	if err == nil && drifted {
+		result.DriftCorrected = append(result.DriftCorrected, resource)
+		metrics.RecordDriftCorrection(config.Name, "rolebinding")
+	}
+	operation := "create"
+	if err == errUnchanged {
+		operation, err = "noop", nil
+	}
+	metrics.RecordResourceOperation(config.Name, "rolebinding", operation, err)
 	if err == nil {
 		metrics.UpdateManagedResources(config.Name, "rolebinding", ns.Name, 1)
 	}
@@ -256,26 +1113,39 @@ func (m *Manager) applyRoleBinding(ctx context.Context, ns *corev1.Namespace, co
 }
 
 // applyClusterRoleBinding creates or updates a ClusterRoleBinding
-func (m *Manager) applyClusterRoleBinding(ctx context.Context, ns *corev1.Namespace, config *rbacoperatorv1.NamespaceRBACConfig, template rbacoperatorv1.ClusterRoleBindingTemplate, templateCtx *template.TemplateContext) error {
+func (m *Manager) applyClusterRoleBinding(ctx context.Context, ns *corev1.Namespace, config *rbacoperatorv1.NamespaceRBACConfig, template rbacoperatorv1.ClusterRoleBindingTemplate, templateCtx *template.TemplateContext, mergeStrategy rbacoperatorv1.MergeStrategy, result *ApplyResult) error {
+	ctx, span := tracing.Start(ctx, "rbac.applyClusterRoleBinding",
+		tracing.ConfigKey.String(config.Name), tracing.NamespaceKey.String(ns.Name), tracing.KindKey.String("ClusterRoleBinding"))
+	defer span.End()
+
 	start := time.Now()
-	name, err := m.templateEngine.ProcessTemplate(template.Name, templateCtx)
+	name, err := m.resolveName(config, template.Name, templateCtx)
 	metrics.RecordTemplateProcessing(config.Name, "clusterrolebinding_name", time.Since(start), err)
 	if err != nil {
 		return fmt.Errorf("failed to process cluster role binding name template: %w", err)
 	}
+	if name == "" {
+		// Creating would fail with an opaque API error; skip and surface a warning instead
+		result.LintWarnings = append(result.LintWarnings, fmt.Sprintf("ClusterRoleBinding template %q rendered an empty name for namespace %s, skipped", template.Name, ns.Name))
+		return nil
+	}
 
-	labels, err := m.templateEngine.ProcessMap(template.Labels, templateCtx)
+	labels, err := m.processLabels(config, template.Labels, templateCtx)
 	if err != nil {
 		return fmt.Errorf("failed to process cluster role binding labels: %w", err)
 	}
+	lintLabels("ClusterRoleBinding", name, labels, result)
 
-	annotations, err := m.templateEngine.ProcessMap(template.Annotations, templateCtx)
+	annotations, err := m.processAnnotations(config, template.Annotations, templateCtx)
 	if err != nil {
 		return fmt.Errorf("failed to process cluster role binding annotations: %w", err)
 	}
+	if annotations, err = withTemplateChecksum(annotations, template); err != nil {
+		return err
+	}
 
 	// Process role reference name
-	roleRefName, err := m.templateEngine.ProcessTemplate(template.RoleRef.Name, templateCtx)
+	roleRefName, err := m.resolveRoleRefName(config, template.RoleRef, templateCtx)
 	if err != nil {
 		return fmt.Errorf("failed to process role ref name template: %w", err)
 	}
@@ -286,6 +1156,15 @@ func (m *Manager) applyClusterRoleBinding(ctx context.Context, ns *corev1.Namesp
 		return fmt.Errorf("failed to process subjects: %w", err)
 	}
 
+	// Expand subjects listed in a template variable
+	if template.SubjectsFromVar != "" {
+		varSubjects, err := m.subjectsFromVar(template.SubjectsFromVar, templateCtx)
+		if err != nil {
+			return err
+		}
+		subjects = append(subjects, varSubjects...)
+	}
+
 	clusterRoleBinding := &rbacv1.ClusterRoleBinding{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:        name,
@@ -300,41 +1179,194 @@ func (m *Manager) applyClusterRoleBinding(ctx context.Context, ns *corev1.Namesp
 		Subjects: subjects,
 	}
 
-	err = m.createOrUpdateClusterRoleBinding(ctx, clusterRoleBinding, config)
-	metrics.RecordResourceOperation(config.Name, "clusterrolebinding", "create", err)
-	if err == nil {
-		metrics.UpdateManagedResources(config.Name, "clusterrolebinding", "", 1)
+	if err := m.setOwnerReference(ns, config, clusterRoleBinding); err != nil {
+		return err
+	}
+
+	result.Resources = append(result.Resources, clusterRoleBinding.DeepCopy())
+	resource := fmt.Sprintf("ClusterRoleBinding %s", clusterRoleBinding.Name)
+	drifted := m.detectDrift(ctx, clusterRoleBinding, ns, config, mergeStrategy)
+	err = wrapEscalationDenied(wrapAPIUnavailable(m.createOrUpdateClusterRoleBinding(ctx, clusterRoleBinding, config, mergeStrategy)), resource)
+	if err == errMergeFrozen {
+		result.FrozenResources = append(result.FrozenResources, resource)
+		return nil
+	}
+	if err == nil && drifted {
+		result.DriftCorrected = append(result.DriftCorrected, resource)
+		metrics.RecordDriftCorrection(config.Name, "clusterrolebinding")
+	}
+	operation := "create"
+	if err == errUnchanged {
+		operation, err = "noop", nil
+	}
+	metrics.RecordResourceOperation(config.Name, "clusterrolebinding", operation, err)
+	if err == nil {
+		metrics.UpdateManagedResources(config.Name, "clusterrolebinding", "", 1)
+	}
+	return err
+}
+
+// processSubjects processes template variables in subjects
+func (m *Manager) processSubjects(subjects []rbacv1.Subject, templateCtx *template.TemplateContext) ([]rbacv1.Subject, error) {
+	result := make([]rbacv1.Subject, len(subjects))
+
+	for i, subject := range subjects {
+		processedName, err := m.templateEngine.ProcessTemplate(subject.Name, templateCtx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to process subject name: %w", err)
+		}
+
+		result[i] = rbacv1.Subject{
+			Kind:     subject.Kind,
+			APIGroup: subject.APIGroup,
+			Name:     processedName,
+		}
+
+		// Default the API group when unset: User and Group subjects require the RBAC
+		// group, while ServiceAccount subjects belong to the core (empty) group
+		if result[i].APIGroup == "" && (subject.Kind == rbacv1.UserKind || subject.Kind == rbacv1.GroupKind) {
+			result[i].APIGroup = rbacv1.GroupName
+		}
+
+		// Process namespace for ServiceAccount subjects
+		if subject.Namespace != "" {
+			processedNamespace, err := m.templateEngine.ProcessTemplate(subject.Namespace, templateCtx)
+			if err != nil {
+				return nil, fmt.Errorf("failed to process subject namespace: %w", err)
+			}
+			result[i].Namespace = processedNamespace
+		}
+	}
+
+	return result, nil
+}
+
+// subjectsFromVar parses the named template variable as a YAML or JSON list of
+// subjects (e.g. `[{kind: User, name: alice}]`) and processes them like static subjects
+func (m *Manager) subjectsFromVar(varName string, templateCtx *template.TemplateContext) ([]rbacv1.Subject, error) {
+	value, ok := templateCtx.CustomVars[varName]
+	if !ok {
+		return nil, fmt.Errorf("subjectsFromVar: template variable %q is not defined", varName)
+	}
+
+	var subjects []rbacv1.Subject
+	if err := yaml.Unmarshal([]byte(value), &subjects); err != nil {
+		return nil, fmt.Errorf("subjectsFromVar: template variable %q is not a list of subjects: %w", varName, err)
+	}
+
+	processed, err := m.processSubjects(subjects, templateCtx)
+	if err != nil {
+		return nil, fmt.Errorf("subjectsFromVar: %w", err)
+	}
+	return processed, nil
+}
+
+// serviceAccountSubjects lists the ServiceAccounts in namespace matching selector
+// and returns them as subjects, sorted by name. No matches yields no subjects.
+func (m *Manager) serviceAccountSubjects(ctx context.Context, namespace string, selector map[string]string) ([]rbacv1.Subject, error) {
+	serviceAccounts := &corev1.ServiceAccountList{}
+	if err := m.List(ctx, serviceAccounts, client.InNamespace(namespace), client.MatchingLabels(selector)); err != nil {
+		return nil, fmt.Errorf("failed to list service accounts in namespace %s: %w", namespace, err)
+	}
+
+	subjects := make([]rbacv1.Subject, 0, len(serviceAccounts.Items))
+	for _, sa := range serviceAccounts.Items {
+		subjects = append(subjects, rbacv1.Subject{
+			Kind:      rbacv1.ServiceAccountKind,
+			Name:      sa.Name,
+			Namespace: namespace,
+		})
+	}
+	sort.Slice(subjects, func(i, j int) bool { return subjects[i].Name < subjects[j].Name })
+
+	return subjects, nil
+}
+
+// processLabels renders the config's common labels and the template's labels,
+// with template labels taking precedence
+func (m *Manager) processLabels(config *rbacoperatorv1.NamespaceRBACConfig, templateLabels map[string]string, templateCtx *template.TemplateContext) (map[string]string, error) {
+	var common map[string]string
+	if config.Spec.Config != nil {
+		common = config.Spec.Config.CommonLabels
+	}
+	return m.processWithCommon(common, templateLabels, templateCtx)
+}
+
+// processAnnotations renders the config's common annotations and the template's
+// annotations, with template annotations taking precedence
+func (m *Manager) processAnnotations(config *rbacoperatorv1.NamespaceRBACConfig, templateAnnotations map[string]string, templateCtx *template.TemplateContext) (map[string]string, error) {
+	var common map[string]string
+	if config.Spec.Config != nil {
+		common = config.Spec.Config.CommonAnnotations
+	}
+	return m.processWithCommon(common, templateAnnotations, templateCtx)
+}
+
+// processWithCommon renders both maps and overlays specific onto common
+func (m *Manager) processWithCommon(common, specific map[string]string, templateCtx *template.TemplateContext) (map[string]string, error) {
+	result, err := m.templateEngine.ProcessMap(common, templateCtx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to process common values: %w", err)
+	}
+
+	processed, err := m.templateEngine.ProcessMap(specific, templateCtx)
+	if err != nil {
+		return nil, err
+	}
+	if result == nil {
+		return processed, nil
+	}
+	for k, v := range processed {
+		result[k] = v
+	}
+
+	return result, nil
+}
+
+// setOwnerReference sets the controller reference selected by the config's
+// ownerReferenceStrategy. A namespace cannot own cluster-scoped objects, so with the
+// namespace strategy ClusterRoles and ClusterRoleBindings are left without an owner.
+func (m *Manager) setOwnerReference(ns *corev1.Namespace, config *rbacoperatorv1.NamespaceRBACConfig, obj client.Object) error {
+	var owner client.Object
+	switch getOwnerReferenceStrategy(config) {
+	case rbacoperatorv1.OwnerReferenceNamespace:
+		if obj.GetNamespace() == "" {
+			return nil
+		}
+		owner = ns
+	case rbacoperatorv1.OwnerReferenceConfig:
+		owner = config
+	default:
+		return nil
+	}
+
+	if err := controllerutil.SetControllerReference(owner, obj, m.Scheme()); err != nil {
+		return fmt.Errorf("failed to set owner reference: %w", err)
 	}
-	return err
+	return nil
 }
 
-// processSubjects processes template variables in subjects
-func (m *Manager) processSubjects(subjects []rbacv1.Subject, templateCtx *template.TemplateContext) ([]rbacv1.Subject, error) {
-	result := make([]rbacv1.Subject, len(subjects))
-
-	for i, subject := range subjects {
-		processedName, err := m.templateEngine.ProcessTemplate(subject.Name, templateCtx)
-		if err != nil {
-			return nil, fmt.Errorf("failed to process subject name: %w", err)
-		}
-
-		result[i] = rbacv1.Subject{
-			Kind:     subject.Kind,
-			APIGroup: subject.APIGroup,
-			Name:     processedName,
-		}
+// getOwnerReferenceStrategy returns the owner reference strategy from config or the namespace default
+func getOwnerReferenceStrategy(config *rbacoperatorv1.NamespaceRBACConfig) rbacoperatorv1.OwnerReferenceStrategy {
+	if config.Spec.Config != nil && config.Spec.Config.OwnerReferenceStrategy != nil {
+		return *config.Spec.Config.OwnerReferenceStrategy
+	}
+	return rbacoperatorv1.OwnerReferenceNamespace
+}
 
-		// Process namespace for ServiceAccount subjects
-		if subject.Namespace != "" {
-			processedNamespace, err := m.templateEngine.ProcessTemplate(subject.Namespace, templateCtx)
-			if err != nil {
-				return nil, fmt.Errorf("failed to process subject namespace: %w", err)
-			}
-			result[i].Namespace = processedNamespace
+// lintLabels records a warning for every template label that rendered to an empty
+// value, which usually means a missing variable rather than an intended empty label
+func lintLabels(kind, name string, labels map[string]string, result *ApplyResult) {
+	keys := make([]string, 0, len(labels))
+	for key, value := range labels {
+		if value == "" {
+			keys = append(keys, key)
 		}
 	}
-
-	return result, nil
+	sort.Strings(keys)
+	for _, key := range keys {
+		result.LintWarnings = append(result.LintWarnings, fmt.Sprintf("%s %s: label %q rendered an empty value", kind, name, key))
+	}
 }
 
 // mergeLabels merges template labels with operator-managed labels
@@ -357,43 +1389,64 @@ func (m *Manager) mergeLabels(templateLabels map[string]string, config *rbacoper
 }
 
 // createOrUpdateRole creates or updates a Role based on merge strategy
-func (m *Manager) createOrUpdateRole(ctx context.Context, role *rbacv1.Role, config *rbacoperatorv1.NamespaceRBACConfig) error {
-	retry := 3
+func (m *Manager) createOrUpdateRole(ctx context.Context, role *rbacv1.Role, config *rbacoperatorv1.NamespaceRBACConfig, mergeStrategy rbacoperatorv1.MergeStrategy) error {
+	retry := getMaxConflictRetries(config)
 	for i := 0; i < retry; i++ {
 		existing := &rbacv1.Role{}
 		err := m.Get(ctx, types.NamespacedName{Name: role.Name, Namespace: role.Namespace}, existing)
 
 		if errors.IsNotFound(err) {
-			return m.Create(ctx, role)
+			err = m.Create(ctx, role, client.FieldOwner(m.fieldManager))
+			if !errors.IsAlreadyExists(err) {
+				return err
+			}
+			// Created by someone else since the Get; update it instead
+			err = m.Get(ctx, types.NamespacedName{Name: role.Name, Namespace: role.Namespace}, existing)
 		}
 		if err != nil {
 			return err
 		}
 
-		// Handle merge strategy
-		mergeStrategy := rbacoperatorv1.MergeStrategyMerge
-		if config.Spec.Config != nil && config.Spec.Config.MergeStrategy != nil {
-			mergeStrategy = *config.Spec.Config.MergeStrategy
+		if isMergeFrozen(existing) {
+			metrics.RecordConflictResolution(config.Name, "freeze", "role")
+			return errMergeFrozen
 		}
 
+		// Handle merge strategy
 		switch mergeStrategy {
 		case rbacoperatorv1.MergeStrategyIgnore:
 			metrics.RecordConflictResolution(config.Name, "ignore", "role")
 			return nil // Don't update existing resource
 		case rbacoperatorv1.MergeStrategyReplace:
 			metrics.RecordConflictResolution(config.Name, "replace", "role")
-			role.ResourceVersion = existing.ResourceVersion
-			err = m.Update(ctx, role)
+		case rbacoperatorv1.MergeStrategyAuthoritative:
+			metrics.RecordConflictResolution(config.Name, "authoritative", "role")
+			// Rules are operator-owned: replace them entirely
 		case rbacoperatorv1.MergeStrategyMerge:
 			metrics.RecordConflictResolution(config.Name, "merge", "role")
-			// Merge rules and update
-			role.Rules = mergeRules(existing.Rules, role.Rules)
-			role.ResourceVersion = existing.ResourceVersion
-			err = m.Update(ctx, role)
+			// Merge rules unless a previous apply already did
+			if rulesMatch(existing.Rules, role.Rules, true) {
+				role.Rules = existing.Rules
+			} else {
+				role.Rules = mergeRules(existing.Rules, role.Rules)
+			}
 		default:
 			return fmt.Errorf("unknown merge strategy: %s", mergeStrategy)
 		}
 
+		adopted := carryAdoption(existing, role)
+
+		// Skip the write when nothing would change
+		if metadataUnchanged(existing, role) && equality.Semantic.DeepEqual(existing.Rules, role.Rules) {
+			return errUnchanged
+		}
+
+		role.ResourceVersion = existing.ResourceVersion
+		err = m.Update(ctx, role, client.FieldOwner(m.fieldManager))
+		if err == nil && adopted {
+			metrics.RecordAdoption(config.Name, "role")
+		}
+
 		// If no conflict, return
 		if err == nil || !errors.IsConflict(err) {
 			return err
@@ -404,79 +1457,184 @@ func (m *Manager) createOrUpdateRole(ctx context.Context, role *rbacv1.Role, con
 	return fmt.Errorf("failed to update role after %d retries due to conflicts", retry)
 }
 
+// createOrUpdateServiceAccount creates or updates a ServiceAccount and reports which
+// operation it performed. Only labels, annotations and owner references are managed;
+// secrets, image pull secrets and token automount of an existing ServiceAccount are
+// left alone. With the merge strategy, foreign labels and annotations are kept.
+func (m *Manager) createOrUpdateServiceAccount(ctx context.Context, serviceAccount *corev1.ServiceAccount, config *rbacoperatorv1.NamespaceRBACConfig, mergeStrategy rbacoperatorv1.MergeStrategy) (string, error) {
+	retry := getMaxConflictRetries(config)
+	for i := 0; i < retry; i++ {
+		existing := &corev1.ServiceAccount{}
+		err := m.Get(ctx, types.NamespacedName{Name: serviceAccount.Name, Namespace: serviceAccount.Namespace}, existing)
+
+		if errors.IsNotFound(err) {
+			err = m.Create(ctx, serviceAccount, client.FieldOwner(m.fieldManager))
+			if !errors.IsAlreadyExists(err) {
+				return "create", err
+			}
+			// Created by someone else since the Get; update it instead
+			err = m.Get(ctx, types.NamespacedName{Name: serviceAccount.Name, Namespace: serviceAccount.Namespace}, existing)
+		}
+		if err != nil {
+			return "update", err
+		}
+
+		if isMergeFrozen(existing) {
+			metrics.RecordConflictResolution(config.Name, "freeze", "serviceaccount")
+			return "update", errMergeFrozen
+		}
+
+		desired := existing.DeepCopy()
+		desired.OwnerReferences = serviceAccount.OwnerReferences
+		switch mergeStrategy {
+		case rbacoperatorv1.MergeStrategyIgnore:
+			metrics.RecordConflictResolution(config.Name, "ignore", "serviceaccount")
+			return "noop", nil
+		case rbacoperatorv1.MergeStrategyReplace, rbacoperatorv1.MergeStrategyAuthoritative:
+			metrics.RecordConflictResolution(config.Name, string(mergeStrategy), "serviceaccount")
+			desired.Labels = serviceAccount.Labels
+			desired.Annotations = serviceAccount.Annotations
+		case rbacoperatorv1.MergeStrategyMerge:
+			metrics.RecordConflictResolution(config.Name, "merge", "serviceaccount")
+			desired.Labels = utils.MergeMaps(existing.Labels, serviceAccount.Labels)
+			desired.Annotations = utils.MergeMaps(existing.Annotations, serviceAccount.Annotations)
+		default:
+			return "update", fmt.Errorf("unknown merge strategy: %s", mergeStrategy)
+		}
+
+		adopted := carryAdoption(existing, desired)
+
+		// Skip the write when nothing would change
+		if metadataUnchanged(existing, desired) {
+			return "noop", errUnchanged
+		}
+
+		err = m.Update(ctx, desired, client.FieldOwner(m.fieldManager))
+		if err == nil && adopted {
+			metrics.RecordAdoption(config.Name, "serviceaccount")
+		}
+		if err == nil || !errors.IsConflict(err) {
+			return "update", err
+		}
+	}
+	return "update", fmt.Errorf("failed to update serviceaccount after %d retries due to conflicts", retry)
+}
+
 // createOrUpdateClusterRole creates or updates a ClusterRole
-func (m *Manager) createOrUpdateClusterRole(ctx context.Context, clusterRole *rbacv1.ClusterRole, config *rbacoperatorv1.NamespaceRBACConfig) error {
+func (m *Manager) createOrUpdateClusterRole(ctx context.Context, clusterRole *rbacv1.ClusterRole, config *rbacoperatorv1.NamespaceRBACConfig, mergeStrategy rbacoperatorv1.MergeStrategy) error {
 	existing := &rbacv1.ClusterRole{}
 	err := m.Get(ctx, types.NamespacedName{Name: clusterRole.Name}, existing)
 
 	if errors.IsNotFound(err) {
-		return m.Create(ctx, clusterRole)
+		err = m.Create(ctx, clusterRole, client.FieldOwner(m.fieldManager))
+		if !errors.IsAlreadyExists(err) {
+			return err
+		}
+		// Created by someone else since the Get; update it instead
+		err = m.Get(ctx, types.NamespacedName{Name: clusterRole.Name}, existing)
 	}
 	if err != nil {
 		return err
 	}
 
-	// Handle merge strategy
-	mergeStrategy := rbacoperatorv1.MergeStrategyMerge
-	if config.Spec.Config != nil && config.Spec.Config.MergeStrategy != nil {
-		mergeStrategy = *config.Spec.Config.MergeStrategy
+	if isMergeFrozen(existing) {
+		metrics.RecordConflictResolution(config.Name, "freeze", "clusterrole")
+		return errMergeFrozen
 	}
 
+	// Handle merge strategy
 	switch mergeStrategy {
 	case rbacoperatorv1.MergeStrategyIgnore:
 		metrics.RecordConflictResolution(config.Name, "ignore", "clusterrole")
 		return nil
 	case rbacoperatorv1.MergeStrategyReplace:
 		metrics.RecordConflictResolution(config.Name, "replace", "clusterrole")
-		clusterRole.ResourceVersion = existing.ResourceVersion
-		return m.Update(ctx, clusterRole)
+	case rbacoperatorv1.MergeStrategyAuthoritative:
+		metrics.RecordConflictResolution(config.Name, "authoritative", "clusterrole")
 	case rbacoperatorv1.MergeStrategyMerge:
 		metrics.RecordConflictResolution(config.Name, "merge", "clusterrole")
-		clusterRole.Rules = mergeRules(existing.Rules, clusterRole.Rules)
-		clusterRole.ResourceVersion = existing.ResourceVersion
-		return m.Update(ctx, clusterRole)
+		if rulesMatch(existing.Rules, clusterRole.Rules, true) {
+			clusterRole.Rules = existing.Rules // Already merged; appending again would duplicate rules
+		} else {
+			clusterRole.Rules = mergeRules(existing.Rules, clusterRole.Rules)
+		}
 	default:
 		return fmt.Errorf("unknown merge strategy: %s", mergeStrategy)
 	}
+
+	adopted := carryAdoption(existing, clusterRole)
+
+	// Skip the write when nothing would change, avoiding resourceVersion churn
+	if metadataUnchanged(existing, clusterRole) &&
+		equality.Semantic.DeepEqual(existing.Rules, clusterRole.Rules) &&
+		existing.AggregationRule == nil {
+		return errUnchanged
+	}
+
+	clusterRole.ResourceVersion = existing.ResourceVersion
+	err = m.Update(ctx, clusterRole, client.FieldOwner(m.fieldManager))
+	if err == nil && adopted {
+		metrics.RecordAdoption(config.Name, "clusterrole")
+	}
+	return err
 }
 
 // createOrUpdateRoleBinding creates or updates a RoleBinding
-func (m *Manager) createOrUpdateRoleBinding(ctx context.Context, roleBinding *rbacv1.RoleBinding, config *rbacoperatorv1.NamespaceRBACConfig) error {
-	retry := 3
+func (m *Manager) createOrUpdateRoleBinding(ctx context.Context, roleBinding *rbacv1.RoleBinding, config *rbacoperatorv1.NamespaceRBACConfig, mergeStrategy rbacoperatorv1.MergeStrategy) error {
+	retry := getMaxConflictRetries(config)
 	for i := 0; i < retry; i++ {
 		existing := &rbacv1.RoleBinding{}
 		err := m.Get(ctx, types.NamespacedName{Name: roleBinding.Name, Namespace: roleBinding.Namespace}, existing)
 
 		if errors.IsNotFound(err) {
-			return m.Create(ctx, roleBinding)
+			err = m.Create(ctx, roleBinding, client.FieldOwner(m.fieldManager))
+			if !errors.IsAlreadyExists(err) {
+				return err
+			}
+			// Created by someone else since the Get; update it instead
+			err = m.Get(ctx, types.NamespacedName{Name: roleBinding.Name, Namespace: roleBinding.Namespace}, existing)
 		}
 		if err != nil {
 			return err
 		}
 
-		// Handle merge strategy
-		mergeStrategy := rbacoperatorv1.MergeStrategyMerge
-		if config.Spec.Config != nil && config.Spec.Config.MergeStrategy != nil {
-			mergeStrategy = *config.Spec.Config.MergeStrategy
+		if isMergeFrozen(existing) {
+			metrics.RecordConflictResolution(config.Name, "freeze", "rolebinding")
+			return errMergeFrozen
 		}
 
+		// Handle merge strategy
 		switch mergeStrategy {
 		case rbacoperatorv1.MergeStrategyIgnore:
 			metrics.RecordConflictResolution(config.Name, "ignore", "rolebinding")
 			return nil
 		case rbacoperatorv1.MergeStrategyReplace:
 			metrics.RecordConflictResolution(config.Name, "replace", "rolebinding")
-			roleBinding.ResourceVersion = existing.ResourceVersion
-			err = m.Update(ctx, roleBinding)
+		case rbacoperatorv1.MergeStrategyAuthoritative:
+			metrics.RecordConflictResolution(config.Name, "authoritative", "rolebinding")
+			// Manually added subjects are preserved
+			roleBinding.Subjects = mergeExistingSubjects(existing.Subjects, roleBinding.Subjects, subjectMatchIgnoreCase(config))
 		case rbacoperatorv1.MergeStrategyMerge:
 			metrics.RecordConflictResolution(config.Name, "merge", "rolebinding")
-			roleBinding.Subjects = mergeSubjects(existing.Subjects, roleBinding.Subjects)
-			roleBinding.ResourceVersion = existing.ResourceVersion
-			err = m.Update(ctx, roleBinding)
+			roleBinding.Subjects = mergeExistingSubjects(existing.Subjects, roleBinding.Subjects, subjectMatchIgnoreCase(config))
 		default:
 			return fmt.Errorf("unknown merge strategy: %s", mergeStrategy)
 		}
 
+		adopted := carryAdoption(existing, roleBinding)
+
+		// Skip the write when nothing would change
+		if metadataUnchanged(existing, roleBinding) && existing.RoleRef == roleBinding.RoleRef &&
+			equality.Semantic.DeepEqual(existing.Subjects, roleBinding.Subjects) {
+			return errUnchanged
+		}
+
+		roleBinding.ResourceVersion = existing.ResourceVersion
+		err = m.Update(ctx, roleBinding, client.FieldOwner(m.fieldManager))
+		if err == nil && adopted {
+			metrics.RecordAdoption(config.Name, "rolebinding")
+		}
+
 		if err == nil || !errors.IsConflict(err) {
 			return err
 		}
@@ -485,39 +1643,159 @@ func (m *Manager) createOrUpdateRoleBinding(ctx context.Context, roleBinding *rb
 }
 
 // createOrUpdateClusterRoleBinding creates or updates a ClusterRoleBinding
-func (m *Manager) createOrUpdateClusterRoleBinding(ctx context.Context, clusterRoleBinding *rbacv1.ClusterRoleBinding, config *rbacoperatorv1.NamespaceRBACConfig) error {
+func (m *Manager) createOrUpdateClusterRoleBinding(ctx context.Context, clusterRoleBinding *rbacv1.ClusterRoleBinding, config *rbacoperatorv1.NamespaceRBACConfig, mergeStrategy rbacoperatorv1.MergeStrategy) error {
 	existing := &rbacv1.ClusterRoleBinding{}
 	err := m.Get(ctx, types.NamespacedName{Name: clusterRoleBinding.Name}, existing)
 
 	if errors.IsNotFound(err) {
-		return m.Create(ctx, clusterRoleBinding)
+		err = m.Create(ctx, clusterRoleBinding, client.FieldOwner(m.fieldManager))
+		if !errors.IsAlreadyExists(err) {
+			return err
+		}
+		// Created by someone else since the Get; update it instead
+		err = m.Get(ctx, types.NamespacedName{Name: clusterRoleBinding.Name}, existing)
 	}
 	if err != nil {
 		return err
 	}
 
-	// Handle merge strategy
-	mergeStrategy := rbacoperatorv1.MergeStrategyMerge
-	if config.Spec.Config != nil && config.Spec.Config.MergeStrategy != nil {
-		mergeStrategy = *config.Spec.Config.MergeStrategy
+	if isMergeFrozen(existing) {
+		metrics.RecordConflictResolution(config.Name, "freeze", "clusterrolebinding")
+		return errMergeFrozen
 	}
 
+	// Handle merge strategy
 	switch mergeStrategy {
 	case rbacoperatorv1.MergeStrategyIgnore:
 		metrics.RecordConflictResolution(config.Name, "ignore", "clusterrolebinding")
 		return nil
 	case rbacoperatorv1.MergeStrategyReplace:
 		metrics.RecordConflictResolution(config.Name, "replace", "clusterrolebinding")
-		clusterRoleBinding.ResourceVersion = existing.ResourceVersion
-		return m.Update(ctx, clusterRoleBinding)
+	case rbacoperatorv1.MergeStrategyAuthoritative:
+		metrics.RecordConflictResolution(config.Name, "authoritative", "clusterrolebinding")
+		clusterRoleBinding.Subjects = mergeExistingSubjects(existing.Subjects, clusterRoleBinding.Subjects, subjectMatchIgnoreCase(config))
 	case rbacoperatorv1.MergeStrategyMerge:
 		metrics.RecordConflictResolution(config.Name, "merge", "clusterrolebinding")
-		clusterRoleBinding.Subjects = mergeSubjects(existing.Subjects, clusterRoleBinding.Subjects)
-		clusterRoleBinding.ResourceVersion = existing.ResourceVersion
-		return m.Update(ctx, clusterRoleBinding)
+		clusterRoleBinding.Subjects = mergeExistingSubjects(existing.Subjects, clusterRoleBinding.Subjects, subjectMatchIgnoreCase(config))
 	default:
 		return fmt.Errorf("unknown merge strategy: %s", mergeStrategy)
 	}
+
+	adopted := carryAdoption(existing, clusterRoleBinding)
+
+	// Skip the write when nothing would change
+	if metadataUnchanged(existing, clusterRoleBinding) && existing.RoleRef == clusterRoleBinding.RoleRef &&
+		equality.Semantic.DeepEqual(existing.Subjects, clusterRoleBinding.Subjects) {
+		return errUnchanged
+	}
+
+	clusterRoleBinding.ResourceVersion = existing.ResourceVersion
+	err = m.Update(ctx, clusterRoleBinding, client.FieldOwner(m.fieldManager))
+	if err == nil && adopted {
+		metrics.RecordAdoption(config.Name, "clusterrolebinding")
+	}
+	return err
+}
+
+// applyOrder returns the order in which RBAC kinds are applied: the config's
+// ApplyOrder first, followed by any kinds it omits in DefaultApplyOrder
+func applyOrder(config *rbacoperatorv1.NamespaceRBACConfig) []string {
+	order := make([]string, 0, len(DefaultApplyOrder))
+	seen := make(map[string]bool)
+	if config.Spec.Config != nil {
+		for _, kind := range config.Spec.Config.ApplyOrder {
+			if !seen[kind] && IsKnownKind(kind) {
+				order = append(order, kind)
+				seen[kind] = true
+			}
+		}
+	}
+	for _, kind := range DefaultApplyOrder {
+		if !seen[kind] {
+			order = append(order, kind)
+		}
+	}
+	return order
+}
+
+// IsKnownKind reports whether kind is an RBAC kind the operator manages
+func IsKnownKind(kind string) bool {
+	for _, known := range DefaultApplyOrder {
+		if kind == known {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveMergeStrategy renders the config's merge strategy for the namespace in
+// templateCtx and checks the result is a known strategy
+func (m *Manager) resolveMergeStrategy(config *rbacoperatorv1.NamespaceRBACConfig, templateCtx *template.TemplateContext) (rbacoperatorv1.MergeStrategy, error) {
+	rendered, err := m.templateEngine.ProcessTemplate(string(getMergeStrategy(config)), templateCtx)
+	if err != nil {
+		return "", fmt.Errorf("failed to process merge strategy template: %w", err)
+	}
+
+	strategy := rbacoperatorv1.MergeStrategy(strings.TrimSpace(rendered))
+	if !IsKnownMergeStrategy(strategy) {
+		return "", fmt.Errorf("merge strategy %q rendered for namespace %s is not one of merge, replace, ignore, authoritative", strategy, templateCtx.Namespace.Name)
+	}
+	return strategy, nil
+}
+
+// IsKnownMergeStrategy reports whether strategy is one of the supported merge strategies
+func IsKnownMergeStrategy(strategy rbacoperatorv1.MergeStrategy) bool {
+	switch strategy {
+	case rbacoperatorv1.MergeStrategyMerge, rbacoperatorv1.MergeStrategyReplace,
+		rbacoperatorv1.MergeStrategyIgnore, rbacoperatorv1.MergeStrategyAuthoritative:
+		return true
+	}
+	return false
+}
+
+// getMaxConflictRetries returns how many times an update is retried on conflict
+func getMaxConflictRetries(config *rbacoperatorv1.NamespaceRBACConfig) int {
+	if config.Spec.Config != nil && config.Spec.Config.MaxConflictRetries != nil {
+		return *config.Spec.Config.MaxConflictRetries
+	}
+	return DefaultMaxConflictRetries
+}
+
+// getMergeStrategy returns the config's merge strategy, defaulting to merge
+func getMergeStrategy(config *rbacoperatorv1.NamespaceRBACConfig) rbacoperatorv1.MergeStrategy {
+	if config.Spec.Config != nil && config.Spec.Config.MergeStrategy != nil {
+		return *config.Spec.Config.MergeStrategy
+	}
+	return rbacoperatorv1.MergeStrategyMerge
+}
+
+// isMergeFrozen reports whether an existing resource opted out of updates
+// via MergeFreezeAnnotation
+func isMergeFrozen(obj metav1.Object) bool {
+	return obj.GetAnnotations()[MergeFreezeAnnotation] == "true"
+}
+
+// withTemplateChecksum returns annotations with TemplateChecksumAnnotation set to the
+// hash of the template. A changed template therefore always changes the annotations,
+// so the no-op check in the createOrUpdate helpers lets the Update through.
+func withTemplateChecksum(annotations map[string]string, template interface{}) (map[string]string, error) {
+	checksum, err := utils.HashJSON(template)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute template checksum: %w", err)
+	}
+	if annotations == nil {
+		annotations = make(map[string]string, 1)
+	}
+	annotations[TemplateChecksumAnnotation] = checksum
+	return annotations, nil
+}
+
+// metadataUnchanged reports whether an Update with desired would leave the existing
+// resource's labels, annotations and owner references as they are
+func metadataUnchanged(existing, desired metav1.Object) bool {
+	return equality.Semantic.DeepEqual(existing.GetLabels(), desired.GetLabels()) &&
+		equality.Semantic.DeepEqual(existing.GetAnnotations(), desired.GetAnnotations()) &&
+		equality.Semantic.DeepEqual(existing.GetOwnerReferences(), desired.GetOwnerReferences())
 }
 
 // mergeRules merges RBAC policy rules
@@ -530,88 +1808,416 @@ func mergeRules(existing, new []rbacv1.PolicyRule) []rbacv1.PolicyRule {
 	return result
 }
 
-// mergeSubjects merges RBAC subjects
-func mergeSubjects(existing, new []rbacv1.Subject) []rbacv1.Subject {
-	// Simple merge - add new subjects to existing ones, avoiding duplicates
-	subjectMap := make(map[string]rbacv1.Subject)
+// mergeExistingSubjects merges desired subjects into existing ones. When existing
+// already holds every desired subject it is returned as is, keeping its order so an
+// unchanged binding compares equal; mergeSubjects does not preserve order.
+func mergeExistingSubjects(existing, desired []rbacv1.Subject, ignoreCase bool) []rbacv1.Subject {
+	if subjectsMatch(existing, desired, true, ignoreCase) {
+		return existing
+	}
+	return mergeSubjects(existing, desired, ignoreCase)
+}
+
+// mergeSubjects merges RBAC subjects, keeping existing subjects in their order and
+// appending new ones after them. Of subjects sharing a key the first one is kept, so
+// with ignoreCase an existing subject keeps its original casing.
+func mergeSubjects(existing, new []rbacv1.Subject, ignoreCase bool) []rbacv1.Subject {
+	seen := make(map[string]bool, len(existing)+len(new))
+	result := make([]rbacv1.Subject, 0, len(existing)+len(new))
+
+	// Add existing subjects, then new ones not already present
+	for _, subjects := range [][]rbacv1.Subject{existing, new} {
+		for _, subject := range subjects {
+			key := subjectKey(subject, ignoreCase)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			result = append(result, subject)
+		}
+	}
+
+	return result
+}
+
+// subjectKey identifies a subject for deduplication. With ignoreCase the name is
+// lowercased so e.g. the groups Developers and developers share a key.
+func subjectKey(subject rbacv1.Subject, ignoreCase bool) string {
+	name := subject.Name
+	if ignoreCase {
+		name = strings.ToLower(name)
+	}
+	return fmt.Sprintf("%s/%s/%s/%s", subject.Kind, subject.APIGroup, name, subject.Namespace)
+}
+
+// subjectMatchIgnoreCase reports whether the config dedupes subjects case-insensitively
+func subjectMatchIgnoreCase(config *rbacoperatorv1.NamespaceRBACConfig) bool {
+	return config.Spec.Config != nil && config.Spec.Config.SubjectMatchIgnoreCase != nil &&
+		*config.Spec.Config.SubjectMatchIgnoreCase
+}
 
-	// Add existing subjects
-	for _, subject := range existing {
-		key := fmt.Sprintf("%s/%s/%s/%s", subject.Kind, subject.APIGroup, subject.Name, subject.Namespace)
-		subjectMap[key] = subject
+// deleteDanglingBindings removes RoleBindings and ClusterRoleBindings created by the config
+// for a namespace whose RoleRef no longer resolves to an existing Role/ClusterRole.
+// Bindings rendered in the current apply (keep) are never deleted.
+// Only runs when Cleanup.DeleteDanglingBindings is enabled.
+func (m *Manager) deleteDanglingBindings(ctx context.Context, namespaceName string, config *rbacoperatorv1.NamespaceRBACConfig, keep []client.Object) error {
+	if config.Spec.Config == nil || config.Spec.Config.Cleanup == nil ||
+		!utils.BoolPtrValue(config.Spec.Config.Cleanup.DeleteDanglingBindings) {
+		return nil
 	}
 
-	// Add new subjects
-	for _, subject := range new {
-		key := fmt.Sprintf("%s/%s/%s/%s", subject.Kind, subject.APIGroup, subject.Name, subject.Namespace)
-		subjectMap[key] = subject
+	kept := make(map[string]bool, len(keep))
+	for _, obj := range keep {
+		kept[objectKey(obj)] = true
+	}
+	ownedLabels := map[string]string{
+		OwnerLabel:     "namespace-rbac-operator",
+		NamespaceLabel: namespaceName,
 	}
 
-	// Convert back to slice
-	result := make([]rbacv1.Subject, 0, len(subjectMap))
-	for _, subject := range subjectMap {
-		result = append(result, subject)
+	roleBindings := &rbacv1.RoleBindingList{}
+	if err := m.listOwned(ctx, roleBindings, config, ownedLabels, client.InNamespace(namespaceName)); err != nil {
+		return fmt.Errorf("failed to list role bindings: %w", err)
+	}
+	for i := range roleBindings.Items {
+		roleBinding := &roleBindings.Items[i]
+		if kept[objectKey(roleBinding)] {
+			continue
+		}
+		if err := m.deleteIfDangling(ctx, roleBinding, namespaceName, roleBinding.RoleRef, config, "rolebinding"); err != nil {
+			return err
+		}
 	}
 
-	return result
+	clusterRoleBindings := &rbacv1.ClusterRoleBindingList{}
+	if err := m.listOwned(ctx, clusterRoleBindings, config, ownedLabels); err != nil {
+		return fmt.Errorf("failed to list cluster role bindings: %w", err)
+	}
+	for i := range clusterRoleBindings.Items {
+		clusterRoleBinding := &clusterRoleBindings.Items[i]
+		if kept[objectKey(clusterRoleBinding)] {
+			continue
+		}
+		if err := m.deleteIfDangling(ctx, clusterRoleBinding, "", clusterRoleBinding.RoleRef, config, "clusterrolebinding"); err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
 
-// CleanupRBACForNamespace removes RBAC resources for a deleted namespace
-func (m *Manager) CleanupRBACForNamespace(ctx context.Context, namespaceName string, config *rbacoperatorv1.NamespaceRBACConfig) error {
-	// Cleanup namespace-scoped resources (they should be auto-deleted with the namespace)
-	// Focus on cluster-scoped resources that need manual cleanup
+// deleteIfDangling deletes a binding if its RoleRef does not resolve
+func (m *Manager) deleteIfDangling(ctx context.Context, binding client.Object, namespaceName string, roleRef rbacv1.RoleRef, config *rbacoperatorv1.NamespaceRBACConfig, resourceType string) error {
+	var target client.Object
+	key := types.NamespacedName{Name: roleRef.Name}
+	switch roleRef.Kind {
+	case "Role":
+		target = &rbacv1.Role{}
+		key.Namespace = namespaceName
+	case "ClusterRole":
+		target = &rbacv1.ClusterRole{}
+	default:
+		return nil // Unknown kind, leave it alone
+	}
 
-	// Cleanup ClusterRoles if no other namespaces reference them
-	for _, clusterRoleTemplate := range config.Spec.RBACTemplates.ClusterRoles {
-		err := m.cleanupClusterRoleIfOrphaned(ctx, clusterRoleTemplate.Name, namespaceName, config)
-		metrics.RecordCleanup("clusterrole", err)
-		if err != nil {
-			return fmt.Errorf("failed to cleanup cluster role: %w", err)
+	err := m.Get(ctx, key, target)
+	if err == nil {
+		return nil
+	}
+	if !errors.IsNotFound(err) {
+		return fmt.Errorf("failed to resolve role ref %s %s: %w", roleRef.Kind, roleRef.Name, err)
+	}
+
+	err = m.deleteOwned(ctx, binding, config, resourceType)
+	metrics.RecordCleanup(resourceType, err)
+	if err != nil {
+		return fmt.Errorf("failed to delete dangling binding: %w", err)
+	}
+	return nil
+}
+
+// grantsOperator reports whether a ClusterRoleBinding has the operator's own
+// ServiceAccount among its subjects, directly or through its ServiceAccount groups.
+// Deleting such a binding could lock the operator out of the cluster.
+func (m *Manager) grantsOperator(binding *rbacv1.ClusterRoleBinding) bool {
+	if m.operatorNS == "" || m.operatorSA == "" {
+		return false
+	}
+	for _, subject := range binding.Subjects {
+		switch subject.Kind {
+		case rbacv1.ServiceAccountKind:
+			if subject.Name == m.operatorSA && subject.Namespace == m.operatorNS {
+				return true
+			}
+		case rbacv1.GroupKind:
+			if subject.Name == "system:serviceaccounts" || subject.Name == "system:serviceaccounts:"+m.operatorNS {
+				return true
+			}
 		}
 	}
+	return false
+}
+
+// objectKey identifies an object by type, namespace and name
+func objectKey(obj client.Object) string {
+	return fmt.Sprintf("%T/%s/%s", obj, obj.GetNamespace(), obj.GetName())
+}
+
+// CleanupRBACForNamespace removes RBAC resources for a deleted namespace. matchingNamespaces
+// are the sorted names of the namespaces the config still matches, as for ApplyRBACForNamespace.
+func (m *Manager) CleanupRBACForNamespace(ctx context.Context, namespaceName string, config *rbacoperatorv1.NamespaceRBACConfig, matchingNamespaces []string) error {
+	// Remove labels/annotations stamped on a namespace that still exists
+	start := time.Now()
+	err := m.cleanupNamespaceMetadata(ctx, namespaceName, config, matchingNamespaces)
+	metrics.RecordCleanupDuration("namespace", time.Since(start))
+	if err != nil {
+		return fmt.Errorf("failed to cleanup namespace metadata: %w", err)
+	}
+	if err := m.updateManagedByConfigs(ctx, namespaceName, config.Name, false); err != nil {
+		return fmt.Errorf("failed to remove managing config from namespace: %w", err)
+	}
 
-	// Cleanup ClusterRoleBindings if no other namespaces reference them
-	for _, clusterRoleBindingTemplate := range config.Spec.RBACTemplates.ClusterRoleBindings {
-		err := m.cleanupClusterRoleBindingIfOrphaned(ctx, clusterRoleBindingTemplate.Name, namespaceName, config)
-		metrics.RecordCleanup("clusterrolebinding", err)
+	// Delete bindings before the roles and ServiceAccounts they reference, and namespaced
+	// resources before cluster-scoped ones, so no binding is left dangling, even momentarily
+	steps := []struct {
+		resourceType string
+		cleanup      func() error
+	}{
+		{"rolebinding", func() error {
+			return m.cleanupNamespaced(ctx, &rbacv1.RoleBindingList{}, namespaceName, config, "rolebinding")
+		}},
+		{"clusterrolebinding", func() error {
+			for _, t := range config.Spec.RBACTemplates.ClusterRoleBindings {
+				if err := m.cleanupClusterRoleBindingIfOrphaned(ctx, t.Name, namespaceName, config, matchingNamespaces); err != nil {
+					return err
+				}
+			}
+			return nil
+		}},
+		{"role", func() error {
+			return m.cleanupNamespaced(ctx, &rbacv1.RoleList{}, namespaceName, config, "role")
+		}},
+		{"serviceaccount", func() error {
+			return m.cleanupNamespaced(ctx, &corev1.ServiceAccountList{}, namespaceName, config, "serviceaccount")
+		}},
+		{"clusterrole", func() error {
+			for _, t := range config.Spec.RBACTemplates.ClusterRoles {
+				if err := m.cleanupClusterRoleIfOrphaned(ctx, t, namespaceName, config, matchingNamespaces); err != nil {
+					return err
+				}
+			}
+			return nil
+		}},
+	}
+	for _, step := range steps {
+		start := time.Now()
+		err := step.cleanup()
+		metrics.RecordCleanupDuration(step.resourceType, time.Since(start))
+		metrics.RecordCleanup(step.resourceType, err)
 		if err != nil {
-			return fmt.Errorf("failed to cleanup cluster role binding: %w", err)
+			return fmt.Errorf("failed to cleanup %s: %w", step.resourceType, err)
 		}
 	}
 
 	return nil
 }
 
-// cleanupClusterRoleIfOrphaned removes a ClusterRole if no namespaces reference it
-func (m *Manager) cleanupClusterRoleIfOrphaned(ctx context.Context, nameTemplate, namespaceName string, config *rbacoperatorv1.NamespaceRBACConfig) error {
-	// This is a simplified implementation
-	// In production, you'd want to check if other namespaces still reference this ClusterRole
-	// For now, we'll implement basic cleanup logic
+// CleanupRBACForConfig removes everything a deleted config generated. Resources are found
+// by their ConfigLabel and namespaces by their ManagedByConfigsAnnotation rather than
+// from the config's status, whose AppliedNamespaces may be truncated. Shared
+// cluster-scoped resources are deleted too, since no namespace references them any more.
+func (m *Manager) CleanupRBACForConfig(ctx context.Context, config *rbacoperatorv1.NamespaceRBACConfig) error {
+	namespaceList := &corev1.NamespaceList{}
+	if err := m.List(ctx, namespaceList); err != nil {
+		return fmt.Errorf("failed to list namespaces: %w", err)
+	}
+	matching, err := m.matchingNamespaceNames(ctx, namespaceList.Items, config)
+	if err != nil {
+		return err
+	}
 
-	// Check cleanup configuration
-	if config.Spec.Config == nil || config.Spec.Config.Cleanup == nil ||
-		config.Spec.Config.Cleanup.DeleteOrphanedClusterResources == nil ||
-		!*config.Spec.Config.Cleanup.DeleteOrphanedClusterResources {
-		return nil // Cleanup disabled
+	// Remove namespace metadata from every namespace the config was applied to
+	var errs []error
+	for i := range namespaceList.Items {
+		ns := &namespaceList.Items[i]
+		if !ManagedBy(ns, config.Name) && !utils.SliceContains(config.Status.AppliedNamespaces, ns.Name) &&
+			!utils.SliceContains(matching, ns.Name) {
+			continue
+		}
+		start := time.Now()
+		err := m.cleanupNamespaceMetadata(ctx, ns.Name, config, matching)
+		metrics.RecordCleanupDuration("namespace", time.Since(start))
+		if err == nil {
+			err = m.updateManagedByConfigs(ctx, ns.Name, config.Name, false)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("namespace %s: %w", ns.Name, err))
+		}
+	}
+
+	// Delete in the same order as CleanupRBACForNamespace: bindings before roles and
+	// ServiceAccounts, namespaced before cluster-scoped
+	steps := []struct {
+		resourceType string
+		list         client.ObjectList
+		clusterScope bool
+	}{
+		{"rolebinding", &rbacv1.RoleBindingList{}, false},
+		{"clusterrolebinding", &rbacv1.ClusterRoleBindingList{}, true},
+		{"role", &rbacv1.RoleList{}, false},
+		{"serviceaccount", &corev1.ServiceAccountList{}, false},
+		{"clusterrole", &rbacv1.ClusterRoleList{}, true},
+	}
+	for _, step := range steps {
+		if step.clusterScope && !deleteOrphanedClusterResources(config) {
+			continue
+		}
+		start := time.Now()
+		err := m.deleteAllOwned(ctx, step.list, config, step.resourceType)
+		metrics.RecordCleanupDuration(step.resourceType, time.Since(start))
+		metrics.RecordCleanup(step.resourceType, err)
+		if err != nil {
+			// Stop so roles are never deleted ahead of bindings that failed to delete
+			errs = append(errs, fmt.Errorf("failed to cleanup %s: %w", step.resourceType, err))
+			break
+		}
 	}
 
-	// TODO: Implement reference counting logic
-	// This would involve checking all namespaces that match the selector
-	// and seeing if any still exist and would generate this same ClusterRole name
+	return utilerrors.NewAggregate(errs)
+}
+
+// cleanupNamespaced deletes the config's generated resources of the list's kind in the
+// namespace. They are garbage collected with a deleted namespace, but must be removed
+// explicitly from one that merely stopped matching.
+func (m *Manager) cleanupNamespaced(ctx context.Context, list client.ObjectList, namespaceName string, config *rbacoperatorv1.NamespaceRBACConfig, resourceType string) error {
+	return m.deleteAllOwned(ctx, list, config, resourceType, client.InNamespace(namespaceName))
+}
 
+// deleteAllOwned deletes every generated resource of the list's kind labelled with the
+// config, narrowed by opts
+func (m *Manager) deleteAllOwned(ctx context.Context, list client.ObjectList, config *rbacoperatorv1.NamespaceRBACConfig, resourceType string, opts ...client.ListOption) error {
+	if err := m.listOwned(ctx, list, config, map[string]string{OwnerLabel: "namespace-rbac-operator"}, opts...); err != nil {
+		return fmt.Errorf("failed to list %ss: %w", resourceType, err)
+	}
+	items, err := meta.ExtractList(list)
+	if err != nil {
+		return err
+	}
+	for _, item := range items {
+		obj, ok := item.(client.Object)
+		if !ok {
+			continue
+		}
+		if err := m.deleteOwned(ctx, obj, config, resourceType); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
-// cleanupClusterRoleBindingIfOrphaned removes a ClusterRoleBinding if no namespaces reference it
-func (m *Manager) cleanupClusterRoleBindingIfOrphaned(ctx context.Context, nameTemplate, namespaceName string, config *rbacoperatorv1.NamespaceRBACConfig) error {
-	// Similar to cleanupClusterRoleIfOrphaned
-	if config.Spec.Config == nil || config.Spec.Config.Cleanup == nil ||
-		config.Spec.Config.Cleanup.DeleteOrphanedClusterResources == nil ||
-		!*config.Spec.Config.Cleanup.DeleteOrphanedClusterResources {
+// deleteOwned deletes a generated resource; one that is already gone is not an error.
+// Every delete goes through here, so ClusterRoleBindings granting the operator's own
+// ServiceAccount are never deleted, whatever path asks for it.
+func (m *Manager) deleteOwned(ctx context.Context, obj client.Object, config *rbacoperatorv1.NamespaceRBACConfig, resourceType string) error {
+	if clusterRoleBinding, ok := obj.(*rbacv1.ClusterRoleBinding); ok && m.grantsOperator(clusterRoleBinding) {
+		log.FromContext(ctx).Info("WARNING: refusing to delete ClusterRoleBinding that grants the operator's ServiceAccount",
+			"clusterRoleBinding", clusterRoleBinding.Name, "config", config.Name)
 		return nil
 	}
 
-	// TODO: Implement reference counting logic
-
+	err := m.Delete(ctx, obj)
+	if errors.IsNotFound(err) {
+		err = nil
+	}
+	metrics.RecordResourceOperation(config.Name, resourceType, "delete", err)
+	if err != nil {
+		return fmt.Errorf("failed to delete %s %s: %w", resourceType, obj.GetName(), err)
+	}
 	return nil
 }
+
+// deleteOrphanedClusterResources reports whether the config asks for cluster-scoped
+// resources to be deleted once no namespace references them
+func deleteOrphanedClusterResources(config *rbacoperatorv1.NamespaceRBACConfig) bool {
+	return config.Spec.Config != nil && config.Spec.Config.Cleanup != nil &&
+		utils.BoolPtrValue(config.Spec.Config.Cleanup.DeleteOrphanedClusterResources)
+}
+
+// namePerNamespace reports whether a cluster-scoped name template renders a distinct
+// name for each namespace: as declared by perNamespace when set, otherwise as rendered
+func (m *Manager) namePerNamespace(config *rbacoperatorv1.NamespaceRBACConfig, nameTemplate string, perNamespace *bool) (bool, error) {
+	if perNamespace != nil {
+		return *perNamespace, nil
+	}
+	if getNamingStrategy(config) == rbacoperatorv1.NamingStrategyHashed {
+		return true, nil // The namespace is part of every hashed name
+	}
+	return m.templateEngine.VariesByNamespace(nameTemplate)
+}
+
+// orphanedClusterResource renders the name of a cluster-scoped resource generated for the
+// namespace and fetches it into obj. It reports false when cleanup is disabled, the
+// resource is shared and another matching namespace still references it, or the
+// resource does not exist or was not created by the config.
+func (m *Manager) orphanedClusterResource(ctx context.Context, obj client.Object, nameTemplate string, perNamespace *bool, namespaceName string, config *rbacoperatorv1.NamespaceRBACConfig, matching []string) (bool, error) {
+	if !deleteOrphanedClusterResources(config) {
+		return false, nil // Cleanup disabled
+	}
+
+	unique, err := m.namePerNamespace(config, nameTemplate, perNamespace)
+	if err != nil {
+		return false, nil // Rendering errors are reported at apply time
+	}
+	if !unique {
+		for _, name := range matching {
+			if name != namespaceName {
+				return false, nil // Still referenced by another namespace
+			}
+		}
+	}
+
+	// The namespace may already be gone; its name is enough to render the resource name
+	ns := &corev1.Namespace{}
+	if err := m.Get(ctx, types.NamespacedName{Name: namespaceName}, ns); err != nil {
+		if !errors.IsNotFound(err) {
+			return false, err
+		}
+		ns = &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: namespaceName}}
+	}
+	name, err := m.resolveName(config, nameTemplate, m.templateEngine.BuildContext(ns, config, matching))
+	if err != nil {
+		return false, fmt.Errorf("failed to process name template: %w", err)
+	}
+
+	if err := m.Get(ctx, types.NamespacedName{Name: name}, obj); err != nil {
+		if errors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return obj.GetLabels()[ConfigLabel] == config.Name, nil // Otherwise not created by this config
+}
+
+// cleanupClusterRoleIfOrphaned removes a ClusterRole if no namespaces reference it.
+// A per-namespace ClusterRole belongs to the namespace alone and is always removed;
+// a shared one is kept while any other namespace still matches the config.
+func (m *Manager) cleanupClusterRoleIfOrphaned(ctx context.Context, roleTemplate rbacoperatorv1.ClusterRoleTemplate, namespaceName string, config *rbacoperatorv1.NamespaceRBACConfig, matching []string) error {
+	clusterRole := &rbacv1.ClusterRole{}
+	orphaned, err := m.orphanedClusterResource(ctx, clusterRole, roleTemplate.Name, roleTemplate.PerNamespace, namespaceName, config, matching)
+	if err != nil || !orphaned {
+		return err
+	}
+	return m.deleteOwned(ctx, clusterRole, config, "clusterrole")
+}
+
+// cleanupClusterRoleBindingIfOrphaned removes a ClusterRoleBinding if no namespaces
+// reference it, on the same terms as cleanupClusterRoleIfOrphaned. Binding templates
+// declare no scope, so it is inferred from whether the name varies by namespace.
+func (m *Manager) cleanupClusterRoleBindingIfOrphaned(ctx context.Context, nameTemplate, namespaceName string, config *rbacoperatorv1.NamespaceRBACConfig, matching []string) error {
+	clusterRoleBinding := &rbacv1.ClusterRoleBinding{}
+	orphaned, err := m.orphanedClusterResource(ctx, clusterRoleBinding, nameTemplate, nil, namespaceName, config, matching)
+	if err != nil || !orphaned {
+		return err
+	}
+	return m.deleteOwned(ctx, clusterRoleBinding, config, "clusterrolebinding")
+}
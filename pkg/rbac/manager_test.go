@@ -0,0 +1,2437 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rbac
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/discovery"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	rbacoperatorv1 "github.com/cropalato/k8s-acl-operator/pkg/apis/rbac/v1"
+	"github.com/cropalato/k8s-acl-operator/pkg/metrics"
+	"github.com/cropalato/k8s-acl-operator/pkg/utils"
+)
+
+// newTestScheme returns a scheme with the core, RBAC and operator types registered
+func newTestScheme(t *testing.T) *runtime.Scheme {
+	t.Helper()
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := rbacoperatorv1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	return scheme
+}
+
+// newFakeClient returns a fake client holding objs, with funcs intercepting its calls
+func newFakeClient(t *testing.T, funcs interceptor.Funcs, objs ...client.Object) client.WithWatch {
+	t.Helper()
+	return fake.NewClientBuilder().
+		WithScheme(newTestScheme(t)).
+		WithObjects(objs...).
+		WithInterceptorFuncs(funcs).
+		Build()
+}
+
+// testNamespace returns a namespace with the given name and labels
+func testNamespace(name string, labels map[string]string) *corev1.Namespace {
+	return &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels}}
+}
+
+// testConfig returns a config selecting namespaces labelled team=a
+func testConfig(name string) *rbacoperatorv1.NamespaceRBACConfig {
+	return &rbacoperatorv1.NamespaceRBACConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: name, UID: types.UID(name + "-uid"), Generation: 1},
+		Spec: rbacoperatorv1.NamespaceRBACConfigSpec{
+			NamespaceSelector: rbacoperatorv1.NamespaceSelector{Labels: map[string]string{"team": "a"}},
+		},
+	}
+}
+
+func TestMatchingNamespaces(t *testing.T) {
+	tests := []struct {
+		name       string
+		namespaces []client.Object
+		operatorNS string
+		allowOpNS  bool
+		want       []string
+	}{
+		{
+			name:       "none match",
+			namespaces: []client.Object{testNamespace("other", nil)},
+			want:       []string{},
+		},
+		{
+			name: "sorted by name",
+			namespaces: []client.Object{
+				testNamespace("team-b", map[string]string{"team": "a"}),
+				testNamespace("team-a", map[string]string{"team": "a"}),
+				testNamespace("other", map[string]string{"team": "b"}),
+			},
+			want: []string{"team-a", "team-b"},
+		},
+		{
+			name: "operator namespace skipped",
+			namespaces: []client.Object{
+				testNamespace("operator", map[string]string{"team": "a"}),
+				testNamespace("team-a", map[string]string{"team": "a"}),
+			},
+			operatorNS: "operator",
+			want:       []string{"team-a"},
+		},
+		{
+			name: "operator namespace allowed by config",
+			namespaces: []client.Object{
+				testNamespace("operator", map[string]string{"team": "a"}),
+				testNamespace("team-a", map[string]string{"team": "a"}),
+			},
+			operatorNS: "operator",
+			allowOpNS:  true,
+			want:       []string{"operator", "team-a"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewManager(newFakeClient(t, interceptor.Funcs{}, tt.namespaces...), Options{OperatorNamespace: tt.operatorNS})
+			config := testConfig("cfg")
+			if tt.allowOpNS {
+				config.Spec.Config = &rbacoperatorv1.NamespaceRBACConfigConfig{AllowOperatorNamespace: &tt.allowOpNS}
+			}
+			got, err := m.MatchingNamespaces(context.Background(), config)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("MatchingNamespaces() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyRBACForNamespaceRendersMatchingNamespaces(t *testing.T) {
+	nsA := testNamespace("team-a", map[string]string{"team": "a"})
+	nsB := testNamespace("team-b", map[string]string{"team": "a"})
+
+	var namespaceLists int
+	c := newFakeClient(t, interceptor.Funcs{
+		List: func(ctx context.Context, c client.WithWatch, list client.ObjectList, opts ...client.ListOption) error {
+			if _, ok := list.(*corev1.NamespaceList); ok {
+				namespaceLists++
+			}
+			return c.List(ctx, list, opts...)
+		},
+	}, nsA, nsB)
+	m := NewManager(c, Options{})
+
+	config := testConfig("cfg")
+	config.Spec.RBACTemplates.Roles = []rbacoperatorv1.RoleTemplate{{
+		Name: "viewer",
+		Annotations: map[string]string{
+			"docs": `{{ range $i, $ns := matchingNamespaces }}{{ if $i }},{{ end }}{{ $ns }}{{ end }}`,
+		},
+	}}
+
+	matching, err := m.MatchingNamespaces(context.Background(), config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	namespaceLists = 0
+	for _, ns := range []*corev1.Namespace{nsA, nsB} {
+		if _, err := m.ApplyRBACForNamespace(context.Background(), ns, config, matching); err != nil {
+			t.Fatalf("ApplyRBACForNamespace(%s): %v", ns.Name, err)
+		}
+
+		role := &rbacv1.Role{}
+		if err := c.Get(context.Background(), types.NamespacedName{Namespace: ns.Name, Name: "viewer"}, role); err != nil {
+			t.Fatal(err)
+		}
+		if got, want := role.Annotations["docs"], "team-a,team-b"; got != want {
+			t.Errorf("%s: docs annotation = %q, want %q", ns.Name, got, want)
+		}
+	}
+	if namespaceLists != 0 {
+		t.Errorf("ApplyRBACForNamespace listed namespaces %d times, want 0", namespaceLists)
+	}
+}
+
+func TestApplySkipsMergeFrozenResources(t *testing.T) {
+	frozen := map[string]string{MergeFreezeAnnotation: "true"}
+	manualRules := []rbacv1.PolicyRule{{APIGroups: []string{""}, Resources: []string{"secrets"}, Verbs: []string{"get"}}}
+	manualSubjects := []rbacv1.Subject{{Kind: rbacv1.UserKind, APIGroup: rbacv1.GroupName, Name: "oncall"}}
+	roleRef := rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "Role", Name: "viewer"}
+
+	tests := []struct {
+		name         string
+		existing     client.Object
+		wantFrozen   string
+		checkUnmoved func(t *testing.T, c client.Client)
+	}{
+		{
+			name:       "role",
+			existing:   &rbacv1.Role{ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "viewer", Annotations: frozen}, Rules: manualRules},
+			wantFrozen: "Role team-a/viewer",
+			checkUnmoved: func(t *testing.T, c client.Client) {
+				role := &rbacv1.Role{}
+				if err := c.Get(context.Background(), types.NamespacedName{Namespace: "team-a", Name: "viewer"}, role); err != nil {
+					t.Fatal(err)
+				}
+				if !reflect.DeepEqual(role.Rules, manualRules) {
+					t.Errorf("Rules = %v, want untouched %v", role.Rules, manualRules)
+				}
+			},
+		},
+		{
+			name: "rolebinding",
+			existing: &rbacv1.RoleBinding{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "viewer", Annotations: frozen},
+				RoleRef:    roleRef,
+				Subjects:   manualSubjects,
+			},
+			wantFrozen: "RoleBinding team-a/viewer",
+			checkUnmoved: func(t *testing.T, c client.Client) {
+				binding := &rbacv1.RoleBinding{}
+				if err := c.Get(context.Background(), types.NamespacedName{Namespace: "team-a", Name: "viewer"}, binding); err != nil {
+					t.Fatal(err)
+				}
+				if !reflect.DeepEqual(binding.Subjects, manualSubjects) {
+					t.Errorf("Subjects = %v, want untouched %v", binding.Subjects, manualSubjects)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ns := testNamespace("team-a", map[string]string{"team": "a"})
+			c := newFakeClient(t, interceptor.Funcs{}, ns, tt.existing)
+			m := NewManager(c, Options{})
+
+			config := testConfig("cfg")
+			config.Spec.RBACTemplates = rbacoperatorv1.RBACTemplates{
+				Roles: []rbacoperatorv1.RoleTemplate{{
+					Name:  "viewer",
+					Rules: []rbacv1.PolicyRule{{APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: []string{"get"}}},
+				}},
+				RoleBindings: []rbacoperatorv1.RoleBindingTemplate{{
+					Name:     "viewer",
+					RoleRef:  roleRef,
+					Subjects: []rbacv1.Subject{{Kind: rbacv1.GroupKind, APIGroup: rbacv1.GroupName, Name: "team-a"}},
+				}},
+			}
+
+			result, err := m.ApplyRBACForNamespace(context.Background(), ns, config, []string{ns.Name})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(result.FrozenResources, []string{tt.wantFrozen}) {
+				t.Errorf("FrozenResources = %v, want [%s]", result.FrozenResources, tt.wantFrozen)
+			}
+			tt.checkUnmoved(t, c)
+		})
+	}
+}
+
+func TestNamespaceMetadataStampedAndCleanedUp(t *testing.T) {
+	tests := []struct {
+		name          string
+		strategy      rbacoperatorv1.MergeStrategy
+		existing      map[string]string
+		wantApplied   map[string]string
+		wantCleanedUp map[string]string
+	}{
+		{
+			name:          "new label",
+			strategy:      rbacoperatorv1.MergeStrategyMerge,
+			wantApplied:   map[string]string{"team": "a", "rbac.operator.io/managed-by": "cfg"},
+			wantCleanedUp: map[string]string{"team": "a"},
+		},
+		{
+			name:          "merge keeps a foreign value",
+			strategy:      rbacoperatorv1.MergeStrategyMerge,
+			existing:      map[string]string{"rbac.operator.io/managed-by": "someone-else"},
+			wantApplied:   map[string]string{"team": "a", "rbac.operator.io/managed-by": "someone-else"},
+			wantCleanedUp: map[string]string{"team": "a", "rbac.operator.io/managed-by": "someone-else"},
+		},
+		{
+			name:          "replace overwrites a foreign value",
+			strategy:      rbacoperatorv1.MergeStrategyReplace,
+			existing:      map[string]string{"rbac.operator.io/managed-by": "someone-else"},
+			wantApplied:   map[string]string{"team": "a", "rbac.operator.io/managed-by": "cfg"},
+			wantCleanedUp: map[string]string{"team": "a"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			labels := map[string]string{"team": "a"}
+			for k, v := range tt.existing {
+				labels[k] = v
+			}
+			ns := testNamespace("team-a", labels)
+			c := newFakeClient(t, interceptor.Funcs{}, ns)
+			m := NewManager(c, Options{})
+
+			config := testConfig("cfg")
+			strategy := tt.strategy
+			config.Spec.Config = &rbacoperatorv1.NamespaceRBACConfigConfig{
+				MergeStrategy:   &strategy,
+				NamespaceLabels: map[string]string{"rbac.operator.io/managed-by": "{{ .CRD.Name }}"},
+			}
+
+			if _, err := m.ApplyRBACForNamespace(context.Background(), ns, config, []string{ns.Name}); err != nil {
+				t.Fatal(err)
+			}
+			got := &corev1.Namespace{}
+			if err := c.Get(context.Background(), types.NamespacedName{Name: ns.Name}, got); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got.Labels, tt.wantApplied) {
+				t.Errorf("labels after apply = %v, want %v", got.Labels, tt.wantApplied)
+			}
+
+			if err := m.CleanupRBACForNamespace(context.Background(), ns.Name, config, nil); err != nil {
+				t.Fatal(err)
+			}
+			if err := c.Get(context.Background(), types.NamespacedName{Name: ns.Name}, got); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got.Labels, tt.wantCleanedUp) {
+				t.Errorf("labels after cleanup = %v, want %v", got.Labels, tt.wantCleanedUp)
+			}
+		})
+	}
+}
+
+// cleanupTestConfig returns a config with one template of each kind and cleanup of
+// orphaned cluster resources enabled
+func cleanupTestConfig() *rbacoperatorv1.NamespaceRBACConfig {
+	deleteOrphaned := true
+	perNamespace := true
+	config := testConfig("cfg")
+	config.Spec.Config = &rbacoperatorv1.NamespaceRBACConfigConfig{
+		Cleanup: &rbacoperatorv1.CleanupConfig{DeleteOrphanedClusterResources: &deleteOrphaned},
+	}
+	config.Spec.RBACTemplates = rbacoperatorv1.RBACTemplates{
+		Roles:        []rbacoperatorv1.RoleTemplate{{Name: "viewer"}},
+		ClusterRoles: []rbacoperatorv1.ClusterRoleTemplate{{Name: "viewer-{{ .Namespace.Name }}", PerNamespace: &perNamespace}},
+		RoleBindings: []rbacoperatorv1.RoleBindingTemplate{{
+			Name:     "viewer",
+			RoleRef:  rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: KindRole, Name: "viewer"},
+			Subjects: []rbacv1.Subject{{Kind: rbacv1.GroupKind, APIGroup: rbacv1.GroupName, Name: "team-a"}},
+		}},
+		ClusterRoleBindings: []rbacoperatorv1.ClusterRoleBindingTemplate{{
+			Name:     "viewer-{{ .Namespace.Name }}",
+			RoleRef:  rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: KindClusterRole, Name: "viewer-{{ .Namespace.Name }}"},
+			Subjects: []rbacv1.Subject{{Kind: rbacv1.GroupKind, APIGroup: rbacv1.GroupName, Name: "team-a"}},
+		}},
+	}
+	return config
+}
+
+func TestCleanupRBACForNamespaceDeleteOrder(t *testing.T) {
+	ns := testNamespace("team-a", map[string]string{"team": "a"})
+	var deleted []string
+	c := newFakeClient(t, interceptor.Funcs{
+		Delete: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.DeleteOption) error {
+			deleted = append(deleted, fmt.Sprintf("%T %s", obj, obj.GetName()))
+			return c.Delete(ctx, obj, opts...)
+		},
+	}, ns)
+	m := NewManager(c, Options{})
+	config := cleanupTestConfig()
+
+	if _, err := m.ApplyRBACForNamespace(context.Background(), ns, config, []string{ns.Name}); err != nil {
+		t.Fatal(err)
+	}
+	// The namespace stopped matching, so nothing matches any more
+	if err := m.CleanupRBACForNamespace(context.Background(), ns.Name, config, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		"*v1.RoleBinding viewer",
+		"*v1.ClusterRoleBinding viewer-team-a",
+		"*v1.Role viewer",
+		"*v1.ClusterRole viewer-team-a",
+	}
+	if !reflect.DeepEqual(deleted, want) {
+		t.Errorf("delete order = %v, want %v", deleted, want)
+	}
+}
+
+func TestCleanupRecordsDuration(t *testing.T) {
+	tests := []struct {
+		name           string
+		deleteOrphaned bool
+		cleanup        func(m *Manager, config *rbacoperatorv1.NamespaceRBACConfig) error
+		want           int
+	}{
+		{
+			name:           "namespace cleanup",
+			deleteOrphaned: true,
+			cleanup: func(m *Manager, config *rbacoperatorv1.NamespaceRBACConfig) error {
+				return m.CleanupRBACForNamespace(context.Background(), "team-a", config, nil)
+			},
+			// namespace, rolebinding, clusterrolebinding, role, serviceaccount, clusterrole
+			want: 6,
+		},
+		{
+			name:           "config cleanup",
+			deleteOrphaned: true,
+			cleanup: func(m *Manager, config *rbacoperatorv1.NamespaceRBACConfig) error {
+				return m.CleanupRBACForConfig(context.Background(), config)
+			},
+			want: 6,
+		},
+		{
+			name:           "config cleanup keeping cluster resources",
+			deleteOrphaned: false,
+			cleanup: func(m *Manager, config *rbacoperatorv1.NamespaceRBACConfig) error {
+				return m.CleanupRBACForConfig(context.Background(), config)
+			},
+			// The cluster-scoped steps are skipped
+			want: 4,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ns := testNamespace("team-a", map[string]string{"team": "a"})
+			m := NewManager(newFakeClient(t, interceptor.Funcs{}, ns), Options{})
+			config := cleanupTestConfig()
+			config.Spec.Config.Cleanup.DeleteOrphanedClusterResources = &tt.deleteOrphaned
+			if _, err := m.ApplyRBACForNamespace(context.Background(), ns, config, []string{ns.Name}); err != nil {
+				t.Fatal(err)
+			}
+
+			metrics.ResetMetrics()
+			if err := tt.cleanup(m, config); err != nil {
+				t.Fatal(err)
+			}
+			// One series per resource type, each created by an observation
+			if got := testutil.CollectAndCount(metrics.CleanupDuration); got != tt.want {
+				t.Errorf("cleanup duration series = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCleanupClusterRoleBindingIfOrphaned(t *testing.T) {
+	tests := []struct {
+		name         string
+		nameTemplate string
+		matching     []string
+		wantDeleted  bool
+	}{
+		{
+			name:         "per-namespace binding is deleted",
+			nameTemplate: "viewer-{{ .Namespace.Name }}",
+			matching:     []string{"team-a", "team-b"},
+			wantDeleted:  true,
+		},
+		{
+			name:         "shared binding kept while another namespace matches",
+			nameTemplate: "viewer",
+			matching:     []string{"team-a", "team-b"},
+			wantDeleted:  false,
+		},
+		{
+			name:         "shared binding deleted once no other namespace matches",
+			nameTemplate: "viewer",
+			matching:     []string{"team-a"},
+			wantDeleted:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ns := testNamespace("team-a", map[string]string{"team": "a"})
+			m := NewManager(newFakeClient(t, interceptor.Funcs{}, ns), Options{})
+			config := cleanupTestConfig()
+			config.Spec.RBACTemplates.ClusterRoleBindings[0].Name = tt.nameTemplate
+			if _, err := m.ApplyRBACForNamespace(context.Background(), ns, config, tt.matching); err != nil {
+				t.Fatal(err)
+			}
+			name, err := m.resolveName(config, tt.nameTemplate, m.templateEngine.BuildContext(ns, config, tt.matching))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if err := m.cleanupClusterRoleBindingIfOrphaned(context.Background(), tt.nameTemplate, ns.Name, config, tt.matching); err != nil {
+				t.Fatal(err)
+			}
+
+			err = m.Get(context.Background(), types.NamespacedName{Name: name}, &rbacv1.ClusterRoleBinding{})
+			if deleted := errors.IsNotFound(err); deleted != tt.wantDeleted {
+				t.Errorf("deleted = %v (err %v), want %v", deleted, err, tt.wantDeleted)
+			}
+		})
+	}
+}
+
+func TestCleanupKeepsBindingsGrantingOperator(t *testing.T) {
+	tests := []struct {
+		name        string
+		subject     rbacv1.Subject
+		wantDeleted bool
+	}{
+		{
+			name:        "operator service account",
+			subject:     rbacv1.Subject{Kind: rbacv1.ServiceAccountKind, Name: "operator", Namespace: "operator-system"},
+			wantDeleted: false,
+		},
+		{
+			name:        "service accounts of the operator namespace",
+			subject:     rbacv1.Subject{Kind: rbacv1.GroupKind, APIGroup: rbacv1.GroupName, Name: "system:serviceaccounts:operator-system"},
+			wantDeleted: false,
+		},
+		{
+			name:        "other service account",
+			subject:     rbacv1.Subject{Kind: rbacv1.ServiceAccountKind, Name: "operator", Namespace: "team-a"},
+			wantDeleted: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ns := testNamespace("team-a", map[string]string{"team": "a"})
+			m := NewManager(newFakeClient(t, interceptor.Funcs{}, ns), Options{
+				OperatorNamespace:      "operator-system",
+				OperatorServiceAccount: "operator",
+			})
+			config := cleanupTestConfig()
+			config.Spec.RBACTemplates.ClusterRoleBindings[0].Subjects = []rbacv1.Subject{tt.subject}
+			if _, err := m.ApplyRBACForNamespace(context.Background(), ns, config, []string{ns.Name}); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := m.CleanupRBACForNamespace(context.Background(), ns.Name, config, nil); err != nil {
+				t.Fatal(err)
+			}
+
+			err := m.Get(context.Background(), types.NamespacedName{Name: "viewer-team-a"}, &rbacv1.ClusterRoleBinding{})
+			if deleted := errors.IsNotFound(err); deleted != tt.wantDeleted {
+				t.Errorf("deleted = %v (err %v), want %v", deleted, err, tt.wantDeleted)
+			}
+		})
+	}
+}
+
+func TestDeleteDanglingBindingsKeepsBindingGrantingOperator(t *testing.T) {
+	binding := &rbacv1.ClusterRoleBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "operator-access",
+			Labels: map[string]string{OwnerLabel: "namespace-rbac-operator", ConfigLabel: "cfg", NamespaceLabel: "team-a"},
+		},
+		RoleRef:  rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: KindClusterRole, Name: "missing"},
+		Subjects: []rbacv1.Subject{{Kind: rbacv1.ServiceAccountKind, Name: "operator", Namespace: "operator-system"}},
+	}
+	m := NewManager(newFakeClient(t, interceptor.Funcs{}, binding), Options{
+		OperatorNamespace:      "operator-system",
+		OperatorServiceAccount: "operator",
+	})
+	config := testConfig("cfg")
+	deleteDangling := true
+	config.Spec.Config = &rbacoperatorv1.NamespaceRBACConfigConfig{
+		Cleanup: &rbacoperatorv1.CleanupConfig{DeleteDanglingBindings: &deleteDangling},
+	}
+
+	if err := m.deleteDanglingBindings(context.Background(), "team-a", config, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Get(context.Background(), types.NamespacedName{Name: binding.Name}, &rbacv1.ClusterRoleBinding{}); err != nil {
+		t.Errorf("binding granting the operator was deleted: %v", err)
+	}
+}
+
+func TestCreateOrUpdateRaceWithConcurrentCreate(t *testing.T) {
+	rules := []rbacv1.PolicyRule{{APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: []string{"get"}}}
+	subjects := []rbacv1.Subject{{Kind: rbacv1.GroupKind, APIGroup: rbacv1.GroupName, Name: "team-a"}}
+	roleRef := rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "Role", Name: "viewer"}
+
+	tests := []struct {
+		name     string
+		existing client.Object
+		apply    func(m *Manager, config *rbacoperatorv1.NamespaceRBACConfig) error
+		check    func(t *testing.T, c client.Client)
+	}{
+		{
+			name:     "role",
+			existing: &rbacv1.Role{ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "viewer"}},
+			apply: func(m *Manager, config *rbacoperatorv1.NamespaceRBACConfig) error {
+				role := &rbacv1.Role{ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "viewer"}, Rules: rules}
+				return m.createOrUpdateRole(context.Background(), role, config, rbacoperatorv1.MergeStrategyReplace)
+			},
+			check: func(t *testing.T, c client.Client) {
+				role := &rbacv1.Role{}
+				if err := c.Get(context.Background(), types.NamespacedName{Namespace: "team-a", Name: "viewer"}, role); err != nil {
+					t.Fatal(err)
+				}
+				if !reflect.DeepEqual(role.Rules, rules) {
+					t.Errorf("Rules = %v, want %v", role.Rules, rules)
+				}
+			},
+		},
+		{
+			name:     "rolebinding",
+			existing: &rbacv1.RoleBinding{ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "viewer"}, RoleRef: roleRef},
+			apply: func(m *Manager, config *rbacoperatorv1.NamespaceRBACConfig) error {
+				binding := &rbacv1.RoleBinding{ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "viewer"}, RoleRef: roleRef, Subjects: subjects}
+				return m.createOrUpdateRoleBinding(context.Background(), binding, config, rbacoperatorv1.MergeStrategyReplace)
+			},
+			check: func(t *testing.T, c client.Client) {
+				binding := &rbacv1.RoleBinding{}
+				if err := c.Get(context.Background(), types.NamespacedName{Namespace: "team-a", Name: "viewer"}, binding); err != nil {
+					t.Fatal(err)
+				}
+				if !reflect.DeepEqual(binding.Subjects, subjects) {
+					t.Errorf("Subjects = %v, want %v", binding.Subjects, subjects)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The first Get misses the object, as if it were created right after it
+			var gets int
+			c := newFakeClient(t, interceptor.Funcs{
+				Get: func(ctx context.Context, c client.WithWatch, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+					gets++
+					if gets == 1 {
+						return errors.NewNotFound(rbacv1.Resource("roles"), key.Name)
+					}
+					return c.Get(ctx, key, obj, opts...)
+				},
+			}, tt.existing)
+			m := NewManager(c, Options{})
+
+			config := testConfig("cfg")
+			retries := 1
+			config.Spec.Config = &rbacoperatorv1.NamespaceRBACConfigConfig{MaxConflictRetries: &retries}
+
+			if err := tt.apply(m, config); err != nil {
+				t.Fatalf("createOrUpdate: %v", err)
+			}
+			tt.check(t, c)
+		})
+	}
+}
+
+func TestApplyMarksRBACAPIUnavailable(t *testing.T) {
+	roleKind := schema.GroupKind{Group: rbacv1.GroupName, Kind: "Role"}
+	tests := []struct {
+		name        string
+		err         error
+		unavailable bool
+	}{
+		{
+			name:        "no kind match",
+			err:         &meta.NoKindMatchError{GroupKind: roleKind, SearchedVersions: []string{"v1"}},
+			unavailable: true,
+		},
+		{
+			name:        "no resource match",
+			err:         &meta.NoResourceMatchError{PartialResource: rbacv1.SchemeGroupVersion.WithResource("roles")},
+			unavailable: true,
+		},
+		{
+			name: "group discovery failed",
+			err: &discovery.ErrGroupDiscoveryFailed{Groups: map[schema.GroupVersion]error{
+				rbacv1.SchemeGroupVersion: fmt.Errorf("the server could not find the requested resource"),
+			}},
+			unavailable: true,
+		},
+		{
+			name:        "other API error",
+			err:         errors.NewInternalError(fmt.Errorf("boom")),
+			unavailable: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ns := testNamespace("team-a", map[string]string{"team": "a"})
+			c := newFakeClient(t, interceptor.Funcs{
+				Get: func(ctx context.Context, c client.WithWatch, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+					if _, ok := obj.(*rbacv1.Role); ok {
+						return tt.err
+					}
+					return c.Get(ctx, key, obj, opts...)
+				},
+			}, ns)
+			m := NewManager(c, Options{})
+			config := testConfig("cfg")
+			config.Spec.RBACTemplates.Roles = []rbacoperatorv1.RoleTemplate{{Name: "viewer"}}
+
+			_, err := m.ApplyRBACForNamespace(context.Background(), ns, config, []string{ns.Name})
+			if err == nil {
+				t.Fatal("apply succeeded, want error")
+			}
+			if got := IsAPIUnavailable(err); got != tt.unavailable {
+				t.Errorf("IsAPIUnavailable(%v) = %v, want %v", err, got, tt.unavailable)
+			}
+			if tt.unavailable && !strings.Contains(err.Error(), "RBAC API unavailable") {
+				t.Errorf("error %q does not say the RBAC API is unavailable", err)
+			}
+		})
+	}
+}
+
+func TestApplyMergeStrategies(t *testing.T) {
+	oldRule := rbacv1.PolicyRule{APIGroups: []string{""}, Resources: []string{"secrets"}, Verbs: []string{"get"}}
+	newRule := rbacv1.PolicyRule{APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: []string{"get", "list"}}
+	manualSubject := rbacv1.Subject{Kind: rbacv1.UserKind, APIGroup: rbacv1.GroupName, Name: "alice"}
+	templateSubject := rbacv1.Subject{Kind: rbacv1.GroupKind, APIGroup: rbacv1.GroupName, Name: "team-a"}
+
+	tests := []struct {
+		strategy     rbacoperatorv1.MergeStrategy
+		wantRules    []rbacv1.PolicyRule
+		wantSubjects []rbacv1.Subject
+	}{
+		{
+			strategy:     rbacoperatorv1.MergeStrategyMerge,
+			wantRules:    []rbacv1.PolicyRule{oldRule, newRule},
+			wantSubjects: []rbacv1.Subject{manualSubject, templateSubject},
+		},
+		{
+			strategy:     rbacoperatorv1.MergeStrategyReplace,
+			wantRules:    []rbacv1.PolicyRule{newRule},
+			wantSubjects: []rbacv1.Subject{templateSubject},
+		},
+		{
+			strategy:     rbacoperatorv1.MergeStrategyAuthoritative,
+			wantRules:    []rbacv1.PolicyRule{newRule},
+			wantSubjects: []rbacv1.Subject{manualSubject, templateSubject},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.strategy), func(t *testing.T) {
+			ns := testNamespace("team-a", map[string]string{"team": "a"})
+			existingRole := &rbacv1.Role{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "viewer"},
+				Rules:      []rbacv1.PolicyRule{oldRule},
+			}
+			existingBinding := &rbacv1.RoleBinding{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "viewer"},
+				RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: KindRole, Name: "viewer"},
+				Subjects:   []rbacv1.Subject{manualSubject},
+			}
+			c := newFakeClient(t, interceptor.Funcs{}, ns, existingRole, existingBinding)
+			m := NewManager(c, Options{})
+
+			strategy := tt.strategy
+			config := testConfig("cfg")
+			config.Spec.Config = &rbacoperatorv1.NamespaceRBACConfigConfig{MergeStrategy: &strategy}
+			config.Spec.RBACTemplates = rbacoperatorv1.RBACTemplates{
+				Roles: []rbacoperatorv1.RoleTemplate{{Name: "viewer", Rules: []rbacv1.PolicyRule{newRule}}},
+				RoleBindings: []rbacoperatorv1.RoleBindingTemplate{{
+					Name:     "viewer",
+					RoleRef:  rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: KindRole, Name: "viewer"},
+					Subjects: []rbacv1.Subject{templateSubject},
+				}},
+			}
+
+			// A second apply must not change the outcome of the first
+			for i := 0; i < 2; i++ {
+				if _, err := m.ApplyRBACForNamespace(context.Background(), ns, config, []string{ns.Name}); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			role := &rbacv1.Role{}
+			if err := c.Get(context.Background(), types.NamespacedName{Namespace: "team-a", Name: "viewer"}, role); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(role.Rules, tt.wantRules) {
+				t.Errorf("rules = %v, want %v", role.Rules, tt.wantRules)
+			}
+			binding := &rbacv1.RoleBinding{}
+			if err := c.Get(context.Background(), types.NamespacedName{Namespace: "team-a", Name: "viewer"}, binding); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(binding.Subjects, tt.wantSubjects) {
+				t.Errorf("subjects = %v, want %v", binding.Subjects, tt.wantSubjects)
+			}
+		})
+	}
+}
+
+func TestApplyDeletesDanglingBindings(t *testing.T) {
+	ownedLabels := map[string]string{OwnerLabel: "namespace-rbac-operator", ConfigLabel: "cfg", NamespaceLabel: "team-a"}
+	subjects := []rbacv1.Subject{{Kind: rbacv1.GroupKind, APIGroup: rbacv1.GroupName, Name: "team-a"}}
+
+	tests := []struct {
+		name        string
+		enabled     bool
+		binding     client.Object
+		existing    []client.Object
+		wantDeleted bool
+	}{
+		{
+			name:    "owned binding to pruned Role",
+			enabled: true,
+			binding: &rbacv1.RoleBinding{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "editor", Labels: ownedLabels},
+				RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "Role", Name: "editor"},
+				Subjects:   subjects,
+			},
+			wantDeleted: true,
+		},
+		{
+			name:    "owned binding to pruned ClusterRole",
+			enabled: true,
+			binding: &rbacv1.ClusterRoleBinding{
+				ObjectMeta: metav1.ObjectMeta{Name: "editor-team-a", Labels: ownedLabels},
+				RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: "editor-team-a"},
+				Subjects:   subjects,
+			},
+			wantDeleted: true,
+		},
+		{
+			name:    "disabled",
+			enabled: false,
+			binding: &rbacv1.RoleBinding{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "editor", Labels: ownedLabels},
+				RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "Role", Name: "editor"},
+				Subjects:   subjects,
+			},
+		},
+		{
+			name:    "role still exists",
+			enabled: true,
+			binding: &rbacv1.RoleBinding{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "editor", Labels: ownedLabels},
+				RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "Role", Name: "editor"},
+				Subjects:   subjects,
+			},
+			existing: []client.Object{&rbacv1.Role{ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "editor"}}},
+		},
+		{
+			name:    "binding not owned by the operator",
+			enabled: true,
+			binding: &rbacv1.RoleBinding{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "editor"},
+				RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "Role", Name: "editor"},
+				Subjects:   subjects,
+			},
+		},
+		{
+			name:    "binding rendered by the current apply",
+			enabled: true,
+			binding: &rbacv1.RoleBinding{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "viewer", Labels: ownedLabels},
+				RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "Role", Name: "missing"},
+				Subjects:   subjects,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ns := testNamespace("team-a", map[string]string{"team": "a"})
+			objs := append([]client.Object{ns, tt.binding}, tt.existing...)
+			c := newFakeClient(t, interceptor.Funcs{}, objs...)
+			m := NewManager(c, Options{})
+
+			enabled := tt.enabled
+			config := testConfig("cfg")
+			config.Spec.Config = &rbacoperatorv1.NamespaceRBACConfigConfig{
+				Cleanup: &rbacoperatorv1.CleanupConfig{DeleteDanglingBindings: &enabled},
+			}
+			// The viewer binding references a Role no template produces
+			config.Spec.RBACTemplates.RoleBindings = []rbacoperatorv1.RoleBindingTemplate{{
+				Name:     "viewer",
+				RoleRef:  rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "Role", Name: "missing"},
+				Subjects: subjects,
+			}}
+
+			if _, err := m.ApplyRBACForNamespace(context.Background(), ns, config, []string{ns.Name}); err != nil {
+				t.Fatal(err)
+			}
+
+			err := c.Get(context.Background(), client.ObjectKeyFromObject(tt.binding), tt.binding)
+			if deleted := errors.IsNotFound(err); deleted != tt.wantDeleted {
+				t.Errorf("binding deleted = %v (err %v), want %v", deleted, err, tt.wantDeleted)
+			}
+		})
+	}
+}
+
+func TestApplyRendersTemplateSettings(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings map[string]string
+		template string
+		want     string
+	}{
+		{
+			name:     "setting",
+			settings: map[string]string{"domain": "example.com"},
+			template: "{{ .Namespace.Name }}.{{ .Settings.domain }}",
+			want:     "team-a.example.com",
+		},
+		{
+			name:     "missing setting with default",
+			template: `{{ getOrDefault .Settings "domain" "cluster.local" }}`,
+			want:     "cluster.local",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ns := testNamespace("team-a", map[string]string{"team": "a"})
+			c := newFakeClient(t, interceptor.Funcs{}, ns)
+			m := NewManager(c, Options{TemplateSettings: tt.settings})
+			config := testConfig("cfg")
+			config.Spec.RBACTemplates.Roles = []rbacoperatorv1.RoleTemplate{{
+				Name:        "viewer",
+				Annotations: map[string]string{"example.com/host": tt.template},
+			}}
+
+			if _, err := m.ApplyRBACForNamespace(context.Background(), ns, config, []string{ns.Name}); err != nil {
+				t.Fatal(err)
+			}
+
+			role := &rbacv1.Role{}
+			if err := c.Get(context.Background(), types.NamespacedName{Namespace: "team-a", Name: "viewer"}, role); err != nil {
+				t.Fatal(err)
+			}
+			if got := role.Annotations["example.com/host"]; got != tt.want {
+				t.Errorf("annotation = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckDuplicateNames(t *testing.T) {
+	tests := []struct {
+		name       string
+		roles      []string
+		bindings   []string
+		customVars map[string]string
+		noMatching bool
+		wantErrs   []string
+	}{
+		{
+			name:  "distinct names",
+			roles: []string{"viewer", "editor"},
+		},
+		{
+			name:     "duplicate static names",
+			roles:    []string{"viewer", "editor", "viewer"},
+			wantErrs: []string{"spec.rbacTemplates.roles[2].name"},
+		},
+		{
+			name:       "distinct templates rendering to the same name",
+			roles:      []string{"{{ .Namespace.Name }}-viewer", "{{ .CustomVars.team }}-viewer"},
+			customVars: map[string]string{"team": "team-a"},
+			wantErrs:   []string{"spec.rbacTemplates.roles[1].name"},
+		},
+		{
+			name:       "templates rendering to different names",
+			roles:      []string{"{{ .Namespace.Name }}-viewer", "{{ .CustomVars.team }}-viewer"},
+			customVars: map[string]string{"team": "platform"},
+		},
+		{
+			name:     "same name across kinds",
+			roles:    []string{"viewer"},
+			bindings: []string{"viewer"},
+		},
+		{
+			name:       "static duplicates without matching namespaces",
+			roles:      []string{"viewer", "viewer"},
+			noMatching: true,
+			wantErrs:   []string{"spec.rbacTemplates.roles[1].name"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewManager(newFakeClient(t, interceptor.Funcs{}), Options{})
+			config := testConfig("cfg")
+			config.Spec.Config = &rbacoperatorv1.NamespaceRBACConfigConfig{TemplateVariables: tt.customVars}
+			for _, name := range tt.roles {
+				config.Spec.RBACTemplates.Roles = append(config.Spec.RBACTemplates.Roles, rbacoperatorv1.RoleTemplate{Name: name})
+			}
+			for _, name := range tt.bindings {
+				config.Spec.RBACTemplates.RoleBindings = append(config.Spec.RBACTemplates.RoleBindings, rbacoperatorv1.RoleBindingTemplate{
+					Name:    name,
+					RoleRef: rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: KindRole, Name: "viewer"},
+				})
+			}
+			var matching []corev1.Namespace
+			if !tt.noMatching {
+				matching = []corev1.Namespace{*testNamespace("team-a", map[string]string{"team": "a"})}
+			}
+
+			var got []string
+			for _, err := range m.CheckDuplicateNames(config, matching) {
+				got = append(got, err.Field)
+			}
+			if !reflect.DeepEqual(got, tt.wantErrs) {
+				t.Errorf("duplicate name errors on %v, want %v", got, tt.wantErrs)
+			}
+		})
+	}
+}
+
+func TestApplyExpandsServiceAccountSelector(t *testing.T) {
+	serviceAccount := func(namespace, name string, labels map[string]string) client.Object {
+		return &corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name, Labels: labels}}
+	}
+	ci := map[string]string{"role": "ci"}
+	group := rbacv1.Subject{Kind: rbacv1.GroupKind, APIGroup: rbacv1.GroupName, Name: "team-a"}
+
+	tests := []struct {
+		name     string
+		subjects []rbacv1.Subject
+		existing []client.Object
+		want     []rbacv1.Subject
+	}{
+		{
+			name:     "two matching service accounts",
+			subjects: []rbacv1.Subject{group},
+			existing: []client.Object{
+				serviceAccount("team-a", "runner", ci),
+				serviceAccount("team-a", "deployer", ci),
+				serviceAccount("team-a", "default", nil),
+				serviceAccount("team-b", "builder", ci),
+			},
+			want: []rbacv1.Subject{
+				group,
+				{Kind: rbacv1.ServiceAccountKind, Name: "deployer", Namespace: "team-a"},
+				{Kind: rbacv1.ServiceAccountKind, Name: "runner", Namespace: "team-a"},
+			},
+		},
+		{
+			name:     "no matching service accounts",
+			subjects: []rbacv1.Subject{group},
+			existing: []client.Object{serviceAccount("team-a", "default", nil)},
+			want:     []rbacv1.Subject{group},
+		},
+		{
+			name: "only selector subjects, none matching",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ns := testNamespace("team-a", map[string]string{"team": "a"})
+			c := newFakeClient(t, interceptor.Funcs{}, append([]client.Object{ns}, tt.existing...)...)
+			m := NewManager(c, Options{})
+			config := testConfig("cfg")
+			config.Spec.RBACTemplates.RoleBindings = []rbacoperatorv1.RoleBindingTemplate{{
+				Name:                       "ci",
+				RoleRef:                    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: "edit"},
+				Subjects:                   tt.subjects,
+				FromServiceAccountSelector: ci,
+			}}
+
+			if _, err := m.ApplyRBACForNamespace(context.Background(), ns, config, []string{ns.Name}); err != nil {
+				t.Fatal(err)
+			}
+
+			binding := &rbacv1.RoleBinding{}
+			if err := c.Get(context.Background(), types.NamespacedName{Namespace: "team-a", Name: "ci"}, binding); err != nil {
+				t.Fatal(err)
+			}
+			if len(binding.Subjects) != len(tt.want) || (len(tt.want) > 0 && !reflect.DeepEqual(binding.Subjects, tt.want)) {
+				t.Errorf("subjects = %v, want %v", binding.Subjects, tt.want)
+			}
+		})
+	}
+}
+
+func TestProcessSubjectsDefaultsAPIGroup(t *testing.T) {
+	tests := []struct {
+		name    string
+		subject rbacv1.Subject
+		want    rbacv1.Subject
+	}{
+		{
+			name:    "user",
+			subject: rbacv1.Subject{Kind: rbacv1.UserKind, Name: "alice"},
+			want:    rbacv1.Subject{Kind: rbacv1.UserKind, APIGroup: rbacv1.GroupName, Name: "alice"},
+		},
+		{
+			name:    "group with templated name",
+			subject: rbacv1.Subject{Kind: rbacv1.GroupKind, Name: "{{ .Namespace.Name }}-admins"},
+			want:    rbacv1.Subject{Kind: rbacv1.GroupKind, APIGroup: rbacv1.GroupName, Name: "team-a-admins"},
+		},
+		{
+			name:    "service account",
+			subject: rbacv1.Subject{Kind: rbacv1.ServiceAccountKind, Name: "deployer", Namespace: "{{ .Namespace.Name }}"},
+			want:    rbacv1.Subject{Kind: rbacv1.ServiceAccountKind, Name: "deployer", Namespace: "team-a"},
+		},
+		{
+			name:    "explicit user group is preserved",
+			subject: rbacv1.Subject{Kind: rbacv1.UserKind, APIGroup: "example.com", Name: "alice"},
+			want:    rbacv1.Subject{Kind: rbacv1.UserKind, APIGroup: "example.com", Name: "alice"},
+		},
+		{
+			name:    "explicit service account group is preserved",
+			subject: rbacv1.Subject{Kind: rbacv1.ServiceAccountKind, APIGroup: "example.com", Name: "deployer", Namespace: "ci"},
+			want:    rbacv1.Subject{Kind: rbacv1.ServiceAccountKind, APIGroup: "example.com", Name: "deployer", Namespace: "ci"},
+		},
+	}
+
+	m := NewManager(newFakeClient(t, interceptor.Funcs{}), Options{})
+	ns := testNamespace("team-a", map[string]string{"team": "a"})
+	templateCtx := m.templateEngine.BuildContext(ns, testConfig("cfg"), []string{ns.Name})
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := m.processSubjects([]rbacv1.Subject{tt.subject}, templateCtx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != 1 || got[0] != tt.want {
+				t.Errorf("processSubjects() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyMergesCommonMetadata(t *testing.T) {
+	ns := testNamespace("team-a", map[string]string{"team": "a"})
+	c := newFakeClient(t, interceptor.Funcs{}, ns)
+	m := NewManager(c, Options{})
+	config := testConfig("cfg")
+	config.Spec.Config = &rbacoperatorv1.NamespaceRBACConfigConfig{
+		CommonLabels:      map[string]string{"team": "{{ .Namespace.Name }}", "tier": "common"},
+		CommonAnnotations: map[string]string{"example.com/owner": "platform", "example.com/note": "common"},
+	}
+	config.Spec.RBACTemplates = rbacoperatorv1.RBACTemplates{
+		ServiceAccounts: []rbacoperatorv1.ServiceAccountTemplate{{Name: "deployer"}},
+		Roles: []rbacoperatorv1.RoleTemplate{{
+			Name:        "viewer",
+			Labels:      map[string]string{"tier": "role"},
+			Annotations: map[string]string{"example.com/note": "{{ .Namespace.Name }} viewer"},
+		}},
+		RoleBindings: []rbacoperatorv1.RoleBindingTemplate{{
+			Name:     "viewer",
+			RoleRef:  rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: KindRole, Name: "viewer"},
+			Subjects: []rbacv1.Subject{{Kind: rbacv1.GroupKind, Name: "team-a"}},
+		}},
+	}
+	if _, err := m.ApplyRBACForNamespace(context.Background(), ns, config, []string{ns.Name}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name            string
+		obj             client.Object
+		wantLabels      map[string]string
+		wantAnnotations map[string]string
+	}{
+		{
+			name:            "template values take precedence",
+			obj:             &rbacv1.Role{ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "viewer"}},
+			wantLabels:      map[string]string{"team": "team-a", "tier": "role", OwnerLabel: "namespace-rbac-operator"},
+			wantAnnotations: map[string]string{"example.com/owner": "platform", "example.com/note": "team-a viewer"},
+		},
+		{
+			name:            "common values only",
+			obj:             &rbacv1.RoleBinding{ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "viewer"}},
+			wantLabels:      map[string]string{"team": "team-a", "tier": "common", OwnerLabel: "namespace-rbac-operator"},
+			wantAnnotations: map[string]string{"example.com/owner": "platform", "example.com/note": "common"},
+		},
+		{
+			name:            "service account",
+			obj:             &corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "deployer"}},
+			wantLabels:      map[string]string{"team": "team-a", "tier": "common", OwnerLabel: "namespace-rbac-operator"},
+			wantAnnotations: map[string]string{"example.com/owner": "platform", "example.com/note": "common"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := c.Get(context.Background(), client.ObjectKeyFromObject(tt.obj), tt.obj); err != nil {
+				t.Fatal(err)
+			}
+			for key, want := range tt.wantLabels {
+				if got := tt.obj.GetLabels()[key]; got != want {
+					t.Errorf("label %s = %q, want %q", key, got, want)
+				}
+			}
+			for key, want := range tt.wantAnnotations {
+				if got := tt.obj.GetAnnotations()[key]; got != want {
+					t.Errorf("annotation %s = %q, want %q", key, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestApplyExpandsSubjectsFromVar(t *testing.T) {
+	tests := []struct {
+		name    string
+		vars    map[string]string
+		want    []rbacv1.Subject
+		wantErr string
+	}{
+		{
+			name: "yaml list",
+			vars: map[string]string{"admins": "- {kind: User, name: alice}\n- {kind: User, name: bob}\n- {kind: Group, name: '{{ .Namespace.Name }}-admins'}\n"},
+			want: []rbacv1.Subject{
+				{Kind: rbacv1.UserKind, APIGroup: rbacv1.GroupName, Name: "alice"},
+				{Kind: rbacv1.UserKind, APIGroup: rbacv1.GroupName, Name: "bob"},
+				{Kind: rbacv1.GroupKind, APIGroup: rbacv1.GroupName, Name: "team-a-admins"},
+			},
+		},
+		{
+			name: "json list",
+			vars: map[string]string{"admins": `[{"kind":"User","name":"alice"},{"kind":"User","name":"bob"},{"kind":"ServiceAccount","name":"ci","namespace":"tools"}]`},
+			want: []rbacv1.Subject{
+				{Kind: rbacv1.UserKind, APIGroup: rbacv1.GroupName, Name: "alice"},
+				{Kind: rbacv1.UserKind, APIGroup: rbacv1.GroupName, Name: "bob"},
+				{Kind: rbacv1.ServiceAccountKind, Name: "ci", Namespace: "tools"},
+			},
+		},
+		{
+			name:    "undefined variable",
+			wantErr: `template variable "admins" is not defined`,
+		},
+		{
+			name:    "not a list",
+			vars:    map[string]string{"admins": "alice"},
+			wantErr: "is not a list of subjects",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ns := testNamespace("team-a", map[string]string{"team": "a"})
+			c := newFakeClient(t, interceptor.Funcs{}, ns)
+			m := NewManager(c, Options{})
+			config := testConfig("cfg")
+			config.Spec.Config = &rbacoperatorv1.NamespaceRBACConfigConfig{TemplateVariables: tt.vars}
+			config.Spec.RBACTemplates.RoleBindings = []rbacoperatorv1.RoleBindingTemplate{{
+				Name:            "admins",
+				RoleRef:         rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: "admin"},
+				SubjectsFromVar: "admins",
+			}}
+
+			_, err := m.ApplyRBACForNamespace(context.Background(), ns, config, []string{ns.Name})
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want one containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			binding := &rbacv1.RoleBinding{}
+			if err := c.Get(context.Background(), types.NamespacedName{Namespace: "team-a", Name: "admins"}, binding); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(binding.Subjects, tt.want) {
+				t.Errorf("subjects = %v, want %v", binding.Subjects, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyRendersMergeStrategyPerNamespace(t *testing.T) {
+	const strategy = `{{ if eq (index .Namespace.Labels "env") "prod" }}replace{{ else }}merge{{ end }}`
+	manualRule := rbacv1.PolicyRule{APIGroups: []string{""}, Resources: []string{"secrets"}, Verbs: []string{"get"}}
+	templateRule := rbacv1.PolicyRule{APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: []string{"get"}}
+
+	tests := []struct {
+		name      string
+		env       string
+		strategy  string
+		wantRules []rbacv1.PolicyRule
+		wantErr   string
+	}{
+		{name: "prod replaces", env: "prod", strategy: strategy, wantRules: []rbacv1.PolicyRule{templateRule}},
+		{name: "dev merges", env: "dev", strategy: strategy, wantRules: []rbacv1.PolicyRule{manualRule, templateRule}},
+		{
+			name:     "unknown rendered strategy",
+			env:      "prod",
+			strategy: `{{ index .Namespace.Labels "env" }}`,
+			wantErr:  `merge strategy "prod" rendered for namespace team-a is not one of`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ns := testNamespace("team-a", map[string]string{"team": "a", "env": tt.env})
+			existing := &rbacv1.Role{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "viewer"},
+				Rules:      []rbacv1.PolicyRule{manualRule},
+			}
+			c := newFakeClient(t, interceptor.Funcs{}, ns, existing)
+			m := NewManager(c, Options{})
+
+			mergeStrategy := rbacoperatorv1.MergeStrategy(tt.strategy)
+			config := testConfig("cfg")
+			config.Spec.Config = &rbacoperatorv1.NamespaceRBACConfigConfig{MergeStrategy: &mergeStrategy}
+			config.Spec.RBACTemplates.Roles = []rbacoperatorv1.RoleTemplate{{Name: "viewer", Rules: []rbacv1.PolicyRule{templateRule}}}
+
+			_, err := m.ApplyRBACForNamespace(context.Background(), ns, config, []string{ns.Name})
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want one containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			role := &rbacv1.Role{}
+			if err := c.Get(context.Background(), types.NamespacedName{Namespace: "team-a", Name: "viewer"}, role); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(role.Rules, tt.wantRules) {
+				t.Errorf("rules = %v, want %v", role.Rules, tt.wantRules)
+			}
+		})
+	}
+}
+
+func TestValidateTemplatesReportsUndefinedFields(t *testing.T) {
+	tests := []struct {
+		name     string
+		role     rbacoperatorv1.RoleTemplate
+		binding  rbacoperatorv1.RoleBindingTemplate
+		wantErrs []string
+	}{
+		{
+			name: "known fields",
+			role: rbacoperatorv1.RoleTemplate{Name: "{{ .Namespace.Name }}-viewer", Labels: map[string]string{"team": "{{ .CustomVars.team }}"}},
+			binding: rbacoperatorv1.RoleBindingTemplate{
+				Name:     "viewer",
+				Subjects: []rbacv1.Subject{{Kind: rbacv1.GroupKind, Name: "{{ .Namespace.Name }}-admins"}},
+			},
+		},
+		{
+			name:     "undefined field in a role name",
+			role:     rbacoperatorv1.RoleTemplate{Name: "{{ .Nonexistent.Field }}"},
+			binding:  rbacoperatorv1.RoleBindingTemplate{Name: "viewer"},
+			wantErrs: []string{"spec.rbacTemplates.roles[0].name"},
+		},
+		{
+			name: "undefined field in a label and a subject",
+			role: rbacoperatorv1.RoleTemplate{Name: "viewer", Labels: map[string]string{"team": "{{ .Team }}"}},
+			binding: rbacoperatorv1.RoleBindingTemplate{
+				Name:     "viewer",
+				Subjects: []rbacv1.Subject{{Kind: rbacv1.GroupKind, Name: "{{ .Namespace.Group }}"}},
+			},
+			wantErrs: []string{"spec.rbacTemplates.roleBindings[0].subjects[0].name", "spec.rbacTemplates.roles[0].labels[team]"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewManager(newFakeClient(t, interceptor.Funcs{}), Options{})
+			config := testConfig("cfg")
+			tt.binding.RoleRef = rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: KindRole, Name: "viewer"}
+			config.Spec.RBACTemplates.Roles = []rbacoperatorv1.RoleTemplate{tt.role}
+			config.Spec.RBACTemplates.RoleBindings = []rbacoperatorv1.RoleBindingTemplate{tt.binding}
+
+			var got []string
+			for _, err := range m.ValidateTemplates(config) {
+				got = append(got, err.Field)
+			}
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, tt.wantErrs) {
+				t.Errorf("template errors on %v, want %v", got, tt.wantErrs)
+			}
+		})
+	}
+}
+
+func TestWritesUseConfiguredFieldManager(t *testing.T) {
+	tests := []struct {
+		name         string
+		fieldManager string
+		want         string
+	}{
+		{name: "default", want: DefaultFieldManager},
+		{name: "custom", fieldManager: "team-a-rbac", want: "team-a-rbac"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Field managers seen per write verb
+			seen := map[string][]string{}
+			ns := testNamespace("team-a", map[string]string{"team": "a"})
+			c := newFakeClient(t, interceptor.Funcs{
+				Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+					seen["create"] = append(seen["create"], (&client.CreateOptions{}).ApplyOptions(opts).FieldManager)
+					return c.Create(ctx, obj, opts...)
+				},
+				Update: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.UpdateOption) error {
+					seen["update"] = append(seen["update"], (&client.UpdateOptions{}).ApplyOptions(opts).FieldManager)
+					return c.Update(ctx, obj, opts...)
+				},
+				Patch: func(ctx context.Context, c client.WithWatch, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+					seen["patch"] = append(seen["patch"], (&client.PatchOptions{}).ApplyOptions(opts).FieldManager)
+					return c.Patch(ctx, obj, patch, opts...)
+				},
+			}, ns)
+			m := NewManager(c, Options{FieldManager: tt.fieldManager})
+
+			config := cleanupTestConfig()
+			if _, err := m.ApplyRBACForNamespace(context.Background(), ns, config, []string{ns.Name}); err != nil {
+				t.Fatal(err)
+			}
+			// Changing common and namespace metadata updates every resource and patches the namespace
+			config.Spec.Config.CommonLabels = map[string]string{"tier": "gold"}
+			config.Spec.Config.NamespaceLabels = map[string]string{"rbac.example.com/managed": "true"}
+			if _, err := m.ApplyRBACForNamespace(context.Background(), ns, config, []string{ns.Name}); err != nil {
+				t.Fatal(err)
+			}
+
+			for _, verb := range []string{"create", "update", "patch"} {
+				if len(seen[verb]) == 0 {
+					t.Errorf("no %s calls recorded", verb)
+				}
+				for _, got := range seen[verb] {
+					if got != tt.want {
+						t.Errorf("%s field manager = %q, want %q", verb, got, tt.want)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestApplyFollowsApplyOrder(t *testing.T) {
+	tests := []struct {
+		name  string
+		order []string
+		want  []string
+	}{
+		{
+			name: "default order",
+			want: DefaultApplyOrder,
+		},
+		{
+			name:  "bindings first",
+			order: []string{KindClusterRoleBinding, KindRoleBinding},
+			want:  []string{KindClusterRoleBinding, KindRoleBinding, KindServiceAccount, KindRole, KindClusterRole},
+		},
+		{
+			name:  "full custom order",
+			order: []string{KindRole, KindRoleBinding, KindClusterRole, KindClusterRoleBinding, KindServiceAccount},
+			want:  []string{KindRole, KindRoleBinding, KindClusterRole, KindClusterRoleBinding, KindServiceAccount},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var created []string
+			ns := testNamespace("team-a", map[string]string{"team": "a"})
+			c := newFakeClient(t, interceptor.Funcs{
+				Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+					switch obj.(type) {
+					case *corev1.ServiceAccount:
+						created = append(created, KindServiceAccount)
+					case *rbacv1.Role:
+						created = append(created, KindRole)
+					case *rbacv1.ClusterRole:
+						created = append(created, KindClusterRole)
+					case *rbacv1.RoleBinding:
+						created = append(created, KindRoleBinding)
+					case *rbacv1.ClusterRoleBinding:
+						created = append(created, KindClusterRoleBinding)
+					}
+					return c.Create(ctx, obj, opts...)
+				},
+			}, ns)
+			m := NewManager(c, Options{})
+			config := cleanupTestConfig()
+			config.Spec.Config.ApplyOrder = tt.order
+			config.Spec.RBACTemplates.ServiceAccounts = []rbacoperatorv1.ServiceAccountTemplate{{Name: "deployer"}}
+
+			if _, err := m.ApplyRBACForNamespace(context.Background(), ns, config, []string{ns.Name}); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(created, tt.want) {
+				t.Errorf("create order = %v, want %v", created, tt.want)
+			}
+		})
+	}
+}
+
+func TestCreateOrUpdateRoleConflictRetries(t *testing.T) {
+	one, five := 1, 5
+
+	tests := []struct {
+		name        string
+		retries     *int
+		conflicts   int // Updates answered with a conflict before one succeeds
+		wantUpdates int
+		wantErr     bool
+	}{
+		{name: "single attempt fails on conflict", retries: &one, conflicts: 1, wantUpdates: 1, wantErr: true},
+		{name: "five attempts outlast a conflict", retries: &five, conflicts: 1, wantUpdates: 2},
+		{name: "default outlasts two conflicts", conflicts: 2, wantUpdates: 3},
+		{name: "default gives up after three conflicts", conflicts: 3, wantUpdates: DefaultMaxConflictRetries, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var updates int
+			existing := &rbacv1.Role{ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "viewer"}}
+			c := newFakeClient(t, interceptor.Funcs{
+				Update: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.UpdateOption) error {
+					updates++
+					if updates <= tt.conflicts {
+						return errors.NewConflict(rbacv1.Resource("roles"), obj.GetName(), fmt.Errorf("stale"))
+					}
+					return c.Update(ctx, obj, opts...)
+				},
+			}, existing)
+			m := NewManager(c, Options{})
+			config := testConfig("cfg")
+			config.Spec.Config = &rbacoperatorv1.NamespaceRBACConfigConfig{MaxConflictRetries: tt.retries}
+
+			role := &rbacv1.Role{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "viewer"},
+				Rules:      []rbacv1.PolicyRule{{APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: []string{"get"}}},
+			}
+			err := m.createOrUpdateRole(context.Background(), role, config, rbacoperatorv1.MergeStrategyReplace)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("createOrUpdateRole() error = %v, wantErr %t", err, tt.wantErr)
+			}
+			if updates != tt.wantUpdates {
+				t.Errorf("updates = %d, want %d", updates, tt.wantUpdates)
+			}
+		})
+	}
+}
+
+func TestCleanupClusterRoleIfOrphanedByScope(t *testing.T) {
+	tests := []struct {
+		name         string
+		nameTemplate string
+		perNamespace bool
+		matching     []string
+		wantDeleted  bool
+	}{
+		{
+			name:         "per-namespace role is deleted while others match",
+			nameTemplate: "viewer-{{ .Namespace.Name }}",
+			perNamespace: true,
+			matching:     []string{"team-a", "team-b"},
+			wantDeleted:  true,
+		},
+		{
+			name:         "shared role kept while another namespace matches",
+			nameTemplate: "viewer",
+			matching:     []string{"team-a", "team-b"},
+		},
+		{
+			name:         "shared role deleted once no other namespace matches",
+			nameTemplate: "viewer",
+			matching:     []string{"team-a"},
+			wantDeleted:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ns := testNamespace("team-a", map[string]string{"team": "a"})
+			m := NewManager(newFakeClient(t, interceptor.Funcs{}, ns), Options{})
+			config := cleanupTestConfig()
+			config.Spec.RBACTemplates.ClusterRoles[0].Name = tt.nameTemplate
+			config.Spec.RBACTemplates.ClusterRoles[0].PerNamespace = &tt.perNamespace
+			if _, err := m.ApplyRBACForNamespace(context.Background(), ns, config, tt.matching); err != nil {
+				t.Fatal(err)
+			}
+			name, err := m.resolveName(config, tt.nameTemplate, m.templateEngine.BuildContext(ns, config, tt.matching))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if err := m.cleanupClusterRoleIfOrphaned(context.Background(), config.Spec.RBACTemplates.ClusterRoles[0], ns.Name, config, tt.matching); err != nil {
+				t.Fatal(err)
+			}
+
+			err = m.Get(context.Background(), types.NamespacedName{Name: name}, &rbacv1.ClusterRole{})
+			if deleted := errors.IsNotFound(err); deleted != tt.wantDeleted {
+				t.Errorf("deleted = %v (err %v), want %v", deleted, err, tt.wantDeleted)
+			}
+		})
+	}
+}
+
+func TestValidateClusterRoleScopes(t *testing.T) {
+	tests := []struct {
+		name         string
+		nameTemplate string
+		perNamespace *bool
+		hashed       bool
+		wantErrs     []string
+	}{
+		{name: "scope unset", nameTemplate: "viewer"},
+		{name: "per-namespace with a namespaced name", nameTemplate: "viewer-{{ .Namespace.Name }}", perNamespace: utils.GetBoolPtr(true)},
+		{name: "per-namespace with a shared name", nameTemplate: "viewer", perNamespace: utils.GetBoolPtr(true), wantErrs: []string{"spec.rbacTemplates.clusterRoles[0].perNamespace"}},
+		{name: "per-namespace with hashed naming", nameTemplate: "viewer", perNamespace: utils.GetBoolPtr(true), hashed: true},
+		{name: "shared with a shared name", nameTemplate: "viewer", perNamespace: utils.GetBoolPtr(false)},
+		{name: "shared with a namespaced name", nameTemplate: "viewer-{{ .Namespace.Name }}", perNamespace: utils.GetBoolPtr(false), wantErrs: []string{"spec.rbacTemplates.clusterRoles[0].perNamespace"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewManager(newFakeClient(t, interceptor.Funcs{}), Options{})
+			config := testConfig("cfg")
+			if tt.hashed {
+				config.Spec.Config = &rbacoperatorv1.NamespaceRBACConfigConfig{
+					Naming: &rbacoperatorv1.NamingConfig{Strategy: rbacoperatorv1.NamingStrategyHashed},
+				}
+			}
+			config.Spec.RBACTemplates.ClusterRoles = []rbacoperatorv1.ClusterRoleTemplate{{Name: tt.nameTemplate, PerNamespace: tt.perNamespace}}
+
+			var got []string
+			for _, err := range m.ValidateClusterRoleScopes(config) {
+				got = append(got, err.Field)
+			}
+			if !reflect.DeepEqual(got, tt.wantErrs) {
+				t.Errorf("scope errors on %v, want %v", got, tt.wantErrs)
+			}
+		})
+	}
+}
+
+func TestApplyKeepsMultilineAnnotations(t *testing.T) {
+	ns := testNamespace("team-a", map[string]string{"team": "a"})
+	c := newFakeClient(t, interceptor.Funcs{}, ns)
+	m := NewManager(c, Options{})
+
+	config := testConfig("cfg")
+	config.Spec.Config = &rbacoperatorv1.NamespaceRBACConfigConfig{
+		TemplateVariables: map[string]string{"owner": "team-a", "tier": "gold"},
+	}
+	config.Spec.RBACTemplates.Roles = []rbacoperatorv1.RoleTemplate{{
+		Name: "viewer",
+		Annotations: map[string]string{
+			"example.com/policy": "# Access policy\n{{- range sortedPairs .CustomVars }}\n{{ .Key }}: {{ .Value }}\n{{- end }}\n",
+		},
+	}}
+	if _, err := m.ApplyRBACForNamespace(context.Background(), ns, config, []string{ns.Name}); err != nil {
+		t.Fatal(err)
+	}
+
+	role := &rbacv1.Role{}
+	if err := c.Get(context.Background(), types.NamespacedName{Namespace: "team-a", Name: "viewer"}, role); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := role.Annotations["example.com/policy"], "# Access policy\nowner: team-a\ntier: gold\n"; got != want {
+		t.Errorf("policy annotation = %q, want %q", got, want)
+	}
+}
+
+func TestApplySetsOwnerReferencesByStrategy(t *testing.T) {
+	namespaced := []client.Object{
+		&corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "deployer"}},
+		&rbacv1.Role{ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "viewer"}},
+		&rbacv1.RoleBinding{ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "viewer"}},
+	}
+	clusterScoped := []client.Object{
+		&rbacv1.ClusterRole{ObjectMeta: metav1.ObjectMeta{Name: "viewer-team-a"}},
+		&rbacv1.ClusterRoleBinding{ObjectMeta: metav1.ObjectMeta{Name: "viewer-team-a"}},
+	}
+
+	tests := []struct {
+		name               string
+		strategy           *rbacoperatorv1.OwnerReferenceStrategy
+		wantNamespacedKind string // Kind of the controller owner, empty for none
+		wantClusterKind    string
+	}{
+		{name: "default", wantNamespacedKind: "Namespace"},
+		{name: "namespace", strategy: ownerStrategy(rbacoperatorv1.OwnerReferenceNamespace), wantNamespacedKind: "Namespace"},
+		{name: "config", strategy: ownerStrategy(rbacoperatorv1.OwnerReferenceConfig), wantNamespacedKind: "NamespaceRBACConfig", wantClusterKind: "NamespaceRBACConfig"},
+		{name: "none", strategy: ownerStrategy(rbacoperatorv1.OwnerReferenceNone)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ns := testNamespace("team-a", map[string]string{"team": "a"})
+			ns.UID = "ns-uid"
+			c := newFakeClient(t, interceptor.Funcs{}, ns)
+			m := NewManager(c, Options{})
+			config := cleanupTestConfig()
+			config.UID = "config-uid"
+			config.Spec.Config.OwnerReferenceStrategy = tt.strategy
+			config.Spec.RBACTemplates.ServiceAccounts = []rbacoperatorv1.ServiceAccountTemplate{{Name: "deployer"}}
+			if _, err := m.ApplyRBACForNamespace(context.Background(), ns, config, []string{ns.Name}); err != nil {
+				t.Fatal(err)
+			}
+
+			check := func(objs []client.Object, wantKind string) {
+				for _, obj := range objs {
+					if err := c.Get(context.Background(), client.ObjectKeyFromObject(obj), obj); err != nil {
+						t.Fatal(err)
+					}
+					owner := metav1.GetControllerOf(obj)
+					gotKind := ""
+					if owner != nil {
+						gotKind = owner.Kind
+					}
+					if gotKind != wantKind {
+						t.Errorf("%T %s owned by %q, want %q", obj, obj.GetName(), gotKind, wantKind)
+					}
+					if len(obj.GetOwnerReferences()) > 1 {
+						t.Errorf("%T %s has %d owner references, want at most 1", obj, obj.GetName(), len(obj.GetOwnerReferences()))
+					}
+				}
+			}
+			check(namespaced, tt.wantNamespacedKind)
+			check(clusterScoped, tt.wantClusterKind)
+		})
+	}
+}
+
+// ownerStrategy returns a pointer to strategy
+func ownerStrategy(strategy rbacoperatorv1.OwnerReferenceStrategy) *rbacoperatorv1.OwnerReferenceStrategy {
+	return &strategy
+}
+
+func TestApplyWrapsForbiddenWritesAsEscalation(t *testing.T) {
+	forbidden := errors.NewForbidden(rbacv1.Resource("roles"), "viewer", fmt.Errorf("attempting to grant RBAC permissions not currently held"))
+
+	tests := []struct {
+		name       string
+		createErr  error
+		wantDenied bool
+	}{
+		{name: "forbidden", createErr: forbidden, wantDenied: true},
+		{name: "other error", createErr: errors.NewTimeoutError("slow", 1)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ns := testNamespace("team-a", map[string]string{"team": "a"})
+			c := newFakeClient(t, interceptor.Funcs{
+				Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+					if _, ok := obj.(*rbacv1.Role); ok {
+						return tt.createErr
+					}
+					return c.Create(ctx, obj, opts...)
+				},
+			}, ns)
+			m := NewManager(c, Options{})
+
+			_, err := m.ApplyRBACForNamespace(context.Background(), ns, cleanupTestConfig(), []string{ns.Name})
+			if err == nil {
+				t.Fatal("ApplyRBACForNamespace() succeeded, want an error")
+			}
+			if got := IsEscalationDenied(err); got != tt.wantDenied {
+				t.Errorf("IsEscalationDenied(%v) = %t, want %t", err, got, tt.wantDenied)
+			}
+			if tt.wantDenied && !strings.Contains(err.Error(), "Role team-a/viewer") {
+				t.Errorf("error %q does not name the rejected Role", err)
+			}
+			if !errors.IsForbidden(err) && tt.wantDenied {
+				t.Errorf("error %v no longer wraps the forbidden error", err)
+			}
+		})
+	}
+}
+
+func TestApplyLintsEmptyRenderedValues(t *testing.T) {
+	const owner = `{{ getOrDefault .Namespace.Labels "owner" "" }}`
+
+	tests := []struct {
+		name         string
+		role         rbacoperatorv1.RoleTemplate
+		wantWarnings []string
+		wantRole     string // Name of the Role expected to exist, empty for none
+	}{
+		{
+			name:     "clean",
+			role:     rbacoperatorv1.RoleTemplate{Name: "viewer", Labels: map[string]string{"tier": "gold"}},
+			wantRole: "viewer",
+		},
+		{
+			name:         "empty rendered name is skipped",
+			role:         rbacoperatorv1.RoleTemplate{Name: owner},
+			wantWarnings: []string{fmt.Sprintf("Role template %q rendered an empty name for namespace team-a, skipped", owner)},
+		},
+		{
+			name:         "empty rendered label value",
+			role:         rbacoperatorv1.RoleTemplate{Name: "viewer", Labels: map[string]string{"owner": owner}},
+			wantWarnings: []string{`Role viewer: label "owner" rendered an empty value`},
+			wantRole:     "viewer",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ns := testNamespace("team-a", map[string]string{"team": "a"})
+			c := newFakeClient(t, interceptor.Funcs{}, ns)
+			m := NewManager(c, Options{})
+			config := testConfig("cfg")
+			config.Spec.RBACTemplates.Roles = []rbacoperatorv1.RoleTemplate{tt.role}
+
+			result, err := m.ApplyRBACForNamespace(context.Background(), ns, config, []string{ns.Name})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(result.LintWarnings, tt.wantWarnings) {
+				t.Errorf("LintWarnings = %q, want %q", result.LintWarnings, tt.wantWarnings)
+			}
+
+			roles := &rbacv1.RoleList{}
+			if err := c.List(context.Background(), roles, client.InNamespace("team-a")); err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, role := range roles.Items {
+				got = append(got, role.Name)
+			}
+			var want []string
+			if tt.wantRole != "" {
+				want = []string{tt.wantRole}
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("roles = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestApplyServiceAccountTakesIAMRoleFromNamespace(t *testing.T) {
+	const roleARN = "arn:aws:iam::123456789012:role/team-a"
+
+	tests := []struct {
+		name            string
+		nsAnnotations   map[string]string
+		existing        *corev1.ServiceAccount
+		wantAnnotations map[string]string
+		wantSecrets     int
+	}{
+		{
+			name:            "annotated namespace",
+			nsAnnotations:   map[string]string{"example.com/iam-role-arn": roleARN},
+			wantAnnotations: map[string]string{"eks.amazonaws.com/role-arn": roleARN},
+		},
+		{
+			name:            "namespace without annotation",
+			wantAnnotations: map[string]string{"eks.amazonaws.com/role-arn": ""},
+		},
+		{
+			name:          "existing service account keeps its secrets and annotations",
+			nsAnnotations: map[string]string{"example.com/iam-role-arn": roleARN},
+			existing: &corev1.ServiceAccount{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace:   "team-a",
+					Name:        "deployer",
+					Annotations: map[string]string{"example.com/owner": "ops"},
+				},
+				Secrets: []corev1.ObjectReference{{Name: "deployer-token"}},
+			},
+			wantAnnotations: map[string]string{"eks.amazonaws.com/role-arn": roleARN, "example.com/owner": "ops"},
+			wantSecrets:     1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ns := testNamespace("team-a", map[string]string{"team": "a"})
+			ns.Annotations = tt.nsAnnotations
+			objs := []client.Object{ns}
+			if tt.existing != nil {
+				objs = append(objs, tt.existing)
+			}
+			c := newFakeClient(t, interceptor.Funcs{}, objs...)
+			m := NewManager(c, Options{})
+
+			config := testConfig("cfg")
+			config.Spec.RBACTemplates.ServiceAccounts = []rbacoperatorv1.ServiceAccountTemplate{{
+				Name: "deployer",
+				Annotations: map[string]string{
+					"eks.amazonaws.com/role-arn": `{{ getOrDefault .Namespace.Annotations "example.com/iam-role-arn" "" }}`,
+				},
+			}}
+
+			if _, err := m.ApplyRBACForNamespace(context.Background(), ns, config, []string{ns.Name}); err != nil {
+				t.Fatal(err)
+			}
+
+			sa := &corev1.ServiceAccount{}
+			if err := c.Get(context.Background(), types.NamespacedName{Namespace: "team-a", Name: "deployer"}, sa); err != nil {
+				t.Fatal(err)
+			}
+			for key, want := range tt.wantAnnotations {
+				if got, ok := sa.Annotations[key]; !ok || got != want {
+					t.Errorf("annotation %s = %q (present %v), want %q", key, got, ok, want)
+				}
+			}
+			if sa.Labels[ConfigLabel] != "cfg" {
+				t.Errorf("config label = %q, want cfg", sa.Labels[ConfigLabel])
+			}
+			if len(sa.Secrets) != tt.wantSecrets {
+				t.Errorf("secrets = %v, want %d", sa.Secrets, tt.wantSecrets)
+			}
+
+			if err := m.CleanupRBACForNamespace(context.Background(), ns.Name, config, nil); err != nil {
+				t.Fatal(err)
+			}
+			err := c.Get(context.Background(), types.NamespacedName{Namespace: "team-a", Name: "deployer"}, &corev1.ServiceAccount{})
+			if !errors.IsNotFound(err) {
+				t.Errorf("service account after cleanup: err = %v, want NotFound", err)
+			}
+		})
+	}
+}
+
+func TestValidateTemplatesEnforcesFunctionAllowlist(t *testing.T) {
+	tests := []struct {
+		name     string
+		allowed  []string
+		wantErrs []string
+	}{
+		{name: "no allowlist"},
+		{name: "function allowed", allowed: []string{"default", "getOrDefault"}},
+		{name: "function outside the allowlist", allowed: []string{"default"}, wantErrs: []string{"spec.rbacTemplates.roles[0].labels[team]"}},
+		{name: "empty allowlist forbids every engine function", allowed: []string{}, wantErrs: []string{"spec.rbacTemplates.roles[0].labels[team]", "spec.rbacTemplates.roles[0].name"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewManager(newFakeClient(t, interceptor.Funcs{}), Options{})
+			config := testConfig("cfg")
+			config.Spec.Config = &rbacoperatorv1.NamespaceRBACConfigConfig{AllowedTemplateFunctions: tt.allowed}
+			config.Spec.RBACTemplates.Roles = []rbacoperatorv1.RoleTemplate{{
+				Name:   `{{ default "viewer" .CustomVars.role }}`,
+				Labels: map[string]string{"team": `{{ getOrDefault .Namespace.Labels "team" "none" }}`},
+			}}
+
+			var got []string
+			for _, err := range m.ValidateTemplates(config) {
+				got = append(got, err.Field)
+			}
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, tt.wantErrs) {
+				t.Errorf("template errors on %v, want %v", got, tt.wantErrs)
+			}
+		})
+	}
+}
+
+func TestCreateOrUpdateClusterRoleSkipsUnchanged(t *testing.T) {
+	tests := []struct {
+		name        string
+		strategy    rbacoperatorv1.MergeStrategy
+		change      func(*rbacoperatorv1.NamespaceRBACConfig)
+		wantUpdates int
+		wantNoops   float64
+	}{
+		{name: "replace unchanged", strategy: rbacoperatorv1.MergeStrategyReplace, wantNoops: 1},
+		{name: "merge unchanged", strategy: rbacoperatorv1.MergeStrategyMerge, wantNoops: 1},
+		{
+			name:     "rules changed",
+			strategy: rbacoperatorv1.MergeStrategyReplace,
+			change: func(config *rbacoperatorv1.NamespaceRBACConfig) {
+				config.Spec.RBACTemplates.ClusterRoles[0].Rules[0].Verbs = []string{"get", "list"}
+			},
+			wantUpdates: 1,
+		},
+		{
+			name:     "labels changed",
+			strategy: rbacoperatorv1.MergeStrategyReplace,
+			change: func(config *rbacoperatorv1.NamespaceRBACConfig) {
+				config.Spec.RBACTemplates.ClusterRoles[0].Labels = map[string]string{"tier": "gold"}
+			},
+			wantUpdates: 1,
+		},
+		{
+			name:     "annotations changed",
+			strategy: rbacoperatorv1.MergeStrategyReplace,
+			change: func(config *rbacoperatorv1.NamespaceRBACConfig) {
+				config.Spec.RBACTemplates.ClusterRoles[0].Annotations = map[string]string{"owner": "team-a"}
+			},
+			wantUpdates: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configName := "noop-" + strings.ReplaceAll(tt.name, " ", "-")
+			updates := 0
+			ns := testNamespace("team-a", map[string]string{"team": "a"})
+			c := newFakeClient(t, interceptor.Funcs{
+				Update: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.UpdateOption) error {
+					if _, ok := obj.(*rbacv1.ClusterRole); ok {
+						updates++
+					}
+					return c.Update(ctx, obj, opts...)
+				},
+			}, ns)
+			m := NewManager(c, Options{})
+
+			config := testConfig(configName)
+			strategy := tt.strategy
+			config.Spec.Config = &rbacoperatorv1.NamespaceRBACConfigConfig{MergeStrategy: &strategy}
+			config.Spec.RBACTemplates.ClusterRoles = []rbacoperatorv1.ClusterRoleTemplate{{
+				Name:  "viewer",
+				Rules: []rbacv1.PolicyRule{{APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: []string{"get"}}},
+			}}
+			if _, err := m.ApplyRBACForNamespace(context.Background(), ns, config, []string{ns.Name}); err != nil {
+				t.Fatal(err)
+			}
+
+			if tt.change != nil {
+				tt.change(config)
+			}
+			noops := metrics.ResourceOperations.WithLabelValues(configName, "clusterrole", "noop", "success")
+			before := testutil.ToFloat64(noops)
+			if _, err := m.ApplyRBACForNamespace(context.Background(), ns, config, []string{ns.Name}); err != nil {
+				t.Fatal(err)
+			}
+
+			if updates != tt.wantUpdates {
+				t.Errorf("ClusterRole updates = %d, want %d", updates, tt.wantUpdates)
+			}
+			if got := testutil.ToFloat64(noops) - before; got != tt.wantNoops {
+				t.Errorf("noop operations = %v, want %v", got, tt.wantNoops)
+			}
+		})
+	}
+}
+
+func TestIdempotentApplyIssuesNoUpdates(t *testing.T) {
+	tests := []struct {
+		name     string
+		strategy rbacoperatorv1.MergeStrategy
+		change   func(*rbacoperatorv1.NamespaceRBACConfig)
+		want     map[string]int
+	}{
+		{name: "merge", strategy: rbacoperatorv1.MergeStrategyMerge, want: map[string]int{}},
+		{name: "replace", strategy: rbacoperatorv1.MergeStrategyReplace, want: map[string]int{}},
+		{name: "authoritative", strategy: rbacoperatorv1.MergeStrategyAuthoritative, want: map[string]int{}},
+		{
+			name:     "subjects changed",
+			strategy: rbacoperatorv1.MergeStrategyReplace,
+			change: func(config *rbacoperatorv1.NamespaceRBACConfig) {
+				subject := rbacv1.Subject{Kind: rbacv1.GroupKind, APIGroup: rbacv1.GroupName, Name: "team-b"}
+				config.Spec.RBACTemplates.RoleBindings[0].Subjects = append(config.Spec.RBACTemplates.RoleBindings[0].Subjects, subject)
+				config.Spec.RBACTemplates.ClusterRoleBindings[0].Subjects = append(config.Spec.RBACTemplates.ClusterRoleBindings[0].Subjects, subject)
+			},
+			want: map[string]int{"*v1.RoleBinding": 1, "*v1.ClusterRoleBinding": 1},
+		},
+		{
+			name:     "role rules changed",
+			strategy: rbacoperatorv1.MergeStrategyReplace,
+			change: func(config *rbacoperatorv1.NamespaceRBACConfig) {
+				config.Spec.RBACTemplates.Roles[0].Rules = []rbacv1.PolicyRule{{APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: []string{"get"}}}
+			},
+			want: map[string]int{"*v1.Role": 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ns := testNamespace("team-a", map[string]string{"team": "a"})
+			var counting bool
+			got := map[string]int{}
+			c := newFakeClient(t, interceptor.Funcs{
+				Update: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.UpdateOption) error {
+					if counting {
+						got[fmt.Sprintf("%T", obj)]++
+					}
+					return c.Update(ctx, obj, opts...)
+				},
+			}, ns)
+			m := NewManager(c, Options{})
+
+			config := cleanupTestConfig()
+			strategy := tt.strategy
+			config.Spec.Config.MergeStrategy = &strategy
+			if _, err := m.ApplyRBACForNamespace(context.Background(), ns, config, []string{ns.Name}); err != nil {
+				t.Fatal(err)
+			}
+
+			if tt.change != nil {
+				tt.change(config)
+			}
+			counting = true
+			if _, err := m.ApplyRBACForNamespace(context.Background(), ns, config, []string{ns.Name}); err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("second pass updates = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNamespaceMatchesSkipWhen(t *testing.T) {
+	const skipProd = `{{ eq (getOrDefault .Namespace.Labels "env" "") "prod" }}`
+
+	tests := []struct {
+		name     string
+		labels   map[string]string
+		skipWhen string
+		want     bool
+		wantErr  bool
+	}{
+		{name: "no skipWhen", labels: map[string]string{"team": "a", "env": "prod"}, want: true},
+		{name: "skipWhen excludes a matching namespace", labels: map[string]string{"team": "a", "env": "prod"}, skipWhen: skipProd},
+		{name: "skipWhen renders false", labels: map[string]string{"team": "a", "env": "dev"}, skipWhen: skipProd, want: true},
+		{name: "falsy output", labels: map[string]string{"team": "a"}, skipWhen: `{{ " no " }}`, want: true},
+		{name: "selector mismatch", labels: map[string]string{"team": "b", "env": "dev"}, skipWhen: skipProd},
+		{name: "invalid template", labels: map[string]string{"team": "a"}, skipWhen: `{{ .Namespace.Labels.env `, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewManager(newFakeClient(t, interceptor.Funcs{}), Options{})
+			config := testConfig("cfg")
+			config.Spec.NamespaceSelector.SkipWhen = tt.skipWhen
+			calls := metrics.TemplateFunctionCalls.WithLabelValues("getOrDefault")
+			before := testutil.ToFloat64(calls)
+
+			got, err := m.NamespaceMatches(context.Background(), testNamespace("ns", tt.labels), config)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NamespaceMatches() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("NamespaceMatches() = %v, want %v", got, tt.want)
+			}
+			// Matching is not an apply, so its renders are not counted
+			if delta := testutil.ToFloat64(calls) - before; delta != 0 {
+				t.Errorf("template function calls counted = %v, want 0", delta)
+			}
+		})
+	}
+}
+
+func TestApplySetsTemplateChecksum(t *testing.T) {
+	tests := []struct {
+		name        string
+		change      func(*rbacoperatorv1.NamespaceRBACConfig)
+		wantUpdates map[string]int
+	}{
+		{name: "unchanged template", wantUpdates: map[string]int{}},
+		{
+			name: "role template changed",
+			change: func(config *rbacoperatorv1.NamespaceRBACConfig) {
+				config.Spec.RBACTemplates.Roles[0].Rules = []rbacv1.PolicyRule{{APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: []string{"get"}}}
+			},
+			wantUpdates: map[string]int{"*v1.Role": 1},
+		},
+		{
+			name: "equivalent binding template",
+			change: func(config *rbacoperatorv1.NamespaceRBACConfig) {
+				config.Spec.RBACTemplates.RoleBindings[0].Annotations = map[string]string{}
+			},
+			wantUpdates: map[string]int{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ns := testNamespace("team-a", map[string]string{"team": "a"})
+			var counting bool
+			updates := map[string]int{}
+			c := newFakeClient(t, interceptor.Funcs{
+				Update: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.UpdateOption) error {
+					if counting {
+						updates[fmt.Sprintf("%T", obj)]++
+					}
+					return c.Update(ctx, obj, opts...)
+				},
+			}, ns)
+			m := NewManager(c, Options{})
+
+			config := cleanupTestConfig()
+			if _, err := m.ApplyRBACForNamespace(context.Background(), ns, config, []string{ns.Name}); err != nil {
+				t.Fatal(err)
+			}
+			if tt.change != nil {
+				tt.change(config)
+			}
+			counting = true
+			if _, err := m.ApplyRBACForNamespace(context.Background(), ns, config, []string{ns.Name}); err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(updates, tt.wantUpdates) {
+				t.Errorf("updates = %v, want %v", updates, tt.wantUpdates)
+			}
+
+			templates := config.Spec.RBACTemplates
+			resources := []struct {
+				obj      client.Object
+				key      types.NamespacedName
+				template interface{}
+			}{
+				{obj: &rbacv1.Role{}, key: types.NamespacedName{Namespace: ns.Name, Name: "viewer"}, template: templates.Roles[0]},
+				{obj: &rbacv1.ClusterRole{}, key: types.NamespacedName{Name: "viewer-team-a"}, template: templates.ClusterRoles[0]},
+				{obj: &rbacv1.RoleBinding{}, key: types.NamespacedName{Namespace: ns.Name, Name: "viewer"}, template: templates.RoleBindings[0]},
+				{obj: &rbacv1.ClusterRoleBinding{}, key: types.NamespacedName{Name: "viewer-team-a"}, template: templates.ClusterRoleBindings[0]},
+			}
+			for _, resource := range resources {
+				if err := c.Get(context.Background(), resource.key, resource.obj); err != nil {
+					t.Fatal(err)
+				}
+				want, err := utils.HashJSON(resource.template)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got := resource.obj.GetAnnotations()[TemplateChecksumAnnotation]; got != want {
+					t.Errorf("%T %s checksum = %q, want %q", resource.obj, resource.key.Name, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestNamespaceMatchesHasResourceQuota(t *testing.T) {
+	yes, no := true, false
+	quota := &corev1.ResourceQuota{ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "compute"}}
+	otherQuota := &corev1.ResourceQuota{ObjectMeta: metav1.ObjectMeta{Namespace: "other", Name: "compute"}}
+	listErr := fmt.Errorf("list failed")
+
+	tests := []struct {
+		name     string
+		hasQuota *bool
+		objs     []client.Object
+		listErr  error
+		want     bool
+		wantErr  bool
+	}{
+		{name: "unset without quota", want: true},
+		{name: "required and present", hasQuota: &yes, objs: []client.Object{quota}, want: true},
+		{name: "required but absent", hasQuota: &yes, objs: []client.Object{otherQuota}},
+		{name: "excluded and present", hasQuota: &no, objs: []client.Object{quota}},
+		{name: "excluded and absent", hasQuota: &no, want: true},
+		{name: "list error", hasQuota: &yes, listErr: listErr, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newFakeClient(t, interceptor.Funcs{
+				List: func(ctx context.Context, c client.WithWatch, list client.ObjectList, opts ...client.ListOption) error {
+					if tt.listErr != nil {
+						return tt.listErr
+					}
+					return c.List(ctx, list, opts...)
+				},
+			}, tt.objs...)
+			m := NewManager(c, Options{})
+			config := testConfig("cfg")
+			config.Spec.NamespaceSelector.HasResourceQuota = tt.hasQuota
+
+			got, err := m.NamespaceMatches(context.Background(), testNamespace("team-a", map[string]string{"team": "a"}), config)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NamespaceMatches() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("NamespaceMatches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSubjectMatchIgnoreCase(t *testing.T) {
+	group := func(name string) rbacv1.Subject {
+		return rbacv1.Subject{Kind: rbacv1.GroupKind, APIGroup: rbacv1.GroupName, Name: name}
+	}
+
+	tests := []struct {
+		name       string
+		ignoreCase *bool
+		want       []string
+	}{
+		{name: "unset", want: []string{"Developers", "developers"}},
+		{name: "disabled", ignoreCase: utils.GetBoolPtr(false), want: []string{"Developers", "developers"}},
+		{name: "enabled keeps existing casing", ignoreCase: utils.GetBoolPtr(true), want: []string{"Developers"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ns := testNamespace("team-a", map[string]string{"team": "a"})
+			c := newFakeClient(t, interceptor.Funcs{}, ns)
+			m := NewManager(c, Options{})
+
+			config := testConfig("cfg")
+			merge := rbacoperatorv1.MergeStrategyMerge
+			config.Spec.Config = &rbacoperatorv1.NamespaceRBACConfigConfig{MergeStrategy: &merge, SubjectMatchIgnoreCase: tt.ignoreCase}
+			config.Spec.RBACTemplates.RoleBindings = []rbacoperatorv1.RoleBindingTemplate{{
+				Name:     "viewer",
+				RoleRef:  rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: KindClusterRole, Name: "view"},
+				Subjects: []rbacv1.Subject{group("Developers")},
+			}}
+			if _, err := m.ApplyRBACForNamespace(context.Background(), ns, config, []string{ns.Name}); err != nil {
+				t.Fatal(err)
+			}
+			// The template's group changes case; merge keeps the subject already bound
+			config.Spec.RBACTemplates.RoleBindings[0].Subjects = []rbacv1.Subject{group("developers")}
+			if _, err := m.ApplyRBACForNamespace(context.Background(), ns, config, []string{ns.Name}); err != nil {
+				t.Fatal(err)
+			}
+
+			roleBinding := &rbacv1.RoleBinding{}
+			if err := c.Get(context.Background(), types.NamespacedName{Namespace: ns.Name, Name: "viewer"}, roleBinding); err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, subject := range roleBinding.Subjects {
+				got = append(got, subject.Name)
+			}
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("subjects = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMergeSubjectsPreservesOrder(t *testing.T) {
+	group := func(name string) rbacv1.Subject {
+		return rbacv1.Subject{Kind: rbacv1.GroupKind, APIGroup: rbacv1.GroupName, Name: name}
+	}
+
+	tests := []struct {
+		name       string
+		existing   []rbacv1.Subject
+		new        []rbacv1.Subject
+		ignoreCase bool
+		want       []rbacv1.Subject
+	}{
+		{
+			name:     "existing first, then new",
+			existing: []rbacv1.Subject{group("zeta"), group("alpha"), group("mu")},
+			new:      []rbacv1.Subject{group("omega"), group("beta")},
+			want:     []rbacv1.Subject{group("zeta"), group("alpha"), group("mu"), group("omega"), group("beta")},
+		},
+		{
+			name:     "duplicates keep their first position",
+			existing: []rbacv1.Subject{group("zeta"), group("alpha"), group("mu")},
+			new:      []rbacv1.Subject{group("mu"), group("beta"), group("zeta")},
+			want:     []rbacv1.Subject{group("zeta"), group("alpha"), group("mu"), group("beta")},
+		},
+		{
+			name:       "case-insensitive duplicates keep existing casing",
+			existing:   []rbacv1.Subject{group("Developers"), group("ops")},
+			new:        []rbacv1.Subject{group("developers"), group("qa")},
+			ignoreCase: true,
+			want:       []rbacv1.Subject{group("Developers"), group("ops"), group("qa")},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Map iteration order varies between runs, so repeat to catch reordering
+			for i := 0; i < 20; i++ {
+				if got := mergeSubjects(tt.existing, tt.new, tt.ignoreCase); !reflect.DeepEqual(got, tt.want) {
+					t.Fatalf("mergeSubjects() = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestProducesClusterRole(t *testing.T) {
+	const templated = `{{ .Namespace.Name }}-{{ getOrDefault .Namespace.Labels "tier" "std" }}`
+
+	tests := []struct {
+		name          string
+		templates     []string
+		namespace     *corev1.Namespace
+		clusterRole   string
+		want          bool
+		wantListCalls int
+	}{
+		{name: "literal name differs", templates: []string{"viewer"}, clusterRole: "editor"},
+		{name: "literal name matches", templates: []string{"viewer"}, clusterRole: "viewer", want: true, wantListCalls: 1},
+		{
+			name:          "literal name without matching namespace",
+			templates:     []string{"viewer"},
+			namespace:     testNamespace("team-b", map[string]string{"team": "b"}),
+			clusterRole:   "viewer",
+			wantListCalls: 1,
+		},
+		{name: "templated name matches", templates: []string{"viewer", templated}, clusterRole: "team-a-std", want: true, wantListCalls: 1},
+		{name: "templated name differs", templates: []string{templated}, clusterRole: "team-b-std", wantListCalls: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ns := tt.namespace
+			if ns == nil {
+				ns = testNamespace("team-a", map[string]string{"team": "a"})
+			}
+			listCalls := 0
+			c := newFakeClient(t, interceptor.Funcs{
+				List: func(ctx context.Context, c client.WithWatch, list client.ObjectList, opts ...client.ListOption) error {
+					if _, ok := list.(*corev1.NamespaceList); ok {
+						listCalls++
+					}
+					return c.List(ctx, list, opts...)
+				},
+			}, ns)
+			m := NewManager(c, Options{})
+
+			config := testConfig("cfg")
+			for _, name := range tt.templates {
+				config.Spec.RBACTemplates.ClusterRoles = append(config.Spec.RBACTemplates.ClusterRoles,
+					rbacoperatorv1.ClusterRoleTemplate{Name: name})
+			}
+			calls := metrics.TemplateFunctionCalls.WithLabelValues("getOrDefault")
+			before := testutil.ToFloat64(calls)
+
+			got, err := m.ProducesClusterRole(context.Background(), config, tt.clusterRole)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("ProducesClusterRole(%q) = %v, want %v", tt.clusterRole, got, tt.want)
+			}
+			if listCalls != tt.wantListCalls {
+				t.Errorf("namespace lists = %d, want %d", listCalls, tt.wantListCalls)
+			}
+			if delta := testutil.ToFloat64(calls) - before; delta != 0 {
+				t.Errorf("template function calls counted = %v, want 0", delta)
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rbac
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+
+	rbacoperatorv1 "github.com/cropalato/k8s-acl-operator/pkg/apis/rbac/v1"
+	"github.com/cropalato/k8s-acl-operator/pkg/template"
+)
+
+// nameHashLength is the number of hex characters of the SHA-256 kept in hashed names
+const nameHashLength = 16
+
+// IsKnownNamingStrategy reports whether strategy is one of the supported naming strategies
+func IsKnownNamingStrategy(strategy rbacoperatorv1.NamingStrategy) bool {
+	switch strategy {
+	case rbacoperatorv1.NamingStrategyTemplate, rbacoperatorv1.NamingStrategyHashed, rbacoperatorv1.NamingStrategyTemplateHashed:
+		return true
+	}
+	return false
+}
+
+// getNamingStrategy returns the config's naming strategy, defaulting to template
+func getNamingStrategy(config *rbacoperatorv1.NamespaceRBACConfig) rbacoperatorv1.NamingStrategy {
+	if config.Spec.Config != nil && config.Spec.Config.Naming != nil && config.Spec.Config.Naming.Strategy != "" {
+		return config.Spec.Config.Naming.Strategy
+	}
+	return rbacoperatorv1.NamingStrategyTemplate
+}
+
+// resolveName renders a resource name template and applies the config's naming
+// strategy. Empty rendered names are returned as is so callers can skip them.
+func (m *Manager) resolveName(config *rbacoperatorv1.NamespaceRBACConfig, nameTemplate string, templateCtx *template.TemplateContext) (string, error) {
+	return resolveNameWith(m.templateEngine, config, nameTemplate, templateCtx)
+}
+
+// resolveNameWith is resolveName rendering with engine, e.g. an unmetered one for validation
+func resolveNameWith(engine *template.Engine, config *rbacoperatorv1.NamespaceRBACConfig, nameTemplate string, templateCtx *template.TemplateContext) (string, error) {
+	rendered, err := engine.ProcessTemplate(nameTemplate, templateCtx)
+	if err != nil || rendered == "" {
+		return rendered, err
+	}
+
+	switch getNamingStrategy(config) {
+	case rbacoperatorv1.NamingStrategyHashed:
+		return hashedName(templateCtx.Config.Naming, templateCtx.Namespace.Name+"/"+nameTemplate), nil
+	case rbacoperatorv1.NamingStrategyTemplateHashed:
+		return hashedName(templateCtx.Config.Naming, rendered), nil
+	default:
+		return rendered, nil
+	}
+}
+
+// resolveRoleRefName renders a binding's roleRef name. References to the config's own
+// Role or ClusterRole templates go through the naming strategy so they keep pointing at
+// the generated resource; any other reference, e.g. to the built-in view ClusterRole, is
+// only rendered.
+func (m *Manager) resolveRoleRefName(config *rbacoperatorv1.NamespaceRBACConfig, roleRef rbacv1.RoleRef, templateCtx *template.TemplateContext) (string, error) {
+	if referencesOwnTemplate(config, roleRef) {
+		return m.resolveName(config, roleRef.Name, templateCtx)
+	}
+	return m.templateEngine.ProcessTemplate(roleRef.Name, templateCtx)
+}
+
+// referencesOwnTemplate reports whether roleRef's name template is identical to the name
+// template of one of the config's Roles or ClusterRoles of the referenced kind
+func referencesOwnTemplate(config *rbacoperatorv1.NamespaceRBACConfig, roleRef rbacv1.RoleRef) bool {
+	switch roleRef.Kind {
+	case KindRole:
+		for _, t := range config.Spec.RBACTemplates.Roles {
+			if t.Name == roleRef.Name {
+				return true
+			}
+		}
+	case KindClusterRole:
+		for _, t := range config.Spec.RBACTemplates.ClusterRoles {
+			if t.Name == roleRef.Name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// hashedName returns the truncated SHA-256 of input, after the naming prefix and
+// separator when a prefix is configured
+func hashedName(naming template.NamingContext, input string) string {
+	sum := sha256.Sum256([]byte(input))
+	hash := hex.EncodeToString(sum[:])[:nameHashLength]
+	if naming.Prefix == "" {
+		return hash
+	}
+	return naming.Prefix + naming.Separator + hash
+}
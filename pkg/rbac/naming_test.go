@@ -0,0 +1,128 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rbac
+
+import (
+	"context"
+	"testing"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	rbacoperatorv1 "github.com/cropalato/k8s-acl-operator/pkg/apis/rbac/v1"
+)
+
+func TestResolveNameStrategies(t *testing.T) {
+	tests := []struct {
+		name      string
+		namespace string
+		template  string
+		naming    *rbacoperatorv1.NamingConfig
+		want      string
+	}{
+		{name: "default is template", namespace: "team-a", template: "viewer-{{ .Namespace.Name }}", want: "viewer-team-a"},
+		{
+			name:      "template",
+			namespace: "team-a",
+			template:  "viewer-{{ .Namespace.Name }}",
+			naming:    &rbacoperatorv1.NamingConfig{Strategy: rbacoperatorv1.NamingStrategyTemplate},
+			want:      "viewer-team-a",
+		},
+		{
+			name:      "hashed",
+			namespace: "team-a",
+			template:  "viewer-{{ .Namespace.Name }}",
+			naming:    &rbacoperatorv1.NamingConfig{Strategy: rbacoperatorv1.NamingStrategyHashed},
+			want:      "e7a2a9e3cb4d6321",
+		},
+		{
+			name:      "hashed differs by namespace",
+			namespace: "team-b",
+			template:  "viewer-{{ .Namespace.Name }}",
+			naming:    &rbacoperatorv1.NamingConfig{Strategy: rbacoperatorv1.NamingStrategyHashed},
+			want:      "057b0fa161a8869c",
+		},
+		{
+			name:      "template-hashed",
+			namespace: "team-a",
+			template:  "viewer-{{ .Namespace.Name }}",
+			naming:    &rbacoperatorv1.NamingConfig{Strategy: rbacoperatorv1.NamingStrategyTemplateHashed},
+			want:      "34463d5cb58293f2",
+		},
+		{
+			name:      "hashed with prefix",
+			namespace: "team-a",
+			template:  "viewer-{{ .Namespace.Name }}",
+			naming:    &rbacoperatorv1.NamingConfig{Prefix: "acme", Separator: "-", Strategy: rbacoperatorv1.NamingStrategyHashed},
+			want:      "acme-e7a2a9e3cb4d6321",
+		},
+		{
+			name:      "empty rendered name is kept",
+			namespace: "team-a",
+			template:  `{{ getOrDefault .Namespace.Labels "missing" "" }}`,
+			naming:    &rbacoperatorv1.NamingConfig{Strategy: rbacoperatorv1.NamingStrategyHashed},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewManager(newFakeClient(t, interceptor.Funcs{}), Options{})
+			config := testConfig("cfg")
+			if tt.naming != nil {
+				config.Spec.Config = &rbacoperatorv1.NamespaceRBACConfigConfig{Naming: tt.naming}
+			}
+			ns := testNamespace(tt.namespace, map[string]string{"team": "a"})
+
+			// Names must be stable across renders
+			for i := 0; i < 2; i++ {
+				got, err := m.resolveName(config, tt.template, m.templateEngine.BuildContext(ns, config, nil))
+				if err != nil {
+					t.Fatalf("resolveName() error = %v", err)
+				}
+				if got != tt.want {
+					t.Errorf("resolveName() = %q, want %q", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestApplyBindsHashedRoleName(t *testing.T) {
+	ns := testNamespace("team-a", map[string]string{"team": "a"})
+	c := newFakeClient(t, interceptor.Funcs{}, ns)
+	m := NewManager(c, Options{})
+
+	config := cleanupTestConfig()
+	config.Spec.Config.Naming = &rbacoperatorv1.NamingConfig{Strategy: rbacoperatorv1.NamingStrategyHashed}
+	if _, err := m.ApplyRBACForNamespace(context.Background(), ns, config, []string{ns.Name}); err != nil {
+		t.Fatal(err)
+	}
+
+	// The Role template "viewer" hashes with its namespace
+	const roleName = "cdc795aaf5dfff8a"
+	if err := c.Get(context.Background(), types.NamespacedName{Namespace: ns.Name, Name: roleName}, &rbacv1.Role{}); err != nil {
+		t.Fatalf("hashed Role: %v", err)
+	}
+	roleBindings := &rbacv1.RoleBindingList{}
+	if err := c.List(context.Background(), roleBindings); err != nil {
+		t.Fatal(err)
+	}
+	if len(roleBindings.Items) != 1 || roleBindings.Items[0].RoleRef.Name != roleName {
+		t.Errorf("RoleBindings = %+v, want one bound to Role %s", roleBindings.Items, roleName)
+	}
+}
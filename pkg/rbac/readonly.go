@@ -0,0 +1,70 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rbac
+
+import (
+	"context"
+	"fmt"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// readOnlyClient passes reads through to the wrapped client but turns every
+// write into a log line describing the intended action
+type readOnlyClient struct {
+	client.Client
+}
+
+// Create logs the intended create and skips it
+func (c *readOnlyClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	logSkippedWrite(ctx, "create", obj)
+	return nil
+}
+
+// Update logs the intended update and skips it
+func (c *readOnlyClient) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	logSkippedWrite(ctx, "update", obj)
+	return nil
+}
+
+// Patch logs the intended patch and skips it
+func (c *readOnlyClient) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	logSkippedWrite(ctx, "patch", obj)
+	return nil
+}
+
+// Delete logs the intended delete and skips it
+func (c *readOnlyClient) Delete(ctx context.Context, obj client.Object, opts ...client.DeleteOption) error {
+	logSkippedWrite(ctx, "delete", obj)
+	return nil
+}
+
+// DeleteAllOf logs the intended bulk delete and skips it
+func (c *readOnlyClient) DeleteAllOf(ctx context.Context, obj client.Object, opts ...client.DeleteAllOfOption) error {
+	logSkippedWrite(ctx, "deleteAllOf", obj)
+	return nil
+}
+
+// logSkippedWrite records a write that read-only mode suppressed
+func logSkippedWrite(ctx context.Context, action string, obj client.Object) {
+	log.FromContext(ctx).Info("Read-only mode, skipping write",
+		"action", action,
+		"type", fmt.Sprintf("%T", obj),
+		"namespace", obj.GetNamespace(),
+		"name", obj.GetName())
+}
@@ -0,0 +1,78 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rbac
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+func TestReadOnlyPerformsNoWrites(t *testing.T) {
+	ns := testNamespace("team-a", map[string]string{"team": "a"})
+	var writes []string
+	record := func(action string, obj client.Object) {
+		writes = append(writes, fmt.Sprintf("%s %T %s", action, obj, obj.GetName()))
+	}
+	c := newFakeClient(t, interceptor.Funcs{
+		Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+			record("create", obj)
+			return c.Create(ctx, obj, opts...)
+		},
+		Update: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.UpdateOption) error {
+			record("update", obj)
+			return c.Update(ctx, obj, opts...)
+		},
+		Patch: func(ctx context.Context, c client.WithWatch, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+			record("patch", obj)
+			return c.Patch(ctx, obj, patch, opts...)
+		},
+		Delete: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.DeleteOption) error {
+			record("delete", obj)
+			return c.Delete(ctx, obj, opts...)
+		},
+	}, ns)
+	m := NewManager(c, Options{ReadOnly: true})
+
+	// A previous read-only reconcile recorded the namespace as applied, yet nothing exists
+	config := cleanupTestConfig()
+	config.Status.ObservedGeneration = config.Generation
+	config.Status.AppliedNamespaces = []string{ns.Name}
+
+	for i := 0; i < 2; i++ {
+		result, err := m.ApplyRBACForNamespace(context.Background(), ns, config, []string{ns.Name})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(result.DriftCorrected) != 0 {
+			t.Errorf("apply %d: DriftCorrected = %v, want none in read-only mode", i, result.DriftCorrected)
+		}
+	}
+	if err := m.CleanupRBACForNamespace(context.Background(), ns.Name, config, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.CleanupRBACForConfig(context.Background(), config); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(writes) != 0 {
+		t.Errorf("read-only manager wrote %v, want no writes", writes)
+	}
+}
@@ -0,0 +1,73 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rbac
+
+import (
+	"fmt"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+
+	rbacoperatorv1 "github.com/cropalato/k8s-acl-operator/pkg/apis/rbac/v1"
+)
+
+// accessVerbs maps each access level to the verbs it expands to
+var accessVerbs = map[rbacoperatorv1.AccessLevel][]string{
+	rbacoperatorv1.AccessRead:  {"get", "list", "watch"},
+	rbacoperatorv1.AccessWrite: {"get", "list", "watch", "create", "update", "patch", "delete"},
+	rbacoperatorv1.AccessAdmin: {"*"},
+}
+
+// IsKnownAccessLevel reports whether access is one of the supported SimpleRule access levels
+func IsKnownAccessLevel(access rbacoperatorv1.AccessLevel) bool {
+	_, ok := accessVerbs[access]
+	return ok
+}
+
+// ExpandSimpleRules converts SimpleRules into PolicyRules. Rules without apiGroups
+// apply to the core group.
+func ExpandSimpleRules(simple []rbacoperatorv1.SimpleRule) ([]rbacv1.PolicyRule, error) {
+	rules := make([]rbacv1.PolicyRule, 0, len(simple))
+	for i, rule := range simple {
+		verbs, ok := accessVerbs[rule.Access]
+		if !ok {
+			return nil, fmt.Errorf("simpleRules[%d]: unknown access %q: must be one of read, write, admin", i, rule.Access)
+		}
+		apiGroups := rule.APIGroups
+		if len(apiGroups) == 0 {
+			apiGroups = []string{""}
+		}
+		rules = append(rules, rbacv1.PolicyRule{
+			APIGroups: append([]string(nil), apiGroups...),
+			Resources: append([]string(nil), rule.Resources...),
+			Verbs:     append([]string(nil), verbs...),
+		})
+	}
+	return rules, nil
+}
+
+// templateRules returns a template's raw rules followed by its expanded SimpleRules,
+// in a new slice so the template itself is never modified
+func templateRules(rules []rbacv1.PolicyRule, simple []rbacoperatorv1.SimpleRule) ([]rbacv1.PolicyRule, error) {
+	if len(simple) == 0 {
+		return rules, nil
+	}
+	expanded, err := ExpandSimpleRules(simple)
+	if err != nil {
+		return nil, err
+	}
+	return append(append(make([]rbacv1.PolicyRule, 0, len(rules)+len(expanded)), rules...), expanded...), nil
+}
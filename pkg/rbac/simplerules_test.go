@@ -0,0 +1,107 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rbac
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	rbacoperatorv1 "github.com/cropalato/k8s-acl-operator/pkg/apis/rbac/v1"
+)
+
+func TestExpandSimpleRules(t *testing.T) {
+	tests := []struct {
+		name    string
+		rule    rbacoperatorv1.SimpleRule
+		want    rbacv1.PolicyRule
+		wantErr bool
+	}{
+		{
+			name: "read",
+			rule: rbacoperatorv1.SimpleRule{Resources: []string{"pods"}, Access: rbacoperatorv1.AccessRead},
+			want: rbacv1.PolicyRule{APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: []string{"get", "list", "watch"}},
+		},
+		{
+			name: "write",
+			rule: rbacoperatorv1.SimpleRule{Resources: []string{"configmaps"}, Access: rbacoperatorv1.AccessWrite},
+			want: rbacv1.PolicyRule{
+				APIGroups: []string{""},
+				Resources: []string{"configmaps"},
+				Verbs:     []string{"get", "list", "watch", "create", "update", "patch", "delete"},
+			},
+		},
+		{
+			name: "admin",
+			rule: rbacoperatorv1.SimpleRule{APIGroups: []string{"apps"}, Resources: []string{"deployments"}, Access: rbacoperatorv1.AccessAdmin},
+			want: rbacv1.PolicyRule{APIGroups: []string{"apps"}, Resources: []string{"deployments"}, Verbs: []string{"*"}},
+		},
+		{
+			name:    "unknown access",
+			rule:    rbacoperatorv1.SimpleRule{Resources: []string{"pods"}, Access: "owner"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ExpandSimpleRules([]rbacoperatorv1.SimpleRule{tt.rule})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ExpandSimpleRules() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !reflect.DeepEqual(got, []rbacv1.PolicyRule{tt.want}) {
+				t.Errorf("ExpandSimpleRules() = %+v, want %+v", got, []rbacv1.PolicyRule{tt.want})
+			}
+		})
+	}
+}
+
+func TestApplyAppendsSimpleRulesToRules(t *testing.T) {
+	ns := testNamespace("team-a", map[string]string{"team": "a"})
+	c := newFakeClient(t, interceptor.Funcs{}, ns)
+	m := NewManager(c, Options{})
+
+	raw := rbacv1.PolicyRule{APIGroups: []string{"batch"}, Resources: []string{"jobs"}, Verbs: []string{"get"}}
+	config := testConfig("cfg")
+	config.Spec.RBACTemplates.Roles = []rbacoperatorv1.RoleTemplate{{
+		Name:        "viewer",
+		Rules:       []rbacv1.PolicyRule{raw},
+		SimpleRules: []rbacoperatorv1.SimpleRule{{Resources: []string{"pods"}, Access: rbacoperatorv1.AccessRead}},
+	}}
+	if _, err := m.ApplyRBACForNamespace(context.Background(), ns, config, []string{ns.Name}); err != nil {
+		t.Fatal(err)
+	}
+
+	role := &rbacv1.Role{}
+	if err := c.Get(context.Background(), types.NamespacedName{Namespace: ns.Name, Name: "viewer"}, role); err != nil {
+		t.Fatal(err)
+	}
+	want := []rbacv1.PolicyRule{raw, {APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: []string{"get", "list", "watch"}}}
+	if !reflect.DeepEqual(role.Rules, want) {
+		t.Errorf("Role rules = %+v, want %+v", role.Rules, want)
+	}
+	if len(config.Spec.RBACTemplates.Roles[0].Rules) != 1 {
+		t.Errorf("template rules modified: %+v", config.Spec.RBACTemplates.Roles[0].Rules)
+	}
+}
@@ -22,17 +22,34 @@ limitations under the License.
 // - getOrDefault: Get map value with fallback
 // - hasKey: Check if map contains key
 // - default: Return default value for empty/nil values
+// - matchingNamespaces: Names of all namespaces currently matching the config
+// - sortedKeys: Map keys in sorted order
+// - sortedPairs: Map entries as key/value pairs sorted by key
+// - now: Current time from the engine clock
+// - date: Format a time with a Go layout, e.g. now | date "2006-01-02"
 package template
 
 import (
 	"bytes"
 	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
 	"text/template"
+	"text/template/parse"
+	"time"
 
 	rbacv1 "github.com/cropalato/k8s-acl-operator/pkg/apis/rbac/v1"
+	"github.com/cropalato/k8s-acl-operator/pkg/metrics"
+	"github.com/cropalato/k8s-acl-operator/pkg/utils"
 	corev1 "k8s.io/api/core/v1"
 )
 
+// VarAnnotationPrefix marks namespace annotations that override template variables,
+// e.g. rbac.operator.io/var-team overrides .CustomVars.team
+const VarAnnotationPrefix = "rbac.operator.io/var-"
+
 // TemplateContext provides variables available to templates
 type TemplateContext struct {
 	// Namespace provides access to the target namespace
@@ -43,6 +60,12 @@ type TemplateContext struct {
 	Config ConfigContext `json:"config"`
 	// CustomVars provides access to custom template variables
 	CustomVars map[string]string `json:"customVars"`
+	// Match provides details of how the namespace matched the selector
+	Match MatchContext `json:"match"`
+	// Settings provides operator-level values set via --template-setting flags
+	Settings map[string]string `json:"settings"`
+	// MatchingNamespaces lists all namespaces currently matching the config, sorted by name
+	MatchingNamespaces []string `json:"matchingNamespaces"`
 }
 
 // NamespaceContext provides namespace information to templates
@@ -53,6 +76,24 @@ type NamespaceContext struct {
 	Labels map[string]string `json:"labels"`
 	// Annotations on the namespace
 	Annotations map[string]string `json:"annotations"`
+	// OwnerReferences of the namespace, in metadata order; empty if it has no owners
+	OwnerReferences []OwnerRefContext `json:"ownerReferences"`
+}
+
+// OwnerRefContext provides a namespace owner reference to templates
+type OwnerRefContext struct {
+	// Name of the owning object
+	Name string `json:"name"`
+	// Kind of the owning object
+	Kind string `json:"kind"`
+}
+
+// MatchContext provides selector match details to templates
+type MatchContext struct {
+	// Groups holds the named capture groups of the selector's nameRegex
+	Groups map[string]string `json:"groups"`
+	// NamespaceCount is how many namespaces currently match the config, including this one
+	NamespaceCount int `json:"namespaceCount"`
 }
 
 // CRDContext provides NamespaceRBACConfig information to templates
@@ -79,44 +120,136 @@ type NamingContext struct {
 	Separator string `json:"separator"`
 }
 
+// KeyValue is a single map entry, as returned by the sortedPairs template function
+type KeyValue struct {
+	Key   string
+	Value string
+}
+
 // Engine handles template processing
 type Engine struct {
-	funcMap template.FuncMap
+	funcMap   template.FuncMap  // Functions used to render; instrumented unless unmetered
+	funcs     template.FuncMap  // The same functions without instrumentation
+	settings  map[string]string // Operator-level values exposed as .Settings
+	clock     func() time.Time  // Source of the now template function
+	unmetered bool              // Function calls are not counted
 }
 
-// NewEngine creates a new template engine
-func NewEngine() *Engine {
-	return &Engine{
-		funcMap: template.FuncMap{
-			// Helper functions for safe template processing
-			"default": func(defaultVal, val interface{}) interface{} {
-				if val == nil || val == "" {
-					return defaultVal
-				}
-				return val
-			},
-			"hasKey": func(m map[string]string, key string) bool {
-				if m == nil {
-					return false
-				}
-				_, exists := m[key]
-				return exists
-			},
-			"getOrDefault": func(m map[string]string, key, defaultVal string) string {
-				if m == nil {
-					return defaultVal
-				}
-				if val, exists := m[key]; exists {
-					return val
-				}
+// NewEngine creates a new template engine. settings are operator-level values
+// exposed to every template as .Settings and may be nil.
+func NewEngine(settings map[string]string) *Engine {
+	e := &Engine{
+		settings: settings,
+		clock:    time.Now,
+	}
+	e.funcs = template.FuncMap{
+		// Helper functions for safe template processing
+		"default": func(defaultVal, val interface{}) interface{} {
+			if val == nil || val == "" {
 				return defaultVal
-			},
+			}
+			return val
+		},
+		"hasKey": func(m map[string]string, key string) bool {
+			if m == nil {
+				return false
+			}
+			_, exists := m[key]
+			return exists
+		},
+		"getOrDefault": func(m map[string]string, key, defaultVal string) string {
+			if m == nil {
+				return defaultVal
+			}
+			if val, exists := m[key]; exists {
+				return val
+			}
+			return defaultVal
+		},
+		"sortedKeys":  sortedKeys,
+		"sortedPairs": sortedPairs,
+		"now": func() time.Time {
+			return e.clock()
+		},
+		"date": func(layout string, t time.Time) string {
+			return t.Format(layout)
+		},
+		// Placeholder so templates parse; ProcessTemplate binds it to the context
+		"matchingNamespaces": func() []string {
+			return nil
 		},
 	}
+	e.funcMap = instrumentFuncs(e.funcs)
+	return e
 }
 
-// BuildContext creates a template context from a namespace and config
-func (e *Engine) BuildContext(ns *corev1.Namespace, config *rbacv1.NamespaceRBACConfig) *TemplateContext {
+// Unmetered returns an engine sharing e's functions, settings and clock whose function
+// calls are not counted in rbac_operator_template_function_calls_total. Use it for
+// renders that only validate or analyse templates, so the metric reflects applies.
+func (e *Engine) Unmetered() *Engine {
+	return &Engine{
+		funcMap:   e.funcs,
+		funcs:     e.funcs,
+		settings:  e.settings,
+		clock:     e.clock,
+		unmetered: true,
+	}
+}
+
+// SetClock replaces the source of the now template function, e.g. with a fixed
+// time for deterministic output. A nil clock restores time.Now.
+func (e *Engine) SetClock(clock func() time.Time) {
+	if clock == nil {
+		clock = time.Now
+	}
+	e.clock = clock
+}
+
+// instrumentFuncs wraps each template function so calls are counted in
+// rbac_operator_template_function_calls_total
+func instrumentFuncs(funcs template.FuncMap) template.FuncMap {
+	instrumented := make(template.FuncMap, len(funcs))
+	for name, fn := range funcs {
+		instrumented[name] = instrumentFunc(name, fn)
+	}
+	return instrumented
+}
+
+// instrumentFunc returns a function with fn's signature that records a call to
+// name before delegating to fn
+func instrumentFunc(name string, fn interface{}) interface{} {
+	v := reflect.ValueOf(fn)
+	return reflect.MakeFunc(v.Type(), func(args []reflect.Value) []reflect.Value {
+		metrics.RecordTemplateFunctionCall(name)
+		if v.Type().IsVariadic() {
+			return v.CallSlice(args)
+		}
+		return v.Call(args)
+	}).Interface()
+}
+
+// sortedKeys returns the keys of m in ascending order
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// sortedPairs returns the entries of m as key/value pairs sorted by key
+func sortedPairs(m map[string]string) []KeyValue {
+	pairs := make([]KeyValue, 0, len(m))
+	for _, k := range sortedKeys(m) {
+		pairs = append(pairs, KeyValue{Key: k, Value: m[k]})
+	}
+	return pairs
+}
+
+// BuildContext creates a template context from a namespace and config.
+// matchingNamespaces is the list of namespace names currently matching the config.
+func (e *Engine) BuildContext(ns *corev1.Namespace, config *rbacv1.NamespaceRBACConfig, matchingNamespaces []string) *TemplateContext {
 	ctx := &TemplateContext{
 		Namespace: NamespaceContext{
 			Name:        ns.Name,
@@ -133,15 +266,42 @@ func (e *Engine) BuildContext(ns *corev1.Namespace, config *rbacv1.NamespaceRBAC
 			},
 		},
 		CustomVars: make(map[string]string),
+		Match: MatchContext{
+			Groups:         make(map[string]string),
+			NamespaceCount: len(matchingNamespaces),
+		},
+		Settings:           make(map[string]string),
+		MatchingNamespaces: matchingNamespaces,
+	}
+	for k, v := range e.settings {
+		ctx.Settings[k] = v
+	}
+
+	// Expose named capture groups from the name regex. An invalid regex is rejected
+	// during config validation, so errors here only leave the groups empty.
+	if regex := config.Spec.NamespaceSelector.NameRegex; regex != nil && *regex != "" {
+		if _, groups, err := utils.MatchNameRegex(*regex, ns.Name); err == nil {
+			ctx.Match.Groups = groups
+		}
 	}
 
-	// Ensure maps are not nil
+	// Ensure maps and slices are not nil
+	if ctx.MatchingNamespaces == nil {
+		ctx.MatchingNamespaces = make([]string, 0)
+	}
 	if ctx.Namespace.Labels == nil {
 		ctx.Namespace.Labels = make(map[string]string)
 	}
 	if ctx.Namespace.Annotations == nil {
 		ctx.Namespace.Annotations = make(map[string]string)
 	}
+	ctx.Namespace.OwnerReferences = make([]OwnerRefContext, 0, len(ns.OwnerReferences))
+	for _, ref := range ns.OwnerReferences {
+		ctx.Namespace.OwnerReferences = append(ctx.Namespace.OwnerReferences, OwnerRefContext{
+			Name: ref.Name,
+			Kind: ref.Kind,
+		})
+	}
 
 	// Apply configuration if provided
 	if config.Spec.Config != nil {
@@ -157,17 +317,34 @@ func (e *Engine) BuildContext(ns *corev1.Namespace, config *rbacv1.NamespaceRBAC
 			}
 		}
 
-		if config.Spec.Config.TemplateVariables != nil {
-			ctx.CustomVars = config.Spec.Config.TemplateVariables
+		for k, v := range config.Spec.Config.TemplateVariables {
+			ctx.CustomVars[k] = v
+		}
+	}
+
+	// Namespace annotations override config variables for this namespace
+	for key, value := range ns.Annotations {
+		if name := strings.TrimPrefix(key, VarAnnotationPrefix); name != key && name != "" {
+			ctx.CustomVars[name] = value
 		}
 	}
 
 	return ctx
 }
 
-// ProcessTemplate processes a template string with the given context
+// ProcessTemplate processes a template string with the given context. The output is
+// returned verbatim: newlines and surrounding whitespace are preserved, so multiline
+// values (e.g. built with range) render as written. Use {{- and -}} to trim.
 func (e *Engine) ProcessTemplate(templateStr string, ctx *TemplateContext) (string, error) {
-	tmpl, err := template.New("resource").Funcs(e.funcMap).Option("missingkey=error").Parse(templateStr)
+	bound := template.FuncMap{
+		"matchingNamespaces": func() []string {
+			return ctx.MatchingNamespaces
+		},
+	}
+	if !e.unmetered {
+		bound = instrumentFuncs(bound)
+	}
+	tmpl, err := template.New("resource").Funcs(e.funcMap).Funcs(bound).Option("missingkey=error").Parse(templateStr)
 	if err != nil {
 		return "", fmt.Errorf("failed to parse template: %w", err)
 	}
@@ -200,6 +377,140 @@ func (e *Engine) ProcessMap(templateMap map[string]string, ctx *TemplateContext)
 
 // ValidateTemplate validates a template string without executing it
 func (e *Engine) ValidateTemplate(templateStr string) error {
-	_, err := template.New("validation").Funcs(e.funcMap).Parse(templateStr)
+	_, err := template.New("validation").Funcs(e.funcs).Parse(templateStr)
 	return err
 }
+
+// ValidateTemplateFields renders templateStr against a synthetic context to catch
+// references to fields that do not exist on TemplateContext (e.g. {{ .Nonexistent.Field }}),
+// which parsing alone cannot detect. Map lookups such as labels, annotations and
+// custom variables are not checked, since their keys are only known per namespace.
+func (e *Engine) ValidateTemplateFields(templateStr string) error {
+	tmpl, err := template.New("validation").Funcs(e.funcs).Parse(templateStr)
+	if err != nil {
+		return err
+	}
+
+	// Only field errors are reported; other execution errors may stem from the
+	// sentinel values themselves and are left for apply time
+	if err := tmpl.Execute(io.Discard, sentinelContext()); err != nil && strings.Contains(err.Error(), "can't evaluate field") {
+		return err
+	}
+	return nil
+}
+
+// CheckAllowedFunctions returns an error if templateStr calls an engine function
+// (see the package doc) that is not in allowed. Go's builtin template functions such
+// as eq or printf are not restricted.
+func (e *Engine) CheckAllowedFunctions(templateStr string, allowed []string) error {
+	tmpl, err := template.New("allowlist").Funcs(e.funcs).Parse(templateStr)
+	if err != nil {
+		return err
+	}
+
+	used := make(map[string]bool)
+	for _, t := range tmpl.Templates() {
+		if t.Tree != nil {
+			collectIdentifiers(t.Tree.Root, used)
+		}
+	}
+
+	allowedSet := make(map[string]bool, len(allowed))
+	for _, name := range allowed {
+		allowedSet[name] = true
+	}
+	// Report the first disallowed function by name so the error is stable
+	disallowed := make([]string, 0)
+	for name := range used {
+		if _, isEngineFunc := e.funcs[name]; isEngineFunc && !allowedSet[name] {
+			disallowed = append(disallowed, name)
+		}
+	}
+	if len(disallowed) > 0 {
+		sort.Strings(disallowed)
+		return fmt.Errorf("template function %q is not in allowedTemplateFunctions", disallowed[0])
+	}
+	return nil
+}
+
+// collectIdentifiers records the names of all functions called in the node tree
+func collectIdentifiers(node parse.Node, used map[string]bool) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			collectIdentifiers(child, used)
+		}
+	case *parse.ActionNode:
+		collectIdentifiers(n.Pipe, used)
+	case *parse.PipeNode:
+		if n == nil {
+			return
+		}
+		for _, cmd := range n.Cmds {
+			collectIdentifiers(cmd, used)
+		}
+	case *parse.CommandNode:
+		for _, arg := range n.Args {
+			collectIdentifiers(arg, used)
+		}
+	case *parse.ChainNode:
+		collectIdentifiers(n.Node, used)
+	case *parse.IdentifierNode:
+		used[n.Ident] = true
+	case *parse.IfNode:
+		collectBranchIdentifiers(&n.BranchNode, used)
+	case *parse.RangeNode:
+		collectBranchIdentifiers(&n.BranchNode, used)
+	case *parse.WithNode:
+		collectBranchIdentifiers(&n.BranchNode, used)
+	case *parse.TemplateNode:
+		collectIdentifiers(n.Pipe, used)
+	}
+}
+
+// collectBranchIdentifiers records the functions called in an if/range/with node
+func collectBranchIdentifiers(n *parse.BranchNode, used map[string]bool) {
+	collectIdentifiers(n.Pipe, used)
+	collectIdentifiers(n.List, used)
+	collectIdentifiers(n.ElseList, used)
+}
+
+// VariesByNamespace reports whether the template renders differently for two namespaces
+// that differ only in name, i.e. whether it produces a namespace-unique value
+func (e *Engine) VariesByNamespace(templateStr string) (bool, error) {
+	e = e.Unmetered()
+	first, err := e.ProcessTemplate(templateStr, sentinelContext())
+	if err != nil {
+		return false, err
+	}
+	other := sentinelContext()
+	other.Namespace.Name = "sentinel-other"
+	second, err := e.ProcessTemplate(templateStr, other)
+	if err != nil {
+		return false, err
+	}
+	return first != second, nil
+}
+
+// sentinelContext returns a TemplateContext with placeholder values for every field
+func sentinelContext() *TemplateContext {
+	return &TemplateContext{
+		Namespace: NamespaceContext{
+			Name:            "sentinel",
+			Labels:          map[string]string{},
+			Annotations:     map[string]string{},
+			OwnerReferences: []OwnerRefContext{{Name: "sentinel", Kind: "sentinel"}},
+		},
+		CRD: CRDContext{Name: "sentinel", Namespace: "sentinel"},
+		Config: ConfigContext{
+			Naming: NamingContext{Prefix: "sentinel", Suffix: "sentinel", Separator: "-"},
+		},
+		CustomVars:         map[string]string{},
+		Match:              MatchContext{Groups: map[string]string{}, NamespaceCount: 1},
+		Settings:           map[string]string{},
+		MatchingNamespaces: []string{"sentinel"},
+	}
+}
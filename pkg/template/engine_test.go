@@ -0,0 +1,482 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package template
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	rbacv1 "github.com/cropalato/k8s-acl-operator/pkg/apis/rbac/v1"
+	"github.com/cropalato/k8s-acl-operator/pkg/metrics"
+	"github.com/cropalato/k8s-acl-operator/pkg/utils"
+)
+
+func TestTemplateFunctionCallsCountOnlyRenders(t *testing.T) {
+	const tmpl = `{{ getOrDefault .Namespace.Labels "team" "none" }}-{{ len matchingNamespaces }}`
+
+	tests := []struct {
+		name string
+		run  func(e *Engine) error
+		want float64
+	}{
+		{
+			name: "render",
+			run: func(e *Engine) error {
+				_, err := e.ProcessTemplate(tmpl, sentinelContext())
+				return err
+			},
+			want: 1,
+		},
+		{
+			name: "unmetered render",
+			run: func(e *Engine) error {
+				_, err := e.Unmetered().ProcessTemplate(tmpl, sentinelContext())
+				return err
+			},
+		},
+		{
+			name: "validate",
+			run:  func(e *Engine) error { return e.ValidateTemplate(tmpl) },
+		},
+		{
+			name: "validate fields",
+			run:  func(e *Engine) error { return e.ValidateTemplateFields(tmpl) },
+		},
+		{
+			name: "check allowed functions",
+			run: func(e *Engine) error {
+				return e.CheckAllowedFunctions(tmpl, []string{"getOrDefault", "matchingNamespaces"})
+			},
+		},
+		{
+			name: "varies by namespace",
+			run: func(e *Engine) error {
+				_, err := e.VariesByNamespace(tmpl)
+				return err
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := NewEngine(nil)
+			getOrDefault := metrics.TemplateFunctionCalls.WithLabelValues("getOrDefault")
+			matching := metrics.TemplateFunctionCalls.WithLabelValues("matchingNamespaces")
+			beforeGet, beforeMatching := testutil.ToFloat64(getOrDefault), testutil.ToFloat64(matching)
+
+			if err := tt.run(e); err != nil {
+				t.Fatal(err)
+			}
+
+			if got := testutil.ToFloat64(getOrDefault) - beforeGet; got != tt.want {
+				t.Errorf("getOrDefault calls = %v, want %v", got, tt.want)
+			}
+			if got := testutil.ToFloat64(matching) - beforeMatching; got != tt.want {
+				t.Errorf("matchingNamespaces calls = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSortedFunctionsRenderInStableOrder(t *testing.T) {
+	labels := map[string]string{}
+	for _, k := range []string{"zeta", "alpha", "mu", "beta", "omega", "gamma", "delta", "kappa"} {
+		labels[k] = k + "-value"
+	}
+
+	tests := []struct {
+		name     string
+		labels   map[string]string
+		template string
+		want     string
+	}{
+		{
+			name:     "sortedKeys",
+			labels:   labels,
+			template: `{{ range sortedKeys .Namespace.Labels }}{{ . }},{{ end }}`,
+			want:     "alpha,beta,delta,gamma,kappa,mu,omega,zeta,",
+		},
+		{
+			name:     "sortedPairs",
+			labels:   map[string]string{"tier": "gold", "owner": "team-a", "env": "prod"},
+			template: `{{ range sortedPairs .Namespace.Labels }}{{ .Key }}={{ .Value }};{{ end }}`,
+			want:     "env=prod;owner=team-a;tier=gold;",
+		},
+		{
+			name:     "nil map",
+			template: `[{{ range sortedPairs .Namespace.Labels }}{{ .Key }}{{ end }}{{ len (sortedKeys .Namespace.Labels) }}]`,
+			want:     "[0]",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := NewEngine(nil)
+			ctx := sentinelContext()
+			ctx.Namespace.Labels = tt.labels
+			// Map iteration order is randomized, so repeat to catch any dependence on it
+			for i := 0; i < 20; i++ {
+				got, err := e.ProcessTemplate(tt.template, ctx)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got != tt.want {
+					t.Fatalf("run %d rendered %q, want %q", i, got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestBuildContextExposesCaptureGroups(t *testing.T) {
+	tests := []struct {
+		name      string
+		nameRegex *string
+		namespace string
+		template  string
+		want      string
+	}{
+		{
+			name:      "named groups",
+			nameRegex: utils.GetStringPtr(`^team-(?P<team>.+)-(?P<env>[^-]+)$`),
+			namespace: "team-payments-prod",
+			template:  "{{ .Match.Groups.team }}-{{ .Match.Groups.env }}-viewer",
+			want:      "payments-prod-viewer",
+		},
+		{
+			name:      "group used with a default",
+			nameRegex: utils.GetStringPtr(`^team-(?P<team>[^-]+)`),
+			namespace: "team-search",
+			template:  `{{ getOrDefault .Match.Groups "env" "dev" }}`,
+			want:      "dev",
+		},
+		{
+			name:      "no name regex",
+			namespace: "team-search",
+			template:  "{{ len .Match.Groups }}",
+			want:      "0",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := NewEngine(nil)
+			ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: tt.namespace}}
+			config := &rbacv1.NamespaceRBACConfig{
+				ObjectMeta: metav1.ObjectMeta{Name: "cfg"},
+				Spec: rbacv1.NamespaceRBACConfigSpec{
+					NamespaceSelector: rbacv1.NamespaceSelector{NameRegex: tt.nameRegex},
+				},
+			}
+
+			got, err := e.ProcessTemplate(tt.template, e.BuildContext(ns, config, []string{tt.namespace}))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("rendered %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateTemplateFields(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		wantErr  bool
+	}{
+		{name: "namespace name", template: "{{ .Namespace.Name }}-viewer"},
+		{name: "label lookup", template: `{{ index .Namespace.Labels "team" }}`},
+		{name: "custom variable", template: "{{ .CustomVars.owner }}"},
+		{name: "naming config", template: "{{ .Config.Naming.Prefix }}{{ .Config.Naming.Separator }}viewer"},
+		{name: "plain string", template: "viewer"},
+		{name: "nonexistent top-level field", template: "{{ .Nonexistent.Field }}", wantErr: true},
+		{name: "nonexistent nested field", template: "{{ .Namespace.Owner }}", wantErr: true},
+		{name: "parse error", template: "{{ .Namespace.Name", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := NewEngine(nil).ValidateTemplateFields(tt.template)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateTemplateFields(%q) error = %v, wantErr %t", tt.template, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestBuildContextExposesOwnerReferences(t *testing.T) {
+	owners := []metav1.OwnerReference{
+		{APIVersion: "tenancy.example.com/v1", Kind: "Tenant", Name: "payments", UID: "1"},
+		{APIVersion: "tenancy.example.com/v1", Kind: "Project", Name: "checkout", UID: "2"},
+	}
+
+	tests := []struct {
+		name     string
+		owners   []metav1.OwnerReference
+		template string
+		want     string
+		wantErr  bool
+	}{
+		{
+			name:     "first owner",
+			owners:   owners,
+			template: "{{ (index .Namespace.OwnerReferences 0).Name }}-viewer",
+			want:     "payments-viewer",
+		},
+		{
+			name:     "all owners in order",
+			owners:   owners,
+			template: "{{ range .Namespace.OwnerReferences }}{{ .Kind }}/{{ .Name }};{{ end }}",
+			want:     "Tenant/payments;Project/checkout;",
+		},
+		{
+			name:     "no owners with a guard",
+			template: "{{ with .Namespace.OwnerReferences }}{{ (index . 0).Name }}{{ else }}unowned{{ end }}",
+			want:     "unowned",
+		},
+		{
+			name:     "no owners",
+			template: "{{ len .Namespace.OwnerReferences }}",
+			want:     "0",
+		},
+		{
+			name:     "index without owners",
+			template: "{{ (index .Namespace.OwnerReferences 0).Name }}",
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := NewEngine(nil)
+			ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-a", OwnerReferences: tt.owners}}
+			config := &rbacv1.NamespaceRBACConfig{ObjectMeta: metav1.ObjectMeta{Name: "cfg"}}
+
+			got, err := e.ProcessTemplate(tt.template, e.BuildContext(ns, config, []string{ns.Name}))
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("rendered %q, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("rendered %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildContextAnnotationVarOverrides(t *testing.T) {
+	tests := []struct {
+		name        string
+		configVars  map[string]string
+		annotations map[string]string
+		want        map[string]string
+	}{
+		{
+			name:       "config defaults",
+			configVars: map[string]string{"team": "platform", "tier": "gold"},
+			want:       map[string]string{"team": "platform", "tier": "gold"},
+		},
+		{
+			name:        "annotation overrides config",
+			configVars:  map[string]string{"team": "platform", "tier": "gold"},
+			annotations: map[string]string{VarAnnotationPrefix + "team": "backend"},
+			want:        map[string]string{"team": "backend", "tier": "gold"},
+		},
+		{
+			name:        "annotation adds a variable",
+			annotations: map[string]string{VarAnnotationPrefix + "owner": "alice"},
+			want:        map[string]string{"owner": "alice"},
+		},
+		{
+			name:       "unrelated and empty-named annotations are ignored",
+			configVars: map[string]string{"team": "platform"},
+			annotations: map[string]string{
+				"example.com/team":  "ignored",
+				VarAnnotationPrefix: "ignored",
+			},
+			want: map[string]string{"team": "platform"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := NewEngine(nil)
+			ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-a", Annotations: tt.annotations}}
+			config := &rbacv1.NamespaceRBACConfig{
+				ObjectMeta: metav1.ObjectMeta{Name: "cfg"},
+				Spec: rbacv1.NamespaceRBACConfigSpec{
+					Config: &rbacv1.NamespaceRBACConfigConfig{TemplateVariables: tt.configVars},
+				},
+			}
+
+			got := e.BuildContext(ns, config, []string{ns.Name}).CustomVars
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("CustomVars = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProcessMapRendersMultilineValues(t *testing.T) {
+	e := NewEngine(nil)
+	ctx := sentinelContext()
+	ctx.CustomVars = map[string]string{"owner": "team-a", "tier": "gold"}
+
+	tests := []struct {
+		name     string
+		template string
+		want     string
+	}{
+		{
+			name:     "range with trimmed newlines",
+			template: "# Access policy\n{{- range sortedPairs .CustomVars }}\n{{ .Key }}: {{ .Value }}\n{{- end }}",
+			want:     "# Access policy\nowner: team-a\ntier: gold",
+		},
+		{
+			name:     "range keeps untrimmed whitespace",
+			template: "{{ range sortedKeys .CustomVars }}  - {{ . }}\n{{ end }}",
+			want:     "  - owner\n  - tier\n",
+		},
+		{
+			name:     "leading and trailing whitespace",
+			template: "\n  indented\n\n",
+			want:     "\n  indented\n\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := e.ProcessMap(map[string]string{"example.com/policy": tt.template}, ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got["example.com/policy"] != tt.want {
+				t.Errorf("rendered %q, want %q", got["example.com/policy"], tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckAllowedFunctions(t *testing.T) {
+	allowed := []string{"default"}
+
+	tests := []struct {
+		name     string
+		template string
+		wantErr  string // Substring of the expected error, empty for none
+	}{
+		{name: "allowed function", template: `{{ default "viewer" .CustomVars.role }}`},
+		{name: "allowed function in a pipeline", template: `{{ .CustomVars.role | default "viewer" }}`},
+		{name: "builtin functions are not restricted", template: `{{ if eq .Namespace.Name "prod" }}{{ printf "%s-admin" .Namespace.Name }}{{ end }}`},
+		{name: "no functions", template: "{{ .Namespace.Name }}-viewer"},
+		{name: "engine function outside the list", template: `{{ getOrDefault .Namespace.Labels "team" "none" }}`, wantErr: `"getOrDefault" is not in allowedTemplateFunctions`},
+		{name: "disallowed function inside a branch", template: `{{ with .Namespace.Labels }}{{ range sortedKeys . }}{{ . }}{{ end }}{{ end }}`, wantErr: `"sortedKeys"`},
+		{name: "first disallowed function by name", template: `{{ now | date "2006" }}`, wantErr: `"date"`},
+		{name: "unknown function", template: `{{ regexReplace "a" "b" .Namespace.Name }}`, wantErr: `"regexReplace" not defined`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := NewEngine(nil).CheckAllowedFunctions(tt.template, allowed)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("CheckAllowedFunctions(%q) = %v, want nil", tt.template, err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("CheckAllowedFunctions(%q) = %v, want error containing %q", tt.template, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNowAndDateUseEngineClock(t *testing.T) {
+	fixed := time.Date(2024, time.March, 5, 14, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		template  string
+		clock     func() time.Time
+		unmetered bool
+		want      string
+	}{
+		{name: "date layout", template: `{{ now | date "2006-01-02" }}`, clock: func() time.Time { return fixed }, want: "2024-03-05"},
+		{name: "time layout", template: `{{ date "15:04" now }}`, clock: func() time.Time { return fixed }, want: "14:30"},
+		{name: "unmetered engine shares clock", template: `{{ now | date "2006" }}`, clock: func() time.Time { return fixed }, unmetered: true, want: "2024"},
+		{name: "nil clock restores time.Now", template: `{{ now | date "2006" }}`, want: time.Now().Format("2006")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := NewEngine(nil)
+			e.SetClock(tt.clock)
+			if tt.unmetered {
+				e = e.Unmetered()
+			}
+
+			got, err := e.ProcessTemplate(tt.template, sentinelContext())
+			if err != nil {
+				t.Fatalf("ProcessTemplate() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("ProcessTemplate() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildContextExposesNamespaceCount(t *testing.T) {
+	tests := []struct {
+		name     string
+		matching []string
+		want     string
+	}{
+		{name: "single namespace", matching: []string{"team-a"}, want: "shared by 1 namespaces"},
+		{name: "two namespaces", matching: []string{"team-a", "team-b"}, want: "shared by 2 namespaces"},
+		{name: "no matching set", want: "shared by 0 namespaces"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := NewEngine(nil)
+			ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-a"}}
+			config := &rbacv1.NamespaceRBACConfig{ObjectMeta: metav1.ObjectMeta{Name: "cfg"}}
+
+			got, err := e.ProcessTemplate("shared by {{ .Match.NamespaceCount }} namespaces", e.BuildContext(ns, config, tt.matching))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("rendered %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
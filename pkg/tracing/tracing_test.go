@@ -0,0 +1,60 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+)
+
+func TestSetup(t *testing.T) {
+	tests := []struct {
+		name         string
+		endpoint     string
+		wantErr      bool
+		wantExporter bool
+	}{
+		{name: "unset", endpoint: ""},
+		{name: "host and port", endpoint: "otel-collector:4318", wantExporter: true},
+		{name: "http URL with path", endpoint: "http://otel-collector:4318/custom/traces", wantExporter: true},
+		{name: "https URL", endpoint: "https://otel.example.com", wantExporter: true},
+		{name: "unsupported scheme", endpoint: "grpc://otel-collector:4317", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			previous := otel.GetTracerProvider()
+			defer otel.SetTracerProvider(previous)
+
+			shutdown, err := Setup(context.Background(), tt.endpoint)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Setup() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if replaced := otel.GetTracerProvider() != previous; replaced != tt.wantExporter {
+				t.Errorf("tracer provider replaced = %v, want %v", replaced, tt.wantExporter)
+			}
+			if err := shutdown(context.Background()); err != nil {
+				t.Errorf("shutdown: %v", err)
+			}
+		})
+	}
+}
@@ -20,6 +20,10 @@ limitations under the License.
 package utils
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"path"
 	"regexp"
 
 	rbacoperatorv1 "github.com/cropalato/k8s-acl-operator/pkg/apis/rbac/v1"
@@ -29,10 +33,11 @@ import (
 // NamespaceMatches determines if a namespace matches the given selector criteria.
 // It evaluates multiple criteria using AND logic (all must pass):
 // 1. Exclusion list (takes precedence - if namespace is excluded, returns false)
-// 2. Inclusion list (if specified, namespace must be in the list)
+// 2. Inclusion list and globs (if specified, namespace must be listed or match a glob)
 // 3. Name regex pattern (namespace name must match regex)
 // 4. Required annotations (all specified annotations must exist with exact values)
-// 5. Required labels (all specified labels must exist with exact values)
+// 5. Annotation presence (keys that must exist with any value, or must be absent)
+// 6. Required labels (all specified labels must exist with exact values)
 //
 // Returns true only if ALL applicable criteria pass.
 func NamespaceMatches(ns *corev1.Namespace, selector rbacoperatorv1.NamespaceSelector) (bool, error) {
@@ -42,9 +47,18 @@ func NamespaceMatches(ns *corev1.Namespace, selector rbacoperatorv1.NamespaceSel
 			return false, nil
 		}
 	}
+	for _, pattern := range selector.ExcludeNameRegex {
+		excluded, err := regexp.MatchString(pattern, ns.Name)
+		if err != nil {
+			return false, err
+		}
+		if excluded {
+			return false, nil
+		}
+	}
 
-	// If include list is specified, namespace must be in it
-	if len(selector.IncludeNamespaces) > 0 {
+	// If include list or globs are specified, namespace must be in or match one of them
+	if len(selector.IncludeNamespaces) > 0 || len(selector.IncludeNamespaceGlobs) > 0 {
 		found := false
 		for _, included := range selector.IncludeNamespaces {
 			if ns.Name == included {
@@ -52,6 +66,16 @@ func NamespaceMatches(ns *corev1.Namespace, selector rbacoperatorv1.NamespaceSel
 				break
 			}
 		}
+		for _, pattern := range selector.IncludeNamespaceGlobs {
+			if found {
+				break
+			}
+			matched, err := path.Match(pattern, ns.Name)
+			if err != nil {
+				return false, err
+			}
+			found = matched
+		}
 		if !found {
 			return false, nil
 		}
@@ -59,7 +83,7 @@ func NamespaceMatches(ns *corev1.Namespace, selector rbacoperatorv1.NamespaceSel
 
 	// Check name regex
 	if selector.NameRegex != nil && *selector.NameRegex != "" {
-		matched, err := regexp.MatchString(*selector.NameRegex, ns.Name)
+		matched, _, err := MatchNameRegex(*selector.NameRegex, ns.Name)
 		if err != nil {
 			return false, err
 		}
@@ -80,6 +104,18 @@ func NamespaceMatches(ns *corev1.Namespace, selector rbacoperatorv1.NamespaceSel
 		}
 	}
 
+	// Check annotation presence/absence
+	for _, key := range selector.AnnotationExists {
+		if _, exists := ns.Annotations[key]; !exists {
+			return false, nil
+		}
+	}
+	for _, key := range selector.AnnotationNotExists {
+		if _, exists := ns.Annotations[key]; exists {
+			return false, nil
+		}
+	}
+
 	// Check required labels
 	if selector.Labels != nil {
 		if ns.Labels == nil {
@@ -95,6 +131,40 @@ func NamespaceMatches(ns *corev1.Namespace, selector rbacoperatorv1.NamespaceSel
 	return true, nil
 }
 
+// MatchNameRegex matches a namespace name against a regex pattern and returns the
+// values of the pattern's named capture groups (e.g. (?P<team>...)).
+// The groups map is empty when the name does not match or the pattern has no named groups.
+func MatchNameRegex(pattern, name string) (bool, map[string]string, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return false, nil, err
+	}
+
+	groups := make(map[string]string)
+	submatches := re.FindStringSubmatch(name)
+	if submatches == nil {
+		return false, groups, nil
+	}
+	for i, groupName := range re.SubexpNames() {
+		if i > 0 && groupName != "" {
+			groups[groupName] = submatches[i]
+		}
+	}
+
+	return true, groups, nil
+}
+
+// HashJSON returns the hex-encoded SHA-256 of the JSON encoding of v.
+// encoding/json sorts map keys, so the hash is stable for equal values.
+func HashJSON(v interface{}) (string, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
 // GetStringPtr returns a pointer to the given string
 func GetStringPtr(s string) *string {
 	return &s
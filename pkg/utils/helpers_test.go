@@ -0,0 +1,318 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	rbacoperatorv1 "github.com/cropalato/k8s-acl-operator/pkg/apis/rbac/v1"
+)
+
+func TestNamespaceMatchesAnnotationPresence(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		selector    rbacoperatorv1.NamespaceSelector
+		want        bool
+	}{
+		{
+			name:        "exists with any value",
+			annotations: map[string]string{"owner": "team-a"},
+			selector:    rbacoperatorv1.NamespaceSelector{AnnotationExists: []string{"owner"}},
+			want:        true,
+		},
+		{
+			name:        "exists with empty value",
+			annotations: map[string]string{"owner": ""},
+			selector:    rbacoperatorv1.NamespaceSelector{AnnotationExists: []string{"owner"}},
+			want:        true,
+		},
+		{
+			name:     "exists but no annotations",
+			selector: rbacoperatorv1.NamespaceSelector{AnnotationExists: []string{"owner"}},
+			want:     false,
+		},
+		{
+			name:        "exists requires every key",
+			annotations: map[string]string{"owner": "team-a"},
+			selector:    rbacoperatorv1.NamespaceSelector{AnnotationExists: []string{"owner", "cost-center"}},
+			want:        false,
+		},
+		{
+			name:     "not exists with no annotations",
+			selector: rbacoperatorv1.NamespaceSelector{AnnotationNotExists: []string{"legacy"}},
+			want:     true,
+		},
+		{
+			name:        "not exists but present",
+			annotations: map[string]string{"legacy": "true"},
+			selector:    rbacoperatorv1.NamespaceSelector{AnnotationNotExists: []string{"legacy"}},
+			want:        false,
+		},
+		{
+			name:        "combined with exact value",
+			annotations: map[string]string{"env": "prod", "owner": "team-a"},
+			selector: rbacoperatorv1.NamespaceSelector{
+				Annotations:         map[string]string{"env": "prod"},
+				AnnotationExists:    []string{"owner"},
+				AnnotationNotExists: []string{"legacy"},
+			},
+			want: true,
+		},
+		{
+			name:        "combined with wrong exact value",
+			annotations: map[string]string{"env": "dev", "owner": "team-a"},
+			selector: rbacoperatorv1.NamespaceSelector{
+				Annotations:      map[string]string{"env": "prod"},
+				AnnotationExists: []string{"owner"},
+			},
+			want: false,
+		},
+		{
+			name:        "combined with forbidden key present",
+			annotations: map[string]string{"env": "prod", "owner": "team-a", "legacy": "true"},
+			selector: rbacoperatorv1.NamespaceSelector{
+				Annotations:         map[string]string{"env": "prod"},
+				AnnotationNotExists: []string{"legacy"},
+			},
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-a", Annotations: tt.annotations}}
+			got, err := NamespaceMatches(ns, tt.selector)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("NamespaceMatches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchNameRegex(t *testing.T) {
+	tests := []struct {
+		name        string
+		pattern     string
+		namespace   string
+		wantMatched bool
+		wantGroups  map[string]string
+		wantErr     bool
+	}{
+		{
+			name:        "named groups",
+			pattern:     `^team-(?P<team>.+)-(?P<env>[^-]+)$`,
+			namespace:   "team-payments-api-prod",
+			wantMatched: true,
+			wantGroups:  map[string]string{"team": "payments-api", "env": "prod"},
+		},
+		{
+			name:        "unnamed groups are skipped",
+			pattern:     `^(team)-(?P<env>.+)$`,
+			namespace:   "team-dev",
+			wantMatched: true,
+			wantGroups:  map[string]string{"env": "dev"},
+		},
+		{
+			name:        "no match",
+			pattern:     `^team-(?P<team>.+)$`,
+			namespace:   "kube-system",
+			wantMatched: false,
+			wantGroups:  map[string]string{},
+		},
+		{
+			name:    "invalid pattern",
+			pattern: `^team-(?P<team>.+$`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matched, groups, err := MatchNameRegex(tt.pattern, tt.namespace)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("MatchNameRegex() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if matched != tt.wantMatched {
+				t.Errorf("matched = %v, want %v", matched, tt.wantMatched)
+			}
+			if !reflect.DeepEqual(groups, tt.wantGroups) {
+				t.Errorf("groups = %v, want %v", groups, tt.wantGroups)
+			}
+		})
+	}
+}
+
+func TestHashJSONIsStable(t *testing.T) {
+	a := map[string]string{"team": "a", "env": "prod", "tier": "gold"}
+	b := map[string]string{"tier": "gold", "team": "a", "env": "prod"}
+
+	first, err := HashJSON(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 10; i++ {
+		got, err := HashJSON(b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != first {
+			t.Fatalf("hash of equal maps differs: %s != %s", got, first)
+		}
+	}
+
+	b["env"] = "dev"
+	if changed, _ := HashJSON(b); changed == first {
+		t.Error("hash did not change with the value")
+	}
+}
+
+func TestNamespaceMatchesExcludeNameRegex(t *testing.T) {
+	tests := []struct {
+		name      string
+		namespace string
+		selector  rbacoperatorv1.NamespaceSelector
+		want      bool
+		wantErr   bool
+	}{
+		{
+			name:      "included and not excluded",
+			namespace: "team-a",
+			selector:  rbacoperatorv1.NamespaceSelector{NameRegex: GetStringPtr("^team-"), ExcludeNameRegex: []string{"^temp-"}},
+			want:      true,
+		},
+		{
+			name:      "exclusion takes precedence over inclusion",
+			namespace: "team-a-temp",
+			selector:  rbacoperatorv1.NamespaceSelector{NameRegex: GetStringPtr("^team-"), ExcludeNameRegex: []string{"-temp$"}},
+			want:      false,
+		},
+		{
+			name:      "any exclusion pattern excludes",
+			namespace: "temp-42",
+			selector:  rbacoperatorv1.NamespaceSelector{ExcludeNameRegex: []string{"^scratch-", "^temp-"}},
+			want:      false,
+		},
+		{
+			name:      "exclusion beats explicit inclusion",
+			namespace: "temp-42",
+			selector:  rbacoperatorv1.NamespaceSelector{IncludeNamespaces: []string{"temp-42"}, ExcludeNameRegex: []string{"^temp-"}},
+			want:      false,
+		},
+		{
+			name:      "invalid exclusion pattern",
+			namespace: "team-a",
+			selector:  rbacoperatorv1.NamespaceSelector{ExcludeNameRegex: []string{"^temp-("}},
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: tt.namespace}}
+			got, err := NamespaceMatches(ns, tt.selector)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NamespaceMatches() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("NamespaceMatches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNamespaceMatchesIncludeNamespaceGlobs(t *testing.T) {
+	tests := []struct {
+		name      string
+		namespace string
+		selector  rbacoperatorv1.NamespaceSelector
+		want      bool
+		wantErr   bool
+	}{
+		{
+			name:      "star wildcard",
+			namespace: "team-a-dev",
+			selector:  rbacoperatorv1.NamespaceSelector{IncludeNamespaceGlobs: []string{"team-a-*"}},
+			want:      true,
+		},
+		{
+			name:      "star does not match other prefixes",
+			namespace: "team-b-dev",
+			selector:  rbacoperatorv1.NamespaceSelector{IncludeNamespaceGlobs: []string{"team-a-*"}},
+			want:      false,
+		},
+		{
+			name:      "question mark matches one character",
+			namespace: "team-1",
+			selector:  rbacoperatorv1.NamespaceSelector{IncludeNamespaceGlobs: []string{"team-?"}},
+			want:      true,
+		},
+		{
+			name:      "question mark does not match two characters",
+			namespace: "team-12",
+			selector:  rbacoperatorv1.NamespaceSelector{IncludeNamespaceGlobs: []string{"team-?"}},
+			want:      false,
+		},
+		{
+			name:      "any of several globs",
+			namespace: "ops-prod",
+			selector:  rbacoperatorv1.NamespaceSelector{IncludeNamespaceGlobs: []string{"team-*", "ops-*"}},
+			want:      true,
+		},
+		{
+			name:      "exact names and globs combine",
+			namespace: "shared",
+			selector:  rbacoperatorv1.NamespaceSelector{IncludeNamespaces: []string{"shared"}, IncludeNamespaceGlobs: []string{"team-*"}},
+			want:      true,
+		},
+		{
+			name:      "glob inclusion still requires labels",
+			namespace: "team-a-dev",
+			selector:  rbacoperatorv1.NamespaceSelector{IncludeNamespaceGlobs: []string{"team-*"}, Labels: map[string]string{"env": "prod"}},
+			want:      false,
+		},
+		{
+			name:      "malformed glob",
+			namespace: "team-a",
+			selector:  rbacoperatorv1.NamespaceSelector{IncludeNamespaceGlobs: []string{"team-["}},
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: tt.namespace}}
+			got, err := NamespaceMatches(ns, tt.selector)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NamespaceMatches() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("NamespaceMatches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package webhook holds the operator's admission webhooks for NamespaceRBACConfig
+package webhook
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	rbacoperatorv1 "github.com/cropalato/k8s-acl-operator/pkg/apis/rbac/v1"
+	"github.com/cropalato/k8s-acl-operator/pkg/metrics"
+)
+
+const (
+	// CreatedByVersionAnnotation records the operator version that admitted a config
+	CreatedByVersionAnnotation = "rbac.operator.io/created-by-version"
+	// DefaultSeparator is the naming separator stamped on configs that leave it unset,
+	// matching the template engine's default
+	DefaultSeparator = "-"
+	// AdmissionReasonDefaulted and AdmissionReasonWrongKind label the defaulter's
+	// decisions in the webhook admission metric
+	AdmissionReasonDefaulted = "defaulted"
+	AdmissionReasonWrongKind = "wrong_kind"
+	// MutatePath is where controller-runtime serves the defaulting webhook
+	MutatePath = "/mutate-rbac-operator-io-v1-namespacerbacconfig"
+)
+
+// +kubebuilder:webhook:path=/mutate-rbac-operator-io-v1-namespacerbacconfig,mutating=true,failurePolicy=ignore,sideEffects=None,groups=rbac.operator.io,resources=namespacerbacconfigs,verbs=create,versions=v1,name=mnamespacerbacconfig.rbac.operator.io,admissionReviewVersions=v1
+
+// ConfigDefaulter is a mutating webhook that writes the implicit defaults into newly
+// created NamespaceRBACConfigs, so the stored object shows the behavior it gets even
+// if the operator's defaults change later
+type ConfigDefaulter struct {
+	Version string // Operator version recorded in CreatedByVersionAnnotation; empty skips the annotation
+}
+
+// SetupWithManager registers the defaulter with the manager's webhook server
+func (d *ConfigDefaulter) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&rbacoperatorv1.NamespaceRBACConfig{}).
+		WithDefaulter(d).
+		Complete()
+}
+
+// Default implements admission.CustomDefaulter. It sets the merge strategy and naming
+// separator when unset and records the operator version; values already present are
+// never overwritten. Every decision is counted in the webhook admission metric.
+func (d *ConfigDefaulter) Default(ctx context.Context, obj runtime.Object) error {
+	config, ok := obj.(*rbacoperatorv1.NamespaceRBACConfig)
+	if !ok {
+		metrics.RecordWebhookAdmission(false, AdmissionReasonWrongKind)
+		return fmt.Errorf("expected a NamespaceRBACConfig, got %T", obj)
+	}
+
+	if config.Spec.Config == nil {
+		config.Spec.Config = &rbacoperatorv1.NamespaceRBACConfigConfig{}
+	}
+	if config.Spec.Config.MergeStrategy == nil {
+		strategy := rbacoperatorv1.MergeStrategyMerge
+		config.Spec.Config.MergeStrategy = &strategy
+	}
+	if config.Spec.Config.Naming == nil {
+		config.Spec.Config.Naming = &rbacoperatorv1.NamingConfig{}
+	}
+	if config.Spec.Config.Naming.Separator == "" {
+		config.Spec.Config.Naming.Separator = DefaultSeparator
+	}
+
+	if d.Version != "" {
+		annotations := config.GetAnnotations()
+		if annotations == nil {
+			annotations = make(map[string]string)
+		}
+		if _, exists := annotations[CreatedByVersionAnnotation]; !exists {
+			annotations[CreatedByVersionAnnotation] = d.Version
+			config.SetAnnotations(annotations)
+		}
+	}
+	metrics.RecordWebhookAdmission(true, AdmissionReasonDefaulted)
+	return nil
+}
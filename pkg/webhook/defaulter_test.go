@@ -0,0 +1,152 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	rbacoperatorv1 "github.com/cropalato/k8s-acl-operator/pkg/apis/rbac/v1"
+	"github.com/cropalato/k8s-acl-operator/pkg/metrics"
+)
+
+func TestConfigDefaulterDefault(t *testing.T) {
+	merge, replace := rbacoperatorv1.MergeStrategyMerge, rbacoperatorv1.MergeStrategyReplace
+
+	tests := []struct {
+		name        string
+		version     string
+		config      *rbacoperatorv1.NamespaceRBACConfig
+		wantSpec    *rbacoperatorv1.NamespaceRBACConfigConfig
+		wantVersion string
+	}{
+		{
+			name:        "missing everything",
+			version:     "v1.2.3",
+			config:      &rbacoperatorv1.NamespaceRBACConfig{},
+			wantSpec:    &rbacoperatorv1.NamespaceRBACConfigConfig{MergeStrategy: &merge, Naming: &rbacoperatorv1.NamingConfig{Separator: DefaultSeparator}},
+			wantVersion: "v1.2.3",
+		},
+		{
+			name:    "existing values kept",
+			version: "v1.2.3",
+			config: &rbacoperatorv1.NamespaceRBACConfig{
+				ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{CreatedByVersionAnnotation: "v1.0.0"}},
+				Spec: rbacoperatorv1.NamespaceRBACConfigSpec{Config: &rbacoperatorv1.NamespaceRBACConfigConfig{
+					MergeStrategy: &replace,
+					Naming:        &rbacoperatorv1.NamingConfig{Prefix: "acme", Separator: "."},
+				}},
+			},
+			wantSpec:    &rbacoperatorv1.NamespaceRBACConfigConfig{MergeStrategy: &replace, Naming: &rbacoperatorv1.NamingConfig{Prefix: "acme", Separator: "."}},
+			wantVersion: "v1.0.0",
+		},
+		{
+			name:     "no version",
+			config:   &rbacoperatorv1.NamespaceRBACConfig{},
+			wantSpec: &rbacoperatorv1.NamespaceRBACConfigConfig{MergeStrategy: &merge, Naming: &rbacoperatorv1.NamingConfig{Separator: DefaultSeparator}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &ConfigDefaulter{Version: tt.version}
+			if err := d.Default(context.Background(), tt.config); err != nil {
+				t.Fatalf("Default() error = %v", err)
+			}
+			if !reflect.DeepEqual(tt.config.Spec.Config, tt.wantSpec) {
+				t.Errorf("spec.config = %+v, want %+v", tt.config.Spec.Config, tt.wantSpec)
+			}
+			if got := tt.config.Annotations[CreatedByVersionAnnotation]; got != tt.wantVersion {
+				t.Errorf("%s = %q, want %q", CreatedByVersionAnnotation, got, tt.wantVersion)
+			}
+		})
+	}
+}
+
+func TestConfigDefaulterRejectsOtherKinds(t *testing.T) {
+	if err := (&ConfigDefaulter{}).Default(context.Background(), &corev1.Namespace{}); err == nil {
+		t.Error("Default() accepted a Namespace")
+	}
+}
+
+func TestConfigDefaulterHandle(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := rbacoperatorv1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	raw, err := json.Marshal(&rbacoperatorv1.NamespaceRBACConfig{
+		TypeMeta:   metav1.TypeMeta{APIVersion: rbacoperatorv1.GroupVersion.String(), Kind: "NamespaceRBACConfig"},
+		ObjectMeta: metav1.ObjectMeta{Name: "cfg"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	webhook := admission.WithCustomDefaulter(scheme, &rbacoperatorv1.NamespaceRBACConfig{}, &ConfigDefaulter{Version: "v1.2.3"})
+	resp := webhook.Handle(context.Background(), admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+		Operation: admissionv1.Create,
+		Object:    runtime.RawExtension{Raw: raw},
+	}})
+
+	if !resp.Allowed {
+		t.Fatalf("response not allowed: %+v", resp.Result)
+	}
+	paths := map[string]bool{}
+	for _, patch := range resp.Patches {
+		paths[patch.Path] = true
+	}
+	for _, want := range []string{"/spec/config", "/metadata/annotations"} {
+		if !paths[want] {
+			t.Errorf("no patch for %s in %+v", want, resp.Patches)
+		}
+	}
+}
+
+func TestConfigDefaulterRecordsAdmission(t *testing.T) {
+	tests := []struct {
+		name       string
+		obj        runtime.Object
+		wantResult string
+		wantReason string
+	}{
+		{"config defaulted", &rbacoperatorv1.NamespaceRBACConfig{}, "allowed", AdmissionReasonDefaulted},
+		{"other kind denied", &corev1.Namespace{}, "denied", AdmissionReasonWrongKind},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			metrics.ResetMetrics()
+			_ = (&ConfigDefaulter{}).Default(context.Background(), tt.obj)
+
+			if got := testutil.ToFloat64(metrics.WebhookAdmissions.WithLabelValues(tt.wantResult, tt.wantReason)); got != 1 {
+				t.Errorf("admissions{result=%q,reason=%q} = %v, want 1", tt.wantResult, tt.wantReason, got)
+			}
+			if got := testutil.CollectAndCount(metrics.WebhookAdmissions); got != 1 {
+				t.Errorf("admission series = %d, want 1", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Command integration runs every scenario in test/integration against an in-process
+// operator and envtest API server, exiting non-zero if any scenario fails.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	"github.com/cropalato/k8s-acl-operator/test/integration"
+)
+
+func main() {
+	opts := zap.Options{Development: true}
+	opts.BindFlags(flag.CommandLine)
+	flag.Parse()
+	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
+
+	ctx := ctrl.SetupSignalHandler()
+	env, err := integration.Start(ctx, integration.Options{EnableNamespaceController: true})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to start environment: %v\n", err)
+		os.Exit(1)
+	}
+
+	failed := run(ctx, env)
+	if err := env.Stop(); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to stop environment: %v\n", err)
+		failed = true
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// run executes each scenario in order and reports whether any failed
+func run(ctx context.Context, env *integration.Environment) bool {
+	failed := false
+	for _, scenario := range integration.Scenarios {
+		if err := scenario.Run(ctx, env); err != nil {
+			fmt.Printf("FAIL %s: %v\n", scenario.Name, err)
+			failed = true
+			continue
+		}
+		fmt.Printf("PASS %s\n", scenario.Name)
+	}
+	return failed
+}
@@ -0,0 +1,160 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package integration runs the operator's reconcilers in-process against an envtest
+// API server, so the create/match/apply/cleanup flow can be exercised without a cluster.
+package integration
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"runtime"
+
+	k8sruntime "k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/envtest"
+	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
+
+	rbacoperatorv1 "github.com/cropalato/k8s-acl-operator/pkg/apis/rbac/v1"
+	"github.com/cropalato/k8s-acl-operator/pkg/controller/namespace"
+	"github.com/cropalato/k8s-acl-operator/pkg/controller/namespacerbacconfig"
+	"github.com/cropalato/k8s-acl-operator/pkg/health"
+	"github.com/cropalato/k8s-acl-operator/pkg/rbac"
+)
+
+// Options configures the in-process operator
+type Options struct {
+	// RBAC is passed to both reconcilers, as the --* flags are in cmd/manager
+	RBAC rbac.Options
+	// EnableNamespaceController also runs the standalone Namespace controller
+	EnableNamespaceController bool
+}
+
+// Environment is a running envtest API server with the operator's reconcilers started
+// against it. Binaries are located through KUBEBUILDER_ASSETS, as set by `make test-integration`.
+type Environment struct {
+	Config *rest.Config  // Connects to the envtest API server
+	Client client.Client // Uncached client for arranging and asserting cluster state
+	Cache  client.Reader // The operator's cache-backed client, with its field indexes
+
+	testEnv *envtest.Environment
+	cancel  context.CancelFunc
+	done    chan error
+}
+
+// CRDDirectory returns the directory holding the NamespaceRBACConfig CRD manifest.
+// It is resolved from this source file so callers may run from any working directory.
+func CRDDirectory() string {
+	_, file, _, _ := runtime.Caller(0)
+	return filepath.Join(filepath.Dir(file), "..", "..", "config", "crd")
+}
+
+// Start boots an API server with the CRD installed and runs the operator's manager
+// against it until Stop is called or ctx is cancelled.
+func Start(ctx context.Context, opts Options) (*Environment, error) {
+	testEnv := &envtest.Environment{
+		CRDDirectoryPaths:     []string{CRDDirectory()},
+		ErrorIfCRDPathMissing: true,
+	}
+	cfg, err := testEnv.Start()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start envtest: %w", err)
+	}
+
+	env := &Environment{Config: cfg, testEnv: testEnv, done: make(chan error, 1)}
+	if err := env.startManager(ctx, opts); err != nil {
+		_ = testEnv.Stop()
+		return nil, err
+	}
+	return env, nil
+}
+
+// startManager builds a manager mirroring cmd/manager's controller setup and starts it in the background
+func (e *Environment) startManager(ctx context.Context, opts Options) error {
+	scheme := k8sruntime.NewScheme()
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(rbacoperatorv1.AddToScheme(scheme))
+
+	c, err := client.New(e.Config, client.Options{Scheme: scheme})
+	if err != nil {
+		return fmt.Errorf("failed to create client: %w", err)
+	}
+	e.Client = c
+
+	mgr, err := ctrl.NewManager(e.Config, ctrl.Options{
+		Scheme:                 scheme,
+		Metrics:                metricsserver.Options{BindAddress: "0"},
+		HealthProbeBindAddress: "0",
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create manager: %w", err)
+	}
+
+	if err := rbac.IndexConfigLabel(ctx, mgr.GetFieldIndexer()); err != nil {
+		return err
+	}
+	opts.RBAC.ConfigLabelIndexed = true
+	e.Cache = mgr.GetClient()
+
+	healthChecker := health.NewChecker(ctrl.Log.WithName("health"))
+
+	configReconciler := namespacerbacconfig.NewNamespaceRBACConfigReconciler(
+		mgr.GetClient(),
+		mgr.GetScheme(),
+		ctrl.Log.WithName("controllers").WithName("NamespaceRBACConfig"),
+		healthChecker,
+		opts.RBAC,
+	)
+	configReconciler.APIReader = mgr.GetAPIReader()
+	configReconciler.NamespaceCache = mgr.GetCache()
+	if err := configReconciler.SetupWithManager(mgr); err != nil {
+		return fmt.Errorf("controller NamespaceRBACConfig: %w", err)
+	}
+
+	if opts.EnableNamespaceController {
+		namespaceReconciler := namespace.NewNamespaceReconciler(
+			mgr.GetClient(),
+			mgr.GetScheme(),
+			ctrl.Log.WithName("controllers").WithName("Namespace"),
+			healthChecker,
+			opts.RBAC,
+		)
+		if err := namespaceReconciler.SetupWithManager(mgr); err != nil {
+			return fmt.Errorf("controller Namespace: %w", err)
+		}
+	}
+
+	ctx, e.cancel = context.WithCancel(ctx)
+	go func() {
+		e.done <- mgr.Start(ctx)
+	}()
+	return nil
+}
+
+// Stop shuts down the manager and the API server
+func (e *Environment) Stop() error {
+	e.cancel()
+	if err := <-e.done; err != nil {
+		_ = e.testEnv.Stop()
+		return fmt.Errorf("manager exited with error: %w", err)
+	}
+	return e.testEnv.Stop()
+}
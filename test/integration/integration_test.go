@@ -0,0 +1,67 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package integration
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+)
+
+func TestCRDDirectory(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join(CRDDirectory(), "namespacerbacconfigs.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	crd := &unstructured.Unstructured{}
+	if err := yaml.Unmarshal(data, &crd.Object); err != nil {
+		t.Fatal(err)
+	}
+	if crd.GetKind() != "CustomResourceDefinition" || crd.GetName() != "namespacerbacconfigs.rbac.operator.io" {
+		t.Errorf("CRDDirectory() manifest = %s %s, want the NamespaceRBACConfig CRD", crd.GetKind(), crd.GetName())
+	}
+}
+
+func TestScenarios(t *testing.T) {
+	if os.Getenv("KUBEBUILDER_ASSETS") == "" {
+		t.Skip("KUBEBUILDER_ASSETS not set; run `make test` or `make test-integration`")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	env, err := Start(ctx, Options{EnableNamespaceController: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := env.Stop(); err != nil {
+			t.Errorf("Stop: %v", err)
+		}
+	}()
+
+	for _, scenario := range Scenarios {
+		t.Run(scenario.Name, func(t *testing.T) {
+			if err := scenario.Run(ctx, env); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
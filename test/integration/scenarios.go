@@ -0,0 +1,202 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package integration
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	rbacoperatorv1 "github.com/cropalato/k8s-acl-operator/pkg/apis/rbac/v1"
+	"github.com/cropalato/k8s-acl-operator/pkg/rbac"
+)
+
+const (
+	// PollInterval is how often scenarios re-check cluster state while waiting on the operator
+	PollInterval = 250 * time.Millisecond
+	// PollTimeout bounds how long scenarios wait for the operator to converge
+	PollTimeout = 30 * time.Second
+)
+
+// Scenario is one end-to-end flow run against an Environment
+type Scenario struct {
+	Name string
+	Run  func(ctx context.Context, env *Environment) error
+}
+
+// Scenarios lists every flow run by `make test-integration`
+var Scenarios = []Scenario{
+	{Name: "match-apply-cleanup", Run: MatchApplyCleanup},
+	{Name: "list-by-config-label", Run: ListByConfigLabel},
+}
+
+// MatchApplyCleanup labels a namespace into a config's selector, waits for the Role to be
+// created, then removes the label and waits for the Role to be cleaned up
+func MatchApplyCleanup(ctx context.Context, env *Environment) error {
+	const (
+		configName = "integration-match-apply-cleanup"
+		nsName     = "integration-team-a"
+		label      = "integration.rbac.operator.io/team"
+	)
+
+	ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+		Name:   nsName,
+		Labels: map[string]string{label: "a"},
+	}}
+	if err := env.Client.Create(ctx, ns); err != nil {
+		return fmt.Errorf("failed to create namespace: %w", err)
+	}
+
+	config := &rbacoperatorv1.NamespaceRBACConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: configName},
+		Spec: rbacoperatorv1.NamespaceRBACConfigSpec{
+			NamespaceSelector: rbacoperatorv1.NamespaceSelector{
+				Labels: map[string]string{label: "a"},
+			},
+			RBACTemplates: rbacoperatorv1.RBACTemplates{
+				Roles: []rbacoperatorv1.RoleTemplate{{
+					Name: "{{ .Namespace.Name }}-viewer",
+					Rules: []rbacv1.PolicyRule{{
+						APIGroups: []string{""},
+						Resources: []string{"pods"},
+						Verbs:     []string{"get", "list", "watch"},
+					}},
+				}},
+			},
+		},
+	}
+	if err := env.Client.Create(ctx, config); err != nil {
+		return fmt.Errorf("failed to create NamespaceRBACConfig: %w", err)
+	}
+	defer func() {
+		_ = env.Client.Delete(context.Background(), config)
+	}()
+
+	if err := waitForRoleCount(ctx, env.Client, nsName, configName, 1); err != nil {
+		return fmt.Errorf("role was not created for matching namespace: %w", err)
+	}
+
+	if err := env.Client.Get(ctx, client.ObjectKeyFromObject(ns), ns); err != nil {
+		return fmt.Errorf("failed to get namespace: %w", err)
+	}
+	delete(ns.Labels, label)
+	if err := env.Client.Update(ctx, ns); err != nil {
+		return fmt.Errorf("failed to unlabel namespace: %w", err)
+	}
+
+	if err := waitForRoleCount(ctx, env.Client, nsName, configName, 0); err != nil {
+		return fmt.Errorf("role was not removed after namespace stopped matching: %w", err)
+	}
+	return nil
+}
+
+// ListByConfigLabel applies two configs to the same namespace and checks that listing
+// Roles through the operator's cache by ConfigLabelIndex returns only one config's Roles
+func ListByConfigLabel(ctx context.Context, env *Environment) error {
+	const (
+		nsName = "integration-index"
+		label  = "integration.rbac.operator.io/index"
+	)
+
+	ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+		Name:   nsName,
+		Labels: map[string]string{label: "true"},
+	}}
+	if err := env.Client.Create(ctx, ns); err != nil {
+		return fmt.Errorf("failed to create namespace: %w", err)
+	}
+
+	configNames := []string{"integration-index-a", "integration-index-b"}
+	for _, configName := range configNames {
+		config := &rbacoperatorv1.NamespaceRBACConfig{
+			ObjectMeta: metav1.ObjectMeta{Name: configName},
+			Spec: rbacoperatorv1.NamespaceRBACConfigSpec{
+				NamespaceSelector: rbacoperatorv1.NamespaceSelector{
+					Labels: map[string]string{label: "true"},
+				},
+				RBACTemplates: rbacoperatorv1.RBACTemplates{
+					Roles: []rbacoperatorv1.RoleTemplate{{
+						Name: configName + "-viewer",
+						SimpleRules: []rbacoperatorv1.SimpleRule{{
+							Resources: []string{"pods"},
+							Access:    rbacoperatorv1.AccessRead,
+						}},
+					}},
+				},
+			},
+		}
+		if err := env.Client.Create(ctx, config); err != nil {
+			return fmt.Errorf("failed to create NamespaceRBACConfig %s: %w", configName, err)
+		}
+		defer func() {
+			_ = env.Client.Delete(context.Background(), config)
+		}()
+	}
+
+	for _, configName := range configNames {
+		if err := waitForRoleCount(ctx, env.Client, nsName, configName, 1); err != nil {
+			return fmt.Errorf("role was not created for %s: %w", configName, err)
+		}
+	}
+
+	// The cache may trail the API server, so poll until it holds the Role
+	var roles *rbacv1.RoleList
+	err := wait.PollUntilContextTimeout(ctx, PollInterval, PollTimeout, true, func(ctx context.Context) (bool, error) {
+		roles = &rbacv1.RoleList{}
+		if err := env.Cache.List(ctx, roles, client.InNamespace(nsName),
+			client.MatchingFields{rbac.ConfigLabelIndex: configNames[0]}); err != nil {
+			return false, err
+		}
+		return len(roles.Items) > 0, nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list roles by %s: %w", rbac.ConfigLabelIndex, err)
+	}
+	for _, role := range roles.Items {
+		if role.Labels[rbac.ConfigLabel] != configNames[0] {
+			return fmt.Errorf("listing by %s=%s returned role %s of config %q",
+				rbac.ConfigLabelIndex, configNames[0], role.Name, role.Labels[rbac.ConfigLabel])
+		}
+	}
+	if len(roles.Items) != 1 {
+		return fmt.Errorf("want 1 role for %s, have %d", configNames[0], len(roles.Items))
+	}
+	return nil
+}
+
+// waitForRoleCount polls until the namespace holds exactly want Roles generated by the config
+func waitForRoleCount(ctx context.Context, c client.Client, namespace, configName string, want int) error {
+	var got int
+	err := wait.PollUntilContextTimeout(ctx, PollInterval, PollTimeout, true, func(ctx context.Context) (bool, error) {
+		roles := &rbacv1.RoleList{}
+		if err := c.List(ctx, roles, client.InNamespace(namespace), client.MatchingLabels{rbac.ConfigLabel: configName}); err != nil {
+			return false, err
+		}
+		got = len(roles.Items)
+		return got == want, nil
+	})
+	if err != nil {
+		return fmt.Errorf("want %d role(s), have %d: %w", want, got, err)
+	}
+	return nil
+}